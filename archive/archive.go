@@ -0,0 +1,191 @@
+// Package archive 将抓取到的页面和接口响应保存到本地目录，并按请求回放
+// 用于复现某次分析报告时的原始数据，以及编写不依赖网络的离线测试
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// 存档模式
+const (
+	ModeOff    = "off"    // 不使用存档
+	ModeRecord = "record" // 正常抓取，同时保存每个页面和响应
+	ModeReplay = "replay" // 只从存档读取，不访问网络
+)
+
+// ErrNotFound 存档中没有对应的请求
+var ErrNotFound = errors.New("存档中没有该请求")
+
+// Entry 一次请求的记录，响应正文单独保存
+type Entry struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	RequestBody string      `json:"requestBody,omitempty"` // POST 请求的表单或JSON
+	FinalURL    string      `json:"finalUrl"`              // 跳转后的URL
+	Status      int         `json:"status"`                // HTTP状态码，浏览器抓取时为0表示未知
+	Header      http.Header `json:"header,omitempty"`      // 接口响应头
+	Title       string      `json:"title,omitempty"`
+	Text        string      `json:"text,omitempty"` // 页面纯文本，仅页面抓取有
+	Time        time.Time   `json:"time"`           // 抓取时间
+	File        string      `json:"file"`           // 响应正文文件名（相对存档目录）
+}
+
+// Archive 存档目录：每个请求一个 .json 记录和一个 .body 正文，index.jsonl 按时间顺序列出全部请求
+type Archive struct {
+	// Volatile 每次运行都会变化的查询或表单参数，如按当前时间计算的起始时间
+	// 保存时另按忽略这些参数的键记录一份，回放时找不到完全相同的请求再按该键查找
+	Volatile []string
+
+	dir string
+	mu  sync.Mutex
+}
+
+// Open 打开存档目录，不存在时创建
+func Open(dir string) (*Archive, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("存档目录不能为空")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建存档目录失败: %v", err)
+	}
+	return &Archive{dir: dir}, nil
+}
+
+// Dir 返回存档目录
+func (a *Archive) Dir() string {
+	return a.dir
+}
+
+// Key 返回请求在存档中的文件名（不含扩展名）：方法、URL和请求体相同的请求视为同一个
+func Key(method, rawURL string, requestBody []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", method, rawURL)
+	h.Write(requestBody)
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// looseKey 返回忽略易变参数后的键，请求中没有易变参数时返回空字符串
+func (a *Archive) looseKey(method, rawURL string, requestBody []byte) string {
+	if len(a.Volatile) == 0 {
+		return ""
+	}
+	changed := false
+	if u, err := url.Parse(rawURL); err == nil {
+		if query, ok := maskVolatile(u.Query(), a.Volatile); ok {
+			u.RawQuery = query
+			rawURL, changed = u.String(), true
+		}
+	}
+	if form, err := url.ParseQuery(string(requestBody)); err == nil && len(requestBody) > 0 {
+		if body, ok := maskVolatile(form, a.Volatile); ok {
+			requestBody, changed = []byte(body), true
+		}
+	}
+	if !changed {
+		return ""
+	}
+	return "loose-" + Key(method, rawURL, requestBody)
+}
+
+// maskVolatile 将易变参数的值替换为 *，没有易变参数时返回 false
+func maskVolatile(values url.Values, volatile []string) (string, bool) {
+	masked := false
+	for _, name := range volatile {
+		if values.Has(name) {
+			values.Set(name, "*")
+			masked = true
+		}
+	}
+	return values.Encode(), masked
+}
+
+// Save 保存一次请求的记录和响应正文，同一请求再次保存时覆盖旧记录
+func (a *Archive) Save(e Entry, body []byte) error {
+	if e.Method == "" {
+		e.Method = http.MethodGet
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	key := Key(e.Method, e.URL, []byte(e.RequestBody))
+	e.File = key + ".body"
+	meta, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return fmt.Errorf("保存存档失败: %v", err)
+	}
+	line, err := json.Marshal(struct {
+		Time   time.Time `json:"time"`
+		Method string    `json:"method"`
+		URL    string    `json:"url"`
+		Status int       `json:"status"`
+		Key    string    `json:"key"`
+	}{e.Time, e.Method, e.URL, e.Status, key})
+	if err != nil {
+		return fmt.Errorf("保存存档失败: %v", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := os.WriteFile(filepath.Join(a.dir, e.File), body, 0o644); err != nil {
+		return fmt.Errorf("保存存档失败: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(a.dir, key+".json"), meta, 0o644); err != nil {
+		return fmt.Errorf("保存存档失败: %v", err)
+	}
+	// 忽略易变参数的记录与原记录共用正文，同类请求以最后一次为准
+	if loose := a.looseKey(e.Method, e.URL, []byte(e.RequestBody)); loose != "" {
+		if err := os.WriteFile(filepath.Join(a.dir, loose+".json"), meta, 0o644); err != nil {
+			return fmt.Errorf("保存存档失败: %v", err)
+		}
+	}
+	index, err := os.OpenFile(filepath.Join(a.dir, "index.jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("保存存档索引失败: %v", err)
+	}
+	defer index.Close()
+	if _, err := index.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("保存存档索引失败: %v", err)
+	}
+	return nil
+}
+
+// Load 读取请求的记录和响应正文，存档中没有时返回 ErrNotFound
+// 没有完全相同的请求时，忽略 Volatile 中的参数再查找一次
+func (a *Archive) Load(method, rawURL string, requestBody []byte) (Entry, []byte, error) {
+	if method == "" {
+		method = http.MethodGet
+	}
+	key := Key(method, rawURL, requestBody)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	meta, err := os.ReadFile(filepath.Join(a.dir, key+".json"))
+	if loose := a.looseKey(method, rawURL, requestBody); errors.Is(err, fs.ErrNotExist) && loose != "" {
+		meta, err = os.ReadFile(filepath.Join(a.dir, loose+".json"))
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return Entry{}, nil, fmt.Errorf("%w: %s %s", ErrNotFound, method, rawURL)
+	}
+	if err != nil {
+		return Entry{}, nil, fmt.Errorf("读取存档失败: %v", err)
+	}
+	var e Entry
+	if err := json.Unmarshal(meta, &e); err != nil {
+		return Entry{}, nil, fmt.Errorf("解析存档记录失败: %v", err)
+	}
+	body, err := os.ReadFile(filepath.Join(a.dir, e.File))
+	if err != nil {
+		return Entry{}, nil, fmt.Errorf("读取存档正文失败: %v", err)
+	}
+	return e, body, nil
+}
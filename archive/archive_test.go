@@ -0,0 +1,116 @@
+package archive
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSaveLoad(t *testing.T) {
+	a, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := "https://www.cls.cn/detail/1001"
+	if err := a.Save(Entry{URL: page, FinalURL: page, Title: "标题", Text: "正文"}, []byte("<html>正文</html>")); err != nil {
+		t.Fatal(err)
+	}
+	e, body, err := a.Load("", page, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Method != http.MethodGet || e.Title != "标题" || e.Text != "正文" || string(body) != "<html>正文</html>" || e.Time.IsZero() {
+		t.Errorf("Load = %+v %q", e, body)
+	}
+	if _, _, err := a.Load("", page+"?page=2", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("未存档的请求应返回 ErrNotFound，得到 %v", err)
+	}
+}
+
+func TestTransportRecordReplay(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"symbol":"`+r.Form.Get("symbol")+r.Form.Get("stock")+`"}`)
+	}))
+	defer server.Close()
+
+	a, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Volatile = []string{"begin", "seDate"}
+	record := &http.Client{Transport: &Transport{Archive: a}}
+	replay := &http.Client{Transport: &Transport{Archive: a, Replay: true}}
+
+	kline := func(symbol, begin string) string {
+		return server.URL + "/kline?" + url.Values{"symbol": {symbol}, "begin": {begin}, "count": {"-120"}}.Encode()
+	}
+	form := func(stock, seDate string) string {
+		return url.Values{"stock": {stock}, "seDate": {seDate}, "pageNum": {"1"}}.Encode()
+	}
+	get := func(c *http.Client, rawURL string) (string, error) {
+		resp, err := c.Get(rawURL)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		return string(data), err
+	}
+	post := func(c *http.Client, body string) (string, error) {
+		resp, err := c.Post(server.URL+"/query", "application/x-www-form-urlencoded", strings.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		return string(data), err
+	}
+
+	if _, err := get(record, kline("SH601288", "1760000000000")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := post(record, form("601288,9900000001", "2025-09-15~2025-10-15")); err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != 2 {
+		t.Fatalf("记录模式应发出 2 个请求，实际 %d", requests.Load())
+	}
+
+	tests := []struct {
+		name string
+		do   func() (string, error)
+		want string
+	}{
+		{"相同请求", func() (string, error) { return get(replay, kline("SH601288", "1760000000000")) }, `{"symbol":"SH601288"}`},
+		// 下次运行时 begin 为新的当前时间
+		{"begin 变化", func() (string, error) { return get(replay, kline("SH601288", "1760600000000")) }, `{"symbol":"SH601288"}`},
+		{"seDate 变化", func() (string, error) { return post(replay, form("601288,9900000001", "2025-09-20~2025-10-20")) }, `{"symbol":"601288,9900000001"}`},
+	}
+	for _, tt := range tests {
+		got, err := tt.do()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: 回放 %s，期望 %s", tt.name, got, tt.want)
+		}
+	}
+
+	// 非易变参数不同的请求仍视为不同请求
+	if _, err := get(replay, kline("SZ000001", "1760000000000")); !errors.Is(err, ErrNotFound) {
+		t.Errorf("不同代码的请求应返回 ErrNotFound，得到 %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("回放模式不应访问网络，实际请求 %d 次", requests.Load())
+	}
+}
@@ -0,0 +1,83 @@
+package archive
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Transport 记录或回放接口请求的 http.RoundTripper
+// 记录模式下照常发送请求并保存响应；回放模式下只从存档读取，存档中没有时返回错误
+type Transport struct {
+	Archive *Archive
+	Replay  bool
+	Next    http.RoundTripper // 实际发送请求的 RoundTripper，为空时使用 http.DefaultTransport
+	OnError func(error)       // 保存存档失败时调用，存档失败不影响本次请求
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("读取请求体失败: %v", err)
+		}
+		reqBody = data
+	}
+	url := req.URL.String()
+
+	if t.Replay {
+		e, body, err := t.Archive.Load(req.Method, url, reqBody)
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+			StatusCode:    e.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        e.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	out := req.Clone(req.Context())
+	if reqBody != nil {
+		out.Body = io.NopCloser(bytes.NewReader(reqBody))
+		out.ContentLength = int64(len(reqBody))
+	}
+	resp, err := next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %v", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.Request = req
+
+	err = t.Archive.Save(Entry{
+		Method:      req.Method,
+		URL:         url,
+		RequestBody: string(reqBody),
+		FinalURL:    url,
+		Status:      resp.StatusCode,
+		Header:      resp.Header.Clone(),
+	}, body)
+	if err != nil && t.OnError != nil {
+		t.OnError(err)
+	}
+	return resp, nil
+}
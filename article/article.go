@@ -0,0 +1,389 @@
+// Package article 从新闻网页中提取正文、标题、作者和发布时间
+// 按文本密度、链接密度和标签语义给页面中的块打分，去掉导航、页脚、广告和相关推荐等页面杂项
+package article
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"stock_agent/newstime"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Article 提取结果
+type Article struct {
+	Title     string
+	Byline    string    // 作者，未找到时为空
+	Published time.Time // 发布时间，未找到时为零值
+	Content   string    // 正文，段落之间以换行分隔
+}
+
+const (
+	// minParagraphRunes 参与打分的段落最少字符数
+	minParagraphRunes = 25
+	// minContentRunes 正文最少字符数，不足时认为页面不是文章
+	minContentRunes = 50
+	// maxLinkDensity 渲染正文时跳过链接文字占比超过该值的块（相关阅读、标签列表等）
+	maxLinkDensity = 0.5
+)
+
+var (
+	// removeTags 不可能属于正文的标签
+	removeTags = "script, style, noscript, iframe, svg, canvas, form, button, input, select, textarea, nav, footer, aside"
+	// unlikelyPattern class 或 id 命中时通常是页面杂项
+	unlikelyPattern = regexp.MustCompile(`(?i)\bads?\b|advert|banner|breadcrumb|comment|copyright|download|footer|login|menu|modal|\bnav|popup|qrcode|rank|recommend|related|share|sidebar|sponsor|toolbar|hot-|guess|app-`)
+	// positivePattern class 或 id 命中时通常是正文容器
+	positivePattern = regexp.MustCompile(`(?i)article|body|content|detail|entry|main|news|post|text`)
+	// negativePattern 打分时扣分的 class 或 id
+	negativePattern = regexp.MustCompile(`(?i)comment|footer|hidden|meta|nav|related|recommend|share|sidebar|sponsor|widget|list`)
+	// bylineClassPattern 作者信息所在元素的 class 或 id
+	bylineClassPattern = regexp.MustCompile(`(?i)author|byline|writer|editor`)
+	// dateClassPattern 发布时间所在元素的 class 或 id
+	dateClassPattern = regexp.MustCompile(`(?i)time|date|pub`)
+	// bylinePattern 从作者信息文本中取出姓名
+	bylinePattern = regexp.MustCompile(`(?:作者|记者|编辑|撰文)\s*[：:|丨]?\s*([^\s丨|，,：:]{2,12})`)
+	// titleSeparator <title> 中标题与站点名之间的分隔符
+	titleSeparator = regexp.MustCompile(`\s*[-_|–—]\s*`)
+	spacePattern   = regexp.MustCompile(`[ \t\r\f\v\x{00a0}\x{3000}]+`)
+)
+
+// blockTags 块级标签，渲染时在前后换行，包含块级子元素的 div 不作为段落打分
+var blockTags = map[string]bool{
+	"address": true, "article": true, "blockquote": true, "dd": true, "div": true, "dl": true, "dt": true,
+	"figcaption": true, "figure": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "li": true, "main": true, "ol": true, "p": true, "pre": true, "section": true,
+	"table": true, "tbody": true, "td": true, "th": true, "thead": true, "tr": true, "ul": true, "br": true,
+}
+
+// Extract 提取文章，ref 为抓取时间，用于换算"3小时前"等相对时间
+// 页面中找不到足够长的正文时返回错误，调用方可退回整页文本
+func Extract(rawHTML string, ref time.Time) (*Article, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(rawHTML))
+	if err != nil {
+		return nil, fmt.Errorf("解析页面HTML失败: %v", err)
+	}
+
+	a := &Article{Title: extractTitle(doc)}
+	a.Byline = metaContent(doc, `meta[name="author"]`, `meta[property="article:author"]`)
+	if published := metaContent(doc, `meta[property="article:published_time"]`, `meta[name="pubdate"]`,
+		`meta[name="publishdate"]`, `meta[name="PubDate"]`, `meta[itemprop="datePublished"]`); published != "" {
+		if t, err := newstime.Parse(published, ref); err == nil {
+			a.Published = t
+		}
+	}
+
+	clean(doc)
+	top := topCandidate(doc)
+	if top == nil {
+		return nil, fmt.Errorf("未找到正文")
+	}
+	a.Content = render(top)
+	if utf8.RuneCountInString(a.Content) < minContentRunes {
+		return nil, fmt.Errorf("未找到正文")
+	}
+
+	// 作者和时间一般在正文上方，先在正文附近找，再在全页找
+	scopes := []*goquery.Selection{top.Parent(), top.Parent().Parent(), doc.Selection}
+	for _, scope := range scopes {
+		if a.Byline == "" {
+			a.Byline = findByline(scope)
+		}
+		if a.Published.IsZero() {
+			a.Published = findPublished(scope, ref)
+		}
+	}
+	if m := bylinePattern.FindStringSubmatch(a.Byline); m != nil {
+		a.Byline = m[1]
+	}
+	return a, nil
+}
+
+// extractTitle 取正文标题：优先 h1，其次 og:title，最后 <title> 去掉站点名
+func extractTitle(doc *goquery.Document) string {
+	if h1 := normalizeSpace(doc.Find("h1").First().Text()); utf8.RuneCountInString(h1) >= 4 {
+		return h1
+	}
+	if og := metaContent(doc, `meta[property="og:title"]`); og != "" {
+		return og
+	}
+	title := normalizeSpace(doc.Find("title").First().Text())
+	if parts := titleSeparator.Split(title, -1); len(parts) > 1 {
+		// 站点名一般较短，取最长的一段
+		longest := parts[0]
+		for _, p := range parts[1:] {
+			if utf8.RuneCountInString(p) > utf8.RuneCountInString(longest) {
+				longest = p
+			}
+		}
+		return longest
+	}
+	return title
+}
+
+// metaContent 返回第一个非空的 meta content
+func metaContent(doc *goquery.Document, selectors ...string) string {
+	for _, selector := range selectors {
+		if content := strings.TrimSpace(doc.Find(selector).AttrOr("content", "")); content != "" {
+			return content
+		}
+	}
+	return ""
+}
+
+// clean 删除不可能属于正文的元素
+func clean(doc *goquery.Document) {
+	doc.Find(removeTags).Remove()
+	doc.Find("body *").Each(func(_ int, s *goquery.Selection) {
+		switch goquery.NodeName(s) {
+		case "article", "main":
+			return
+		}
+		if class := classAndID(s); unlikelyPattern.MatchString(class) && !positivePattern.MatchString(class) {
+			s.Remove()
+		}
+	})
+}
+
+// topCandidate 给段落的父级和祖父级打分，返回得分最高的容器
+func topCandidate(doc *goquery.Document) *goquery.Selection {
+	// contentScores 只含段落得分，用于判断兄弟节点是否也是正文
+	scores := make(map[*html.Node]float64)
+	contentScores := make(map[*html.Node]float64)
+	var candidates []*html.Node
+	addScore := func(n *html.Node, score float64) {
+		if n == nil || n.Type != html.ElementNode || n.Data == "html" {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = initialScore(n)
+			candidates = append(candidates, n)
+		}
+		scores[n] += score
+		contentScores[n] += score
+	}
+
+	doc.Find("p, pre, td, blockquote, div, section").Each(func(_ int, s *goquery.Selection) {
+		name := goquery.NodeName(s)
+		if (name == "div" || name == "section") && hasBlockChild(s.Get(0)) {
+			return
+		}
+		text := normalizeSpace(s.Text())
+		n := utf8.RuneCountInString(text)
+		if n < minParagraphRunes {
+			return
+		}
+		// 段落得分：基础分、标点数量（句子越多越像正文）、长度
+		score := 1 + float64(strings.Count(text, "，")+strings.Count(text, "。")+strings.Count(text, ",")) + math.Min(float64(n)/100, 3)
+		node := s.Get(0)
+		addScore(node.Parent, score)
+		if node.Parent != nil {
+			addScore(node.Parent.Parent, score/2)
+		}
+	})
+
+	var best *html.Node
+	bestScore := 0.0
+	for _, n := range candidates {
+		density := linkDensity(goquery.NewDocumentFromNode(n).Selection)
+		score := scores[n] * (1 - density)
+		contentScores[n] *= 1 - density
+		if best == nil || score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	if best == nil {
+		if body := doc.Find("body"); body.Length() > 0 {
+			return body
+		}
+		return nil
+	}
+
+	// 得分接近的兄弟节点通常是被拆开的正文
+	threshold := math.Max(10, contentScores[best]*0.2)
+	var siblings []*html.Node
+	if best.Parent != nil {
+		for c := best.Parent.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if c == best || contentScores[c] >= threshold {
+				siblings = append(siblings, c)
+			}
+		}
+	}
+	if len(siblings) <= 1 {
+		return goquery.NewDocumentFromNode(best).Selection
+	}
+	return doc.FindNodes(siblings...)
+}
+
+// initialScore 按标签和 class 给容器的初始分
+func initialScore(n *html.Node) float64 {
+	score := 0.0
+	switch n.Data {
+	case "article":
+		score += 10
+	case "div", "section", "main":
+		score += 5
+	case "pre", "td", "blockquote":
+		score += 3
+	case "address", "ol", "ul", "dl", "dd", "dt", "li", "form":
+		score -= 3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		score -= 5
+	}
+	class := ""
+	for _, attr := range n.Attr {
+		if attr.Key == "class" || attr.Key == "id" {
+			class += " " + attr.Val
+		}
+	}
+	if negativePattern.MatchString(class) {
+		score -= 25
+	}
+	if positivePattern.MatchString(class) {
+		score += 25
+	}
+	return score
+}
+
+// hasBlockChild 判断元素是否包含块级子元素
+func hasBlockChild(n *html.Node) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && blockTags[c.Data] && c.Data != "br" {
+			return true
+		}
+	}
+	return false
+}
+
+// linkDensity 链接文字占全部文字的比例
+func linkDensity(s *goquery.Selection) float64 {
+	total := utf8.RuneCountInString(normalizeSpace(s.Text()))
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	s.Find("a").Each(func(_ int, a *goquery.Selection) {
+		links += utf8.RuneCountInString(normalizeSpace(a.Text()))
+	})
+	return float64(links) / float64(total)
+}
+
+// render 将正文容器输出为纯文本：行内文字连在一起，块级元素换行，跳过链接为主的块
+func render(sel *goquery.Selection) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(strings.ReplaceAll(n.Data, "\n", " "))
+			return
+		case html.ElementNode:
+		default:
+			return
+		}
+		block := blockTags[n.Data]
+		if block && n.Data != "br" && linkDensity(goquery.NewDocumentFromNode(n).Selection) > maxLinkDensity {
+			return
+		}
+		if block {
+			b.WriteString("\n")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if block {
+			b.WriteString("\n")
+		}
+	}
+	for _, n := range sel.Nodes {
+		walk(n)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(b.String(), "\n") {
+		if line = normalizeSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// findByline 查找作者信息
+func findByline(scope *goquery.Selection) string {
+	if scope.Length() == 0 {
+		return ""
+	}
+	if author := normalizeSpace(scope.Find(`[rel="author"], [itemprop="author"]`).First().Text()); author != "" {
+		return author
+	}
+	var byline string
+	scope.Find("*").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if !bylineClassPattern.MatchString(classAndID(s)) {
+			return true
+		}
+		text := normalizeSpace(s.Text())
+		if text != "" && utf8.RuneCountInString(text) <= 40 {
+			byline = text
+			return false
+		}
+		return true
+	})
+	return byline
+}
+
+// findPublished 查找发布时间：先看 <time> 标签，再看 class 含 time、date 的短文本
+func findPublished(scope *goquery.Selection, ref time.Time) time.Time {
+	if scope.Length() == 0 {
+		return time.Time{}
+	}
+	var published time.Time
+	scope.Find("time, [itemprop='datePublished']").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		for _, text := range []string{s.AttrOr("datetime", ""), s.AttrOr("content", ""), s.Text()} {
+			if t, err := newstime.Parse(text, ref); err == nil {
+				published = t
+				return false
+			}
+			if t, ok := newstime.Find(text, ref); ok {
+				published = t
+				return false
+			}
+		}
+		return true
+	})
+	if !published.IsZero() {
+		return published
+	}
+	scope.Find("*").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if !dateClassPattern.MatchString(classAndID(s)) {
+			return true
+		}
+		text := normalizeSpace(s.Text())
+		if text == "" || utf8.RuneCountInString(text) > 60 {
+			return true
+		}
+		if t, ok := newstime.Find(text, ref); ok {
+			published = t
+			return false
+		}
+		return true
+	})
+	return published
+}
+
+// classAndID 返回元素的 class 和 id
+func classAndID(s *goquery.Selection) string {
+	return s.AttrOr("class", "") + " " + s.AttrOr("id", "")
+}
+
+// normalizeSpace 合并空白
+func normalizeSpace(text string) string {
+	return strings.TrimSpace(spacePattern.ReplaceAllString(strings.ReplaceAll(text, "\n", " "), " "))
+}
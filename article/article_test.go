@@ -0,0 +1,81 @@
+package article
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var shanghai = time.FixedZone("CST", 8*3600)
+
+func TestExtract(t *testing.T) {
+	ref := time.Date(2024, 5, 14, 9, 0, 0, 0, shanghai)
+	tests := []struct {
+		file         string
+		title        string
+		byline       string
+		published    time.Time
+		contentStart string
+		notContain   []string
+	}{
+		{
+			file:         "cls_detail.html",
+			title:        "宁德时代发布新一代钠离子电池 量产时间提前至明年",
+			byline:       "王晓",
+			published:    time.Date(2024, 5, 13, 14, 32, 0, 0, shanghai),
+			contentStart: "财联社5月13日讯（记者 王晓）宁德时代今日在福建宁德召开技术发布会",
+			notContain:   []string{"24小时热文", "分享到微信", "Copyright", "央行今日开展逆回购"},
+		},
+		{
+			file:         "xueqiu_status.html",
+			title:        "贵州茅台一季报点评：直销占比继续提升",
+			byline:       "价值投资小站",
+			published:    time.Date(2024, 4, 26, 21, 15, 0, 0, shanghai),
+			contentStart: "一季度公司实现营业总收入465亿元",
+			notContain:   []string{"网友A", "评论 86", "行情"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			a, err := Extract(string(data), ref)
+			if err != nil {
+				t.Fatalf("Extract: %v", err)
+			}
+			if a.Title != tt.title {
+				t.Errorf("Title = %q，期望 %q", a.Title, tt.title)
+			}
+			if a.Byline != tt.byline {
+				t.Errorf("Byline = %q，期望 %q", a.Byline, tt.byline)
+			}
+			if !a.Published.Equal(tt.published) {
+				t.Errorf("Published = %v，期望 %v", a.Published, tt.published)
+			}
+			if !strings.HasPrefix(a.Content, tt.contentStart) {
+				t.Errorf("Content 开头为 %q，期望 %q", firstLine(a.Content), tt.contentStart)
+			}
+			for _, s := range tt.notContain {
+				if strings.Contains(a.Content, s) {
+					t.Errorf("Content 不应包含页面杂项 %q", s)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractNotArticle(t *testing.T) {
+	page := `<html><body><div class="quote"><span>贵州茅台</span><span>1688.00</span><span>+1.2%</span></div></body></html>`
+	if _, err := Extract(page, time.Now()); err == nil {
+		t.Error("行情页不应提取出正文")
+	}
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,275 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ErrClosed 管理器已关闭后再借用页面时返回
+var ErrClosed = errors.New("浏览器管理器已关闭")
+
+// defaultUserAgent 默认的浏览器UA，避免被识别为无头浏览器
+const defaultUserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+// Options 浏览器管理器配置
+type Options struct {
+	PoolSize    int    // 同时借出的最大页面数
+	Bin         string // 浏览器可执行文件路径，为空时由rod自动查找
+	ShowBrowser bool   // 是否显示浏览器窗口（调试用）
+	UserAgent   string
+}
+
+// Manager 维护一个长期存活的浏览器，并以有界页面池的方式借出页面
+type Manager struct {
+	opts Options
+
+	mu       sync.Mutex
+	launcher *launcher.Launcher
+	browser  *rod.Browser
+	idle     []*rod.Page
+	closed   bool
+
+	// launching 为true时有调用方正在锁外启动浏览器，其他调用方在 launched 上等待
+	launching bool
+	launched  *sync.Cond
+
+	// slots 控制同时借出的页面数量
+	slots chan struct{}
+}
+
+// NewManager 创建浏览器管理器，浏览器在第一次借用页面时才会启动
+func NewManager(opts Options) *Manager {
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = 4
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = defaultUserAgent
+	}
+	m := &Manager{
+		opts:  opts,
+		slots: make(chan struct{}, opts.PoolSize),
+	}
+	m.launched = sync.NewCond(&m.mu)
+	return m
+}
+
+// Acquire 从页面池借出一个页面，池满时阻塞直到有页面归还或ctx结束
+// 借出的页面必须通过 Release 归还
+func (m *Manager) Acquire(ctx context.Context) (*rod.Page, error) {
+	select {
+	case m.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("等待可用页面超时: %v", ctx.Err())
+	}
+
+	page, err := m.takePage()
+	if err != nil {
+		<-m.slots
+		return nil, err
+	}
+	return page, nil
+}
+
+// Release 归还页面；页面已崩溃或无法重置时直接关闭，下次借用会重新创建
+func (m *Manager) Release(page *rod.Page) {
+	defer func() { <-m.slots }()
+	if page == nil {
+		return
+	}
+
+	// 丢弃调用方设置的超时，并把页面重置为空白页
+	page = page.Context(context.Background())
+	if err := page.Timeout(5 * time.Second).Navigate("about:blank"); err != nil {
+		log.Printf("页面重置失败，已丢弃: %v", err)
+		closePage(page)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed || m.browser == nil {
+		closePage(page)
+		return
+	}
+	m.idle = append(m.idle, page)
+}
+
+// Close 关闭所有页面和浏览器进程，可重复调用
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+	return m.shutdownLocked()
+}
+
+// takePage 优先复用空闲页面，必要时启动浏览器并新建页面
+// 只在操作空闲页面和浏览器状态时持有锁，检查页面、启动浏览器和新建页面都在锁外进行
+func (m *Manager) takePage() (*rod.Page, error) {
+	for {
+		page, err := m.popIdle()
+		if err != nil {
+			return nil, err
+		}
+		if page == nil {
+			break
+		}
+		if isPageAlive(page) {
+			return page, nil
+		}
+		log.Printf("回收已崩溃的页面")
+		closePage(page)
+	}
+
+	browser, err := m.ensureBrowser()
+	if err != nil {
+		return nil, err
+	}
+	page, err := browser.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return nil, fmt.Errorf("创建页面失败: %v", err)
+	}
+	return page, nil
+}
+
+// popIdle 取出一个空闲页面，没有空闲页面时返回nil
+func (m *Manager) popIdle() (*rod.Page, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil, ErrClosed
+	}
+	if len(m.idle) == 0 {
+		return nil, nil
+	}
+	page := m.idle[len(m.idle)-1]
+	m.idle = m.idle[:len(m.idle)-1]
+	return page, nil
+}
+
+// ensureBrowser 返回可用的浏览器，浏览器断开时会重新启动
+// 同一时间只有一个调用方启动浏览器，其他调用方等待启动完成后直接使用
+func (m *Manager) ensureBrowser() (*rod.Browser, error) {
+	m.mu.Lock()
+	for {
+		for m.launching {
+			m.launched.Wait()
+		}
+		if m.closed {
+			m.mu.Unlock()
+			return nil, ErrClosed
+		}
+		browser := m.browser
+		if browser == nil {
+			break
+		}
+		m.mu.Unlock()
+		if _, err := browser.Timeout(5 * time.Second).Version(); err == nil {
+			return browser, nil
+		}
+		m.mu.Lock()
+		// 检查期间其他调用方可能已重启浏览器，此时重新检查
+		if m.browser == browser && !m.launching {
+			log.Printf("浏览器连接已断开，正在重新启动")
+			m.shutdownLocked()
+			break
+		}
+	}
+	m.launching = true
+	m.mu.Unlock()
+
+	l, browser, err := launchBrowser(m.opts)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.launching = false
+	m.launched.Broadcast()
+	if err != nil {
+		return nil, err
+	}
+	if m.closed {
+		// 启动期间管理器已关闭
+		browser.Timeout(5 * time.Second).Close()
+		l.Kill()
+		return nil, ErrClosed
+	}
+	m.launcher = l
+	m.browser = browser
+	return browser, nil
+}
+
+// launchBrowser 启动浏览器，测试时替换以避免启动真实的浏览器
+var launchBrowser = launch
+
+// launch 启动浏览器进程并建立连接
+func launch(opts Options) (*launcher.Launcher, *rod.Browser, error) {
+	// 启动浏览器（使用无头模式，添加更多配置避免被检测）
+	l := launcher.New().
+		Headless(!opts.ShowBrowser).
+		Set("disable-blink-features", "AutomationControlled").
+		Set("disable-dev-shm-usage").
+		Set("no-sandbox").
+		UserDataDir("").
+		Set("user-agent", opts.UserAgent)
+	if opts.Bin != "" {
+		l = l.Bin(opts.Bin)
+	}
+
+	browserURL, err := l.Launch()
+	if err != nil {
+		return nil, nil, fmt.Errorf("启动浏览器失败: %v", err)
+	}
+	browser := rod.New().ControlURL(browserURL)
+	if err := browser.Connect(); err != nil {
+		l.Kill()
+		return nil, nil, fmt.Errorf("连接浏览器失败: %v", err)
+	}
+
+	log.Printf("浏览器已启动 (pid=%d)", l.PID())
+	return l, browser, nil
+}
+
+// shutdownLocked 关闭空闲页面、浏览器连接和浏览器进程
+func (m *Manager) shutdownLocked() error {
+	for _, page := range m.idle {
+		closePage(page)
+	}
+	m.idle = nil
+
+	var err error
+	if m.browser != nil {
+		if closeErr := m.browser.Timeout(5 * time.Second).Close(); closeErr != nil {
+			err = fmt.Errorf("关闭浏览器失败: %v", closeErr)
+		}
+		m.browser = nil
+	}
+	if m.launcher != nil {
+		// 确保进程退出，即使浏览器已无响应
+		m.launcher.Kill()
+		m.launcher = nil
+	}
+	return err
+}
+
+// isPageAlive 检查页面是否仍可执行脚本
+func isPageAlive(page *rod.Page) bool {
+	_, err := page.Timeout(2 * time.Second).Eval(`() => 1`)
+	return err == nil
+}
+
+// closePage 关闭页面，忽略错误
+func closePage(page *rod.Page) {
+	if err := page.Timeout(5 * time.Second).Close(); err != nil {
+		log.Printf("关闭页面失败（已忽略）: %v", err)
+	}
+}
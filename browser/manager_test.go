@@ -0,0 +1,326 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
+	"github.com/go-rod/rod/lib/launcher"
+)
+
+// fakeCDP 模拟浏览器的CDP连接，按方法名返回固定结果并记录调用次数
+type fakeCDP struct {
+	mu      sync.Mutex
+	calls   map[string]int
+	targets int
+	// down 为true时所有调用都失败，模拟浏览器崩溃
+	down bool
+	// fail 中的方法调用失败
+	fail   map[string]bool
+	events chan *cdp.Event
+}
+
+func newFakeCDP() *fakeCDP {
+	return &fakeCDP{calls: map[string]int{}, fail: map[string]bool{}, events: make(chan *cdp.Event)}
+}
+
+func (c *fakeCDP) Event() <-chan *cdp.Event { return c.events }
+
+func (c *fakeCDP) Call(_ context.Context, sessionID, method string, _ any) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[method]++
+	if c.down || c.fail[method] {
+		return nil, errors.New("连接已断开")
+	}
+	var result any = map[string]any{}
+	switch method {
+	case "Target.createTarget":
+		c.targets++
+		result = map[string]any{"targetId": fmt.Sprintf("T%d", c.targets)}
+	case "Target.attachToTarget":
+		result = map[string]any{"sessionId": fmt.Sprintf("S%d", c.targets)}
+	case "Runtime.evaluate", "Runtime.callFunctionOn":
+		result = map[string]any{"result": map[string]any{"type": "number", "value": 1}}
+	case "Page.close":
+		// 会话 Sn 对应页面 Tn，Page.Close 等待页面销毁事件后才返回
+		params, _ := json.Marshal(map[string]any{"targetId": "T" + strings.TrimPrefix(sessionID, "S")})
+		go func() { c.events <- &cdp.Event{Method: "Target.targetDestroyed", Params: params} }()
+	}
+	return json.Marshal(result)
+}
+
+func (c *fakeCDP) count(method string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[method]
+}
+
+func (c *fakeCDP) set(f func(c *fakeCDP)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f(c)
+}
+
+// fakeLauncher 替换 launchBrowser，每次启动返回一个新的 fakeCDP 浏览器
+type fakeLauncher struct {
+	mu       sync.Mutex
+	browsers []*fakeCDP
+	// gate 不为nil时启动会阻塞到 gate 关闭
+	gate chan struct{}
+	// started 每次开始启动时发送一次
+	started chan struct{}
+	err     error
+	running int
+	maxRun  int
+}
+
+func useFakeLauncher(t *testing.T) *fakeLauncher {
+	t.Helper()
+	f := &fakeLauncher{started: make(chan struct{}, 16)}
+	old := launchBrowser
+	t.Cleanup(func() { launchBrowser = old })
+	launchBrowser = func(Options) (*launcher.Launcher, *rod.Browser, error) {
+		f.mu.Lock()
+		f.running++
+		f.maxRun = max(f.maxRun, f.running)
+		gate, err := f.gate, f.err
+		f.mu.Unlock()
+		f.started <- struct{}{}
+		if gate != nil {
+			<-gate
+		}
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.running--
+		if err != nil {
+			return nil, nil, err
+		}
+		client := newFakeCDP()
+		browser := rod.New().Client(client)
+		if err := browser.Connect(); err != nil {
+			return nil, nil, err
+		}
+		f.browsers = append(f.browsers, client)
+		return launcher.New(), browser, nil
+	}
+	return f
+}
+
+func (f *fakeLauncher) launches() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.browsers)
+}
+
+func (f *fakeLauncher) browser(i int) *fakeCDP {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.browsers[i]
+}
+
+func acquire(t *testing.T, m *Manager) *rod.Page {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	page, err := m.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	return page
+}
+
+func TestAcquireRelease(t *testing.T) {
+	f := useFakeLauncher(t)
+	m := NewManager(Options{PoolSize: 1})
+
+	page := acquire(t, m)
+	if f.launches() != 1 {
+		t.Fatalf("浏览器启动 %d 次，期望 1 次", f.launches())
+	}
+
+	// 池满时阻塞到ctx结束
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := m.Acquire(ctx); err == nil {
+		t.Fatal("池满时 Acquire 应在ctx结束后返回错误")
+	}
+
+	// 归还后阻塞的调用方拿到复用的页面，不再新建页面或重启浏览器
+	done := make(chan *rod.Page)
+	go func() {
+		page, err := m.Acquire(context.Background())
+		if err != nil {
+			t.Errorf("Acquire: %v", err)
+		}
+		done <- page
+	}()
+	select {
+	case <-done:
+		t.Fatal("页面未归还时 Acquire 不应返回")
+	case <-time.After(50 * time.Millisecond):
+	}
+	m.Release(page)
+	var reused *rod.Page
+	select {
+	case reused = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("页面归还后 Acquire 仍在阻塞")
+	}
+	client := f.browser(0)
+	if reused == nil || reused.TargetID != page.TargetID {
+		t.Errorf("复用的页面 = %v，期望 %s", reused, page.TargetID)
+	}
+	if n := client.count("Target.createTarget"); n != 1 || f.launches() != 1 {
+		t.Errorf("新建页面 %d 次、启动浏览器 %d 次，期望各 1 次", n, f.launches())
+	}
+	if n := client.count("Page.navigate"); n != 1 {
+		t.Errorf("归还时重置页面 %d 次，期望 1 次", n)
+	}
+
+	// 页面无法重置时直接丢弃，下次借用新建页面
+	client.set(func(c *fakeCDP) { c.fail["Page.navigate"] = true })
+	m.Release(reused)
+	page = acquire(t, m)
+	if page.TargetID != "T2" || client.count("Target.createTarget") != 2 {
+		t.Errorf("丢弃页面后借到 %s，新建页面 %d 次", page.TargetID, client.count("Target.createTarget"))
+	}
+
+	// 归还nil也会释放名额
+	m.Release(nil)
+	m.Release(acquire(t, m))
+}
+
+func TestAcquireRelaunch(t *testing.T) {
+	f := useFakeLauncher(t)
+	m := NewManager(Options{PoolSize: 2})
+	m.Release(acquire(t, m))
+
+	// 浏览器崩溃后空闲页面被回收，浏览器重新启动
+	f.browser(0).set(func(c *fakeCDP) { c.down = true })
+	page := acquire(t, m)
+	if f.launches() != 2 {
+		t.Fatalf("浏览器启动 %d 次，期望 2 次", f.launches())
+	}
+	if n := f.browser(1).count("Target.createTarget"); n != 1 {
+		t.Errorf("新浏览器新建页面 %d 次，期望 1 次", n)
+	}
+	m.Release(page)
+
+	// 启动失败时返回错误并释放名额，下次借用重试启动
+	f.browser(1).set(func(c *fakeCDP) { c.down = true })
+	f.mu.Lock()
+	f.err = errors.New("找不到浏览器")
+	f.mu.Unlock()
+	for range 3 {
+		if _, err := m.Acquire(context.Background()); err == nil {
+			t.Fatal("浏览器启动失败时 Acquire 应返回错误")
+		}
+	}
+	f.mu.Lock()
+	f.err = nil
+	f.mu.Unlock()
+	acquire(t, m)
+	if f.launches() != 3 {
+		t.Errorf("浏览器启动成功 %d 次，期望 3 次", f.launches())
+	}
+}
+
+func TestAcquireSingleLaunch(t *testing.T) {
+	f := useFakeLauncher(t)
+	f.gate = make(chan struct{})
+	m := NewManager(Options{PoolSize: 4})
+
+	// 多个调用方同时借用时只启动一次浏览器，其他调用方等待启动完成
+	var wg sync.WaitGroup
+	pages := make([]*rod.Page, 4)
+	for i := range pages {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			page, err := m.Acquire(context.Background())
+			if err != nil {
+				t.Errorf("Acquire: %v", err)
+			}
+			pages[i] = page
+		}()
+	}
+	<-f.started
+	time.Sleep(50 * time.Millisecond)
+	close(f.gate)
+	wg.Wait()
+
+	if f.launches() != 1 || f.maxRun != 1 {
+		t.Fatalf("浏览器启动 %d 次、同时启动 %d 个，期望各 1 个", f.launches(), f.maxRun)
+	}
+	seen := map[string]bool{}
+	for _, page := range pages {
+		if page == nil || seen[string(page.TargetID)] {
+			t.Fatalf("借出的页面重复或为空: %v", pages)
+		}
+		seen[string(page.TargetID)] = true
+	}
+}
+
+func TestClose(t *testing.T) {
+	f := useFakeLauncher(t)
+	m := NewManager(Options{PoolSize: 2})
+	busy := acquire(t, m)
+	m.Release(acquire(t, m))
+
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	client := f.browser(0)
+	if client.count("Browser.close") != 1 {
+		t.Errorf("关闭浏览器 %d 次，期望 1 次", client.count("Browser.close"))
+	}
+	// 重复关闭直接返回
+	if err := m.Close(); err != nil || client.count("Browser.close") != 1 {
+		t.Errorf("重复 Close = %v，关闭浏览器 %d 次", err, client.count("Browser.close"))
+	}
+
+	// 关闭后归还的页面直接关闭，借用返回 ErrClosed 且不占用名额
+	closed := client.count("Target.closeTarget") + client.count("Page.close")
+	m.Release(busy)
+	if n := client.count("Target.closeTarget") + client.count("Page.close"); n != closed+1 {
+		t.Errorf("关闭后归还的页面未关闭")
+	}
+	for range 3 {
+		if _, err := m.Acquire(context.Background()); !errors.Is(err, ErrClosed) {
+			t.Fatalf("关闭后 Acquire = %v，期望 ErrClosed", err)
+		}
+	}
+}
+
+func TestCloseDuringLaunch(t *testing.T) {
+	f := useFakeLauncher(t)
+	f.gate = make(chan struct{})
+	m := NewManager(Options{PoolSize: 1})
+
+	errc := make(chan error)
+	go func() {
+		_, err := m.Acquire(context.Background())
+		errc <- err
+	}()
+	<-f.started
+	// 启动在锁外进行，不会阻塞 Close
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	close(f.gate)
+	if err := <-errc; !errors.Is(err, ErrClosed) {
+		t.Fatalf("启动期间关闭后 Acquire = %v，期望 ErrClosed", err)
+	}
+	if n := f.browser(0).count("Browser.close"); n != 1 {
+		t.Errorf("启动期间关闭后浏览器关闭 %d 次，期望 1 次", n)
+	}
+}
@@ -3,13 +3,25 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config 配置结构
 type Config struct {
-	AI AIConfig `yaml:"ai"`
+	AI        AIConfig        `yaml:"ai"`
+	Browser   BrowserConfig   `yaml:"browser"`
+	Fetcher   FetcherConfig   `yaml:"fetcher"`
+	Crawler   CrawlerConfig   `yaml:"crawler"`
+	Security  SecurityConfig  `yaml:"security"`
+	Sector    SectorConfig    `yaml:"sector"`
+	Kline     KlineConfig     `yaml:"kline"`
+	Financial FinancialConfig `yaml:"financial"`
+	Feed      FeedConfig      `yaml:"feed"`
+	Extractor ExtractorConfig `yaml:"extractor"`
+	Archive   ArchiveConfig   `yaml:"archive"`
+	Cache     CacheConfig     `yaml:"cache"`
 }
 
 // AIConfig AI相关配置
@@ -20,6 +32,77 @@ type AIConfig struct {
 	ModelName string `yaml:"model_name"`
 }
 
+// BrowserConfig 爬虫浏览器相关配置
+type BrowserConfig struct {
+	PoolSize    int    `yaml:"pool_size"`    // 同时打开的最大页面数，默认4
+	Bin         string `yaml:"bin"`          // 浏览器可执行文件路径，为空时自动查找
+	ShowBrowser bool   `yaml:"show_browser"` // 显示浏览器窗口，调试时使用
+}
+
+// FetcherConfig 页面抓取方式配置
+type FetcherConfig struct {
+	Default string            `yaml:"default"` // 默认抓取方式：rod 或 http，默认rod
+	Sources map[string]string `yaml:"sources"` // 按数据源覆盖抓取方式，如 cls: http
+}
+
+// CrawlerConfig 并发抓取配置，未设置时使用默认值
+type CrawlerConfig struct {
+	Workers      int           `yaml:"workers"`       // 并发抓取的页面数
+	HostInterval time.Duration `yaml:"host_interval"` // 同一站点两次请求的最小间隔，如 500ms
+	Deadline     time.Duration `yaml:"deadline"`      // 单次搜索的整体截止时间，如 3m
+}
+
+// SecurityConfig 证券主数据配置
+type SecurityConfig struct {
+	Files []string `yaml:"files"` // 证券CSV文件或目录，默认 data/securities.csv
+}
+
+// SectorConfig 板块数据配置
+type SectorConfig struct {
+	Files  []string `yaml:"files"`  // 板块CSV文件或目录，默认 data/sectors.csv
+	Online bool     `yaml:"online"` // 同时从东方财富查询板块成分股，本地数据缺失时可补充
+}
+
+// KlineConfig K线数据源配置
+type KlineConfig struct {
+	Providers []string `yaml:"providers"` // 按顺序尝试的数据源：csv、eastmoney、xueqiu，默认 csv、eastmoney、xueqiu
+	CSVDir    string   `yaml:"csv_dir"`   // csv 数据源的目录，默认 data/kline
+}
+
+// FinancialConfig 财务数据源配置
+type FinancialConfig struct {
+	Providers []string      `yaml:"providers"` // 按顺序尝试的数据源：csv、eastmoney，默认 csv、eastmoney
+	CSVDir    string        `yaml:"csv_dir"`   // csv 数据源的目录，默认 data/financial
+	CacheDir  string        `yaml:"cache_dir"` // 缓存目录，默认 data/cache/financial
+	CacheTTL  time.Duration `yaml:"cache_ttl"` // 缓存有效期，默认24h
+}
+
+// FeedConfig RSS/Atom订阅源配置
+type FeedConfig struct {
+	URLs []string `yaml:"urls"` // 订阅源地址，支持 http(s) 地址和本地文件路径
+}
+
+// ExtractorConfig 页面提取规则配置
+type ExtractorConfig struct {
+	RulesDir   string `yaml:"rules_dir"`   // 规则目录，其中的同名规则覆盖内置规则，默认 rules
+	HealthFile string `yaml:"health_file"` // 各规则最近命中情况的基线文件，用于发现选择器失效，默认 data/cache/extractor_health.json
+}
+
+// ArchiveConfig 抓取存档配置，用于复现报告和离线测试
+type ArchiveConfig struct {
+	Mode string `yaml:"mode"` // off（默认）、record 记录每个抓取的页面和接口响应、replay 只从存档读取不访问网络
+	Dir  string `yaml:"dir"`  // 存档目录，默认 data/archive
+}
+
+// CacheConfig 页面缓存配置，同一会话重复查询时不再重新抓取
+type CacheConfig struct {
+	Disabled   bool              `yaml:"disabled"`    // 关闭页面缓存
+	Dir        string            `yaml:"dir"`         // 缓存目录，默认 data/cache/pages
+	DefaultTTL string            `yaml:"default_ttl"` // 未单独配置的页面的有效期，默认10m
+	TTL        map[string]string `yaml:"ttl"`         // 按页面类别覆盖有效期，如 cls_telegram: 2m、pdf: forever，0 表示不缓存
+	MaxSizeMB  int               `yaml:"max_size_mb"` // 缓存目录大小上限（MB），超出时删除最早的缓存，默认512，负数表示不限制
+}
+
 // LoadConfig 从配置文件加载配置
 func LoadConfig(configPath string) (*Config, error) {
 	// 如果未指定配置文件路径，使用默认路径
@@ -53,5 +136,58 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("配置文件中缺少 ai.model_name")
 	}
 
+	// 设置默认值
+	if config.Browser.PoolSize <= 0 {
+		config.Browser.PoolSize = 4
+	}
+	if len(config.Security.Files) == 0 {
+		config.Security.Files = []string{"data/securities.csv"}
+	}
+	if len(config.Sector.Files) == 0 {
+		config.Sector.Files = []string{"data/sectors.csv"}
+	}
+	if len(config.Kline.Providers) == 0 {
+		config.Kline.Providers = []string{"csv", "eastmoney", "xueqiu"}
+	}
+	if config.Kline.CSVDir == "" {
+		config.Kline.CSVDir = "data/kline"
+	}
+	if len(config.Financial.Providers) == 0 {
+		config.Financial.Providers = []string{"csv", "eastmoney"}
+	}
+	if config.Financial.CSVDir == "" {
+		config.Financial.CSVDir = "data/financial"
+	}
+	if config.Financial.CacheDir == "" {
+		config.Financial.CacheDir = "data/cache/financial"
+	}
+	if config.Financial.CacheTTL <= 0 {
+		config.Financial.CacheTTL = 24 * time.Hour
+	}
+	if config.Extractor.RulesDir == "" {
+		config.Extractor.RulesDir = "rules"
+	}
+	if config.Extractor.HealthFile == "" {
+		config.Extractor.HealthFile = "data/cache/extractor_health.json"
+	}
+	if config.Archive.Mode == "" {
+		config.Archive.Mode = "off"
+	}
+	if config.Archive.Dir == "" {
+		config.Archive.Dir = "data/archive"
+	}
+	if config.Cache.Dir == "" {
+		config.Cache.Dir = "data/cache/pages"
+	}
+	if config.Cache.MaxSizeMB == 0 {
+		config.Cache.MaxSizeMB = 512
+	}
+	if config.Cache.DefaultTTL == "" {
+		config.Cache.DefaultTTL = "10m"
+	}
+	if config.Fetcher.Default == "" {
+		config.Fetcher.Default = "rod"
+	}
+
 	return &config, nil
 }
@@ -0,0 +1,139 @@
+// Package dedup 基于 SimHash 检测不同来源转载的近似重复新闻并聚类
+package dedup
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// DefaultDistance 默认的海明距离阈值，64位指纹相差不超过3位视为近似重复
+const DefaultDistance = 3
+
+// shingleSize 计算指纹时每个特征包含的字符数
+const shingleSize = 3
+
+var (
+	urlPattern = regexp.MustCompile(`https?://\S+`)
+	// datelinePattern 电头，如 财联社10月15日电、新华社北京10月15日电，转载时常被删改
+	datelinePattern = regexp.MustCompile(`[\p{Han}]{0,8}\d{1,2}月\d{1,2}日(?:电|讯|消息)`)
+)
+
+// Normalize 规范化新闻文本：去掉链接、电头、标点和空白，英文转小写
+func Normalize(text string) string {
+	text = urlPattern.ReplaceAllString(text, "")
+	text = datelinePattern.ReplaceAllString(text, "")
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// SimHash 计算规范化后文本的64位指纹，特征为相邻的3个字符
+func SimHash(text string) uint64 {
+	return fingerprint(Normalize(text))
+}
+
+// fingerprint 计算已规范化文本的指纹
+func fingerprint(normalized string) uint64 {
+	runes := []rune(normalized)
+	if len(runes) == 0 {
+		return 0
+	}
+	var weights [64]int
+	add := func(feature string) {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		for i := 0; i < 64; i++ {
+			if sum&(1<<i) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+	if len(runes) < shingleSize {
+		add(string(runes))
+	}
+	for i := 0; i+shingleSize <= len(runes); i++ {
+		add(string(runes[i : i+shingleSize]))
+	}
+	var fingerprint uint64
+	for i, w := range weights {
+		if w > 0 {
+			fingerprint |= 1 << i
+		}
+	}
+	return fingerprint
+}
+
+// Distance 两个指纹的海明距离
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Cluster 将文本聚类，规范化后相同或指纹距离不超过 maxDistance 的文本归为一簇（可传递）
+// 返回每个簇中文本的下标，簇按首个成员的位置排序，簇内下标升序；规范化后为空的文本各自成簇
+func Cluster(texts []string, maxDistance int) [][]int {
+	parent := make([]int, len(texts))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(a, b int) {
+		ra, rb := find(a), find(b)
+		// 以较小的下标为根，保证簇按首个成员排序
+		if ra < rb {
+			parent[rb] = ra
+		} else if rb < ra {
+			parent[ra] = rb
+		}
+	}
+
+	normalized := make([]string, len(texts))
+	fingerprints := make([]uint64, len(texts))
+	for i, text := range texts {
+		normalized[i] = Normalize(text)
+		fingerprints[i] = fingerprint(normalized[i])
+	}
+	for i := range texts {
+		if normalized[i] == "" {
+			continue
+		}
+		for j := i + 1; j < len(texts); j++ {
+			if normalized[j] == "" {
+				continue
+			}
+			if normalized[i] == normalized[j] || Distance(fingerprints[i], fingerprints[j]) <= maxDistance {
+				union(i, j)
+			}
+		}
+	}
+
+	var clusters [][]int
+	index := make(map[int]int)
+	for i := range texts {
+		root := find(i)
+		k, ok := index[root]
+		if !ok {
+			k = len(clusters)
+			index[root] = k
+			clusters = append(clusters, nil)
+		}
+		clusters[k] = append(clusters[k], i)
+	}
+	return clusters
+}
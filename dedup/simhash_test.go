@@ -0,0 +1,68 @@
+package dedup
+
+import (
+	"reflect"
+	"testing"
+)
+
+const report = "中国农业银行今日发布公告称，一季度实现营业收入1860亿元，同比增长2.3%；归属于母公司股东的净利润718亿元，同比增长1.6%。" +
+	"净息差为1.53%，较上年末下降7个基点，不良贷款率1.33%，拨备覆盖率保持在300%以上。"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"财联社4月29日电，农行发布 Q1 业绩！", "农行发布q1业绩"},
+		{"新华社北京4月29日电 农行发布Q1业绩", "农行发布q1业绩"},
+		{"农行发布Q1业绩 https://www.cls.cn/detail/1001", "农行发布q1业绩"},
+		{"！！！……", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q，期望 %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSimHash(t *testing.T) {
+	if SimHash("财联社4月29日电，"+report) != SimHash("新华社北京4月29日电 "+report) {
+		t.Error("只有电头不同的转载指纹应相同")
+	}
+	if d := Distance(SimHash(report), SimHash(report+"（编辑 张明）")); d > DefaultDistance {
+		t.Errorf("末尾加编辑署名后距离 %d，期望不超过 %d", d, DefaultDistance)
+	}
+	if SimHash("") != 0 {
+		t.Error("空文本的指纹应为0")
+	}
+}
+
+func TestCluster(t *testing.T) {
+	tests := []struct {
+		name  string
+		texts []string
+		want  [][]int
+	}{
+		{"电头不同的转载", []string{
+			"财联社4月29日电，" + report,
+			"光伏组件价格继续下行，多晶硅库存高企",
+			"新华社北京4月29日电 " + report,
+			report + "责任编辑：李华",
+		}, [][]int{{0, 2, 3}, {1}}},
+		{"不相关的短文本", []string{
+			"两市成交额回升至万亿元",
+			"宁德时代发布钠离子电池",
+			"贵州茅台一季度直销收入占比提升",
+			"光伏组件价格继续下行，多晶硅库存高企",
+		}, [][]int{{0}, {1}, {2}, {3}}},
+		// 规范化后为空的文本指纹都是0，不能因此合并
+		{"规范化后为空", []string{"", "！！！", "https://www.cls.cn/detail/1001", "宁德时代发布钠离子电池"},
+			[][]int{{0}, {1}, {2}, {3}}},
+		{"完全相同", []string{"宁德时代发布钠离子电池", "宁德时代发布钠离子电池。"}, [][]int{{0, 1}}},
+		{"空输入", nil, nil},
+	}
+	for _, tt := range tests {
+		if got := Cluster(tt.texts, DefaultDistance); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Cluster = %v，期望 %v", tt.name, got, tt.want)
+		}
+	}
+}
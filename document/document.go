@@ -0,0 +1,231 @@
+// Package document 将公告等文档解析为按页组织的文本和表格，并切分为适合放入提示词的分块
+package document
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Document 解析后的文档
+type Document struct {
+	Pages []Page
+}
+
+// Page 单页内容，Blocks 按阅读顺序排列
+type Page struct {
+	Number int // 页码，从1开始
+	Blocks []Block
+}
+
+// Block 页面中的一段文本或一张表格，二者只有一个非空
+type Block struct {
+	Text  string
+	Table *Table
+}
+
+// Table 页面中检测到的表格，第一行通常为表头
+type Table struct {
+	Rows [][]string
+}
+
+// Chunk 文档分块，不跨越表格边界（除非单张表格超过分块大小）
+type Chunk struct {
+	Index     int    // 分块序号，从0开始
+	StartPage int    // 起始页码
+	EndPage   int    // 结束页码
+	Text      string // 分块文本，表格为markdown格式
+}
+
+// Text 返回页面文本，表格以markdown格式输出
+func (p Page) Text() string {
+	parts := make([]string, 0, len(p.Blocks))
+	for _, b := range p.Blocks {
+		parts = append(parts, b.String())
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Tables 返回页面中的全部表格
+func (p Page) Tables() []*Table {
+	var tables []*Table
+	for _, b := range p.Blocks {
+		if b.Table != nil {
+			tables = append(tables, b.Table)
+		}
+	}
+	return tables
+}
+
+// String 返回块的文本，表格以markdown格式输出
+func (b Block) String() string {
+	if b.Table != nil {
+		return b.Table.Markdown()
+	}
+	return b.Text
+}
+
+// Markdown 将表格输出为markdown表格，第一行作为表头
+func (t *Table) Markdown() string {
+	if len(t.Rows) == 0 {
+		return ""
+	}
+	columns := 0
+	for _, row := range t.Rows {
+		columns = max(columns, len(row))
+	}
+	var b strings.Builder
+	writeRow := func(row []string) {
+		b.WriteString("|")
+		for i := 0; i < columns; i++ {
+			cell := ""
+			if i < len(row) {
+				cell = strings.ReplaceAll(row[i], "|", "\\|")
+			}
+			b.WriteString(" " + cell + " |")
+		}
+		b.WriteString("\n")
+	}
+	writeRow(t.Rows[0])
+	b.WriteString("|" + strings.Repeat("---|", columns) + "\n")
+	for _, row := range t.Rows[1:] {
+		writeRow(row)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Text 返回全文，每页前标注页码
+func (d *Document) Text() string {
+	var b strings.Builder
+	for _, p := range d.Pages {
+		text := p.Text()
+		if text == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "[第%d页]\n%s\n", p.Number, text)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// TableCount 返回全文检测到的表格数量
+func (d *Document) TableCount() int {
+	n := 0
+	for _, p := range d.Pages {
+		n += len(p.Tables())
+	}
+	return n
+}
+
+// Chunks 按页顺序将文档切分为不超过 maxRunes 个字符的分块
+// 文本块和表格尽量完整地放在同一分块中，超长的块按行切分，超长的行按字符切分
+func (d *Document) Chunks(maxRunes int) []Chunk {
+	if maxRunes <= 0 {
+		return nil
+	}
+	var chunks []Chunk
+	var current []string
+	size, start, end := 0, 0, 0
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		chunks = append(chunks, Chunk{Index: len(chunks), StartPage: start, EndPage: end, Text: strings.Join(current, "\n")})
+		current, size = nil, 0
+	}
+	add := func(text string, page int) {
+		n := utf8.RuneCountInString(text)
+		// 与前一块之间有一个换行符
+		if len(current) > 0 && size+1+n > maxRunes {
+			flush()
+		}
+		if len(current) == 0 {
+			start = page
+		} else {
+			size++
+		}
+		current = append(current, text)
+		size += n
+		end = page
+	}
+
+	for _, p := range d.Pages {
+		for _, b := range p.Blocks {
+			text := b.String()
+			if text == "" {
+				continue
+			}
+			if utf8.RuneCountInString(text) <= maxRunes {
+				add(text, p.Number)
+				continue
+			}
+			var pieces []string
+			if b.Table != nil {
+				pieces = splitTable(b.Table, maxRunes)
+			} else {
+				pieces = splitLines(text, maxRunes)
+			}
+			for _, piece := range pieces {
+				add(piece, p.Number)
+			}
+		}
+	}
+	flush()
+	return chunks
+}
+
+// splitTable 将超长表格按行切分，每个片段重复表头
+func splitTable(t *Table, maxRunes int) []string {
+	lines := strings.Split(t.Markdown(), "\n")
+	header := strings.Join(lines[:2], "\n")
+	rowRunes := maxRunes - utf8.RuneCountInString(header) - 1
+	// 表头本身过长时退化为按行切分
+	if rowRunes <= 0 {
+		return splitLines(t.Markdown(), maxRunes)
+	}
+	var pieces []string
+	for _, rows := range splitLines(strings.Join(lines[2:], "\n"), rowRunes) {
+		pieces = append(pieces, header+"\n"+rows)
+	}
+	return pieces
+}
+
+// splitLines 将超长文本按行切分为不超过 maxRunes 个字符的片段
+func splitLines(text string, maxRunes int) []string {
+	var pieces []string
+	var b strings.Builder
+	size := 0
+	for _, line := range strings.Split(text, "\n") {
+		for _, part := range splitRunes(line, maxRunes) {
+			n := utf8.RuneCountInString(part)
+			if size > 0 && size+n+1 > maxRunes {
+				pieces = append(pieces, b.String())
+				b.Reset()
+				size = 0
+			}
+			if size > 0 {
+				b.WriteString("\n")
+				size++
+			}
+			b.WriteString(part)
+			size += n
+		}
+	}
+	if size > 0 {
+		pieces = append(pieces, b.String())
+	}
+	return pieces
+}
+
+// splitRunes 将单行按字符数切分
+func splitRunes(line string, maxRunes int) []string {
+	runes := []rune(line)
+	if len(runes) <= maxRunes {
+		return []string{line}
+	}
+	var parts []string
+	for len(runes) > maxRunes {
+		parts = append(parts, string(runes[:maxRunes]))
+		runes = runes[maxRunes:]
+	}
+	return append(parts, string(runes))
+}
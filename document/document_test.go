@@ -0,0 +1,195 @@
+package document
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestReadPDFFile(t *testing.T) {
+	doc, err := ReadPDFFile("testdata/report.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Pages) != 2 {
+		t.Fatalf("页数 %d，期望 2", len(doc.Pages))
+	}
+	if doc.TableCount() != 1 {
+		t.Errorf("表格数 %d，期望 1", doc.TableCount())
+	}
+
+	want := `[第1页]
+Annual Report 2024
+Revenue grew strongly in the fourth quarter.
+| Item | 2024 | 2023 |
+|---|---|---|
+| Revenue | 1,200 | 1,000 |
+| Net profit | 300 | 240 |
+Figures in millions of yuan.
+[第2页]
+Outlook
+Management expects stable growth next year.`
+	if got := doc.Text(); got != want {
+		t.Errorf("Text() =\n%s\n期望\n%s", got, want)
+	}
+
+	// 表格前后的文字是独立的文本块
+	blocks := doc.Pages[0].Blocks
+	if len(blocks) != 3 || blocks[0].Table != nil || blocks[1].Table == nil || blocks[2].Table != nil {
+		t.Fatalf("第1页应为 文本、表格、文本 三块，得到 %d 块", len(blocks))
+	}
+	rows := blocks[1].Table.Rows
+	if len(rows) != 3 || strings.Join(rows[2], ",") != "Net profit,300,240" {
+		t.Errorf("表格行 = %q", rows)
+	}
+}
+
+func TestReadPDFFileCIDFont(t *testing.T) {
+	// 中文PDF常见的 Type0 字体、Identity-H 编码，没有字宽，同一段文字的字符坐标相同
+	doc, err := ReadPDFFile("testdata/report_cid.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 同一行分两次输出的文字、表格单元格按估算的字宽拼回一段
+	want := `[第1页]
+2024年年度报告摘要
+公司营业收入稳步增长。
+| 项目 | 2024年 | 2023年 |
+|---|---|---|
+| 营业收入（亿元） | 1,200.5 | 1,000.3 |
+| 净利润（亿元） | 300.2 | 240.8 |
+数据来源：公司年度报告。`
+	if got := doc.Text(); got != want {
+		t.Errorf("Text() =\n%s\n期望\n%s", got, want)
+	}
+	if doc.TableCount() != 1 {
+		t.Errorf("表格数 %d，期望 1", doc.TableCount())
+	}
+}
+
+func TestEstimateWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want float64
+	}{
+		{"营业收入", 40},
+		{"2024年", 30},
+		{"1,200.5", 35},
+	}
+	for _, tt := range tests {
+		if got := estimateWidth(glyph{size: 10, s: tt.s}); got != tt.want {
+			t.Errorf("estimateWidth(%q) = %v，期望 %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestParsePDFInvalid(t *testing.T) {
+	if _, err := ParsePDF([]byte("<html>not a pdf</html>")); err == nil {
+		t.Error("非PDF数据应返回错误")
+	}
+	if _, err := ParsePDF([]byte("%PDF-1.4\ngarbage")); err == nil {
+		t.Error("损坏的PDF应返回错误")
+	}
+	if _, err := ReadPDFFile("testdata/missing.pdf"); err == nil {
+		t.Error("文件不存在时应返回错误")
+	}
+}
+
+func testDocument() *Document {
+	return &Document{Pages: []Page{
+		{Number: 1, Blocks: []Block{
+			{Text: "第一段"},
+			{Table: &Table{Rows: [][]string{{"项目", "本期"}, {"营业收入", "100"}, {"净利润", "20"}}}},
+		}},
+		{Number: 2, Blocks: []Block{{Text: "第二页"}}},
+	}}
+}
+
+func TestChunks(t *testing.T) {
+	table := testDocument().Pages[0].Blocks[1].Table.Markdown()
+	tests := []struct {
+		name     string
+		maxRunes int
+		want     []Chunk
+	}{
+		{"全部放入一块", 1000, []Chunk{
+			{Index: 0, StartPage: 1, EndPage: 2, Text: "第一段\n" + table + "\n第二页"},
+		}},
+		// 表格不与前后文本拆开，放不下时单独成块
+		{"表格独立成块", utf8.RuneCountInString(table), []Chunk{
+			{Index: 0, StartPage: 1, EndPage: 1, Text: "第一段"},
+			{Index: 1, StartPage: 1, EndPage: 1, Text: table},
+			{Index: 2, StartPage: 2, EndPage: 2, Text: "第二页"},
+		}},
+		// 刚好容纳 文本+换行+表格
+		{"恰好容纳", utf8.RuneCountInString(table) + 4, []Chunk{
+			{Index: 0, StartPage: 1, EndPage: 1, Text: "第一段\n" + table},
+			{Index: 1, StartPage: 2, EndPage: 2, Text: "第二页"},
+		}},
+		{"非法大小", 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := testDocument().Chunks(tt.maxRunes)
+			if len(got) != len(tt.want) {
+				t.Fatalf("分块数 %d，期望 %d: %q", len(got), len(tt.want), got)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("分块 %d = %+v，期望 %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestChunksSplitLongTable(t *testing.T) {
+	doc := testDocument()
+	header := "| 项目 | 本期 |\n|---|---|"
+	maxRunes := utf8.RuneCountInString(header) + 1 + utf8.RuneCountInString("| 营业收入 | 100 |")
+	chunks := doc.Chunks(maxRunes)
+	var tableChunks []string
+	for _, c := range chunks {
+		if n := utf8.RuneCountInString(c.Text); n > maxRunes {
+			t.Errorf("分块 %d 长度 %d 超过 %d", c.Index, n, maxRunes)
+		}
+		if strings.HasPrefix(c.Text, "| ") {
+			tableChunks = append(tableChunks, c.Text)
+		}
+	}
+	want := []string{header + "\n| 营业收入 | 100 |", header + "\n| 净利润 | 20 |"}
+	if strings.Join(tableChunks, "\n\n") != strings.Join(want, "\n\n") {
+		t.Errorf("表格分块 = %q，期望每块重复表头 %q", tableChunks, want)
+	}
+}
+
+func TestSplitTable(t *testing.T) {
+	table := &Table{Rows: [][]string{{"a", "b"}, {"1", "2"}, {"3", "4"}, {"5", "6"}}}
+	header := "| a | b |\n|---|---|"
+	tests := []struct {
+		name     string
+		maxRunes int
+		want     []string
+	}{
+		{"每块一行", 29, []string{header + "\n| 1 | 2 |", header + "\n| 3 | 4 |", header + "\n| 5 | 6 |"}},
+		{"每块两行", 39, []string{header + "\n| 1 | 2 |\n| 3 | 4 |", header + "\n| 5 | 6 |"}},
+		// 表头本身放不下时按行切分，不再重复表头
+		{"表头过长", 9, []string{"| a | b |", "|---|---|", "| 1 | 2 |", "| 3 | 4 |", "| 5 | 6 |"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitTable(table, tt.maxRunes)
+			if strings.Join(got, "\n\n") != strings.Join(tt.want, "\n\n") {
+				t.Errorf("splitTable = %q，期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	got := splitLines("一二三四五\n六七\n八", 3)
+	want := []string{"一二三", "四五", "六七", "八"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("splitLines = %q，期望 %q", got, want)
+	}
+}
@@ -0,0 +1,257 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ledongthuc/pdf"
+)
+
+const (
+	// lineTolerance 基线相差不超过字号的该比例时视为同一行
+	lineTolerance = 0.5
+	// cellGap 同一行内文字间距超过字号的该倍数时视为不同单元格
+	cellGap = 1.5
+	// wordGap 同一单元格内文字间距超过字号的该比例时补一个空格
+	wordGap = 0.3
+	// minTableRows 连续多少行都有多个单元格时视为表格
+	minTableRows = 2
+)
+
+// IsPDF 判断数据是否为PDF文件
+func IsPDF(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(data[:min(len(data), 1024)], "\r\n\t "), []byte("%PDF-"))
+}
+
+// ReadPDFFile 解析本地PDF文件
+func ReadPDFFile(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取PDF文件失败: %v", err)
+	}
+	return ParsePDF(data)
+}
+
+// ParsePDF 逐页提取PDF文本并检测表格，无法解析的页面跳过
+func ParsePDF(data []byte) (doc *Document, err error) {
+	// 解析库遇到格式异常的PDF可能panic
+	defer func() {
+		if r := recover(); r != nil {
+			doc, err = nil, fmt.Errorf("解析PDF失败: %v", r)
+		}
+	}()
+	if !IsPDF(data) {
+		return nil, fmt.Errorf("不是PDF文件")
+	}
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("解析PDF失败: %v", err)
+	}
+	doc = &Document{}
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		doc.Pages = append(doc.Pages, Page{Number: i, Blocks: layoutPage(pageGlyphs(page))})
+	}
+	return doc, nil
+}
+
+// glyph 页面上的单个字符
+type glyph struct {
+	x, y, w, size float64
+	s             string
+}
+
+// pageGlyphs 提取页面上所有字符的位置，单页解析失败时返回空
+func pageGlyphs(page pdf.Page) (glyphs []glyph) {
+	defer func() {
+		if recover() != nil {
+			glyphs = nil
+		}
+	}()
+	for _, t := range page.Content().Text {
+		if t.S == "" || t.S == "\n" {
+			continue
+		}
+		size := t.FontSize
+		if size <= 0 {
+			size = 10
+		}
+		glyphs = append(glyphs, glyph{x: t.X, y: t.Y, w: t.W, size: size, s: t.S})
+	}
+	return glyphs
+}
+
+// cell 同一行内连续的一段文字
+type cell struct {
+	text   strings.Builder
+	x0, x1 float64
+}
+
+// layoutPage 将字符按行、单元格组织，连续的多单元格行识别为表格
+func layoutPage(glyphs []glyph) []Block {
+	var blocks []Block
+	var textLines []string
+	var tableRows [][]*cell
+	flushText := func() {
+		if len(textLines) > 0 {
+			blocks = append(blocks, Block{Text: strings.Join(textLines, "\n")})
+			textLines = nil
+		}
+	}
+	flushTable := func() {
+		if len(tableRows) >= minTableRows {
+			flushText()
+			blocks = append(blocks, Block{Table: buildTable(tableRows)})
+		} else {
+			for _, row := range tableRows {
+				textLines = append(textLines, joinCells(row))
+			}
+		}
+		tableRows = nil
+	}
+
+	for _, line := range groupLines(glyphs) {
+		cells := splitCells(line)
+		if len(cells) == 0 {
+			continue
+		}
+		if len(cells) >= 2 {
+			tableRows = append(tableRows, cells)
+			continue
+		}
+		flushTable()
+		textLines = append(textLines, joinCells(cells))
+	}
+	flushTable()
+	flushText()
+	return blocks
+}
+
+// groupLines 按基线将字符分行，行从上到下，PDF坐标系的y轴向上
+func groupLines(glyphs []glyph) [][]glyph {
+	sorted := append([]glyph(nil), glyphs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].y > sorted[j].y })
+
+	var lines [][]glyph
+	var lineY float64
+	for _, g := range sorted {
+		n := len(lines)
+		if n > 0 && lineY-g.y <= g.size*lineTolerance {
+			lines[n-1] = append(lines[n-1], g)
+			continue
+		}
+		lines = append(lines, []glyph{g})
+		lineY = g.y
+	}
+	return lines
+}
+
+// splitCells 将一行字符按水平间距切分为单元格
+// 中文PDF常用的CID字体取不到字宽，同一段文字的字符坐标相同，此时按字号估算宽度
+func splitCells(line []glyph) []*cell {
+	sort.SliceStable(line, func(i, j int) bool { return line[i].x < line[j].x })
+
+	var cells []*cell
+	var cur *cell
+	for _, g := range line {
+		w := g.w
+		if w <= 0 {
+			w = estimateWidth(g)
+		}
+		if cur == nil || g.x > cur.x1+g.size*cellGap {
+			cur = &cell{x0: g.x, x1: g.x}
+			cells = append(cells, cur)
+		} else if g.x > cur.x1+g.size*wordGap && !strings.HasSuffix(cur.text.String(), " ") {
+			cur.text.WriteString(" ")
+		}
+		cur.text.WriteString(g.s)
+		if g.w > 0 {
+			cur.x1 = math.Max(cur.x1, g.x+w)
+		} else {
+			cur.x1 = math.Max(cur.x1, g.x) + w
+		}
+	}
+
+	result := cells[:0]
+	for _, c := range cells {
+		if strings.TrimSpace(c.text.String()) != "" {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// estimateWidth 估算字符宽度：全角字符约为一个字号，半角约为一半
+func estimateWidth(g glyph) float64 {
+	w := 0.0
+	for _, r := range g.s {
+		if r > unicode.MaxLatin1 {
+			w += g.size
+		} else {
+			w += g.size / 2
+		}
+	}
+	return w
+}
+
+// joinCells 将非表格行的单元格拼接为一行文本
+func joinCells(cells []*cell) string {
+	parts := make([]string, len(cells))
+	for i, c := range cells {
+		parts[i] = strings.TrimSpace(c.text.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// buildTable 以单元格最多的行确定列位置，其余行的单元格归入重叠最多的列
+func buildTable(rows [][]*cell) *Table {
+	anchors := rows[0]
+	for _, row := range rows {
+		if len(row) > len(anchors) {
+			anchors = row
+		}
+	}
+
+	table := &Table{Rows: make([][]string, len(rows))}
+	for i, row := range rows {
+		values := make([]string, len(anchors))
+		for _, c := range row {
+			col := nearestColumn(anchors, c)
+			text := strings.TrimSpace(c.text.String())
+			if values[col] != "" {
+				text = values[col] + " " + text
+			}
+			values[col] = text
+		}
+		table.Rows[i] = values
+	}
+	return table
+}
+
+// nearestColumn 返回与单元格水平重叠最多的列，都不重叠时返回中心最近的列
+func nearestColumn(anchors []*cell, c *cell) int {
+	best, bestOverlap, bestDistance := 0, 0.0, math.MaxFloat64
+	for i, a := range anchors {
+		overlap := math.Min(a.x1, c.x1) - math.Max(a.x0, c.x0)
+		if overlap > bestOverlap {
+			best, bestOverlap = i, overlap
+			continue
+		}
+		if bestOverlap > 0 {
+			continue
+		}
+		distance := math.Abs((a.x0 + a.x1 - c.x0 - c.x1) / 2)
+		if distance < bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	return best
+}
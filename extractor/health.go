@@ -0,0 +1,240 @@
+package extractor
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// healthWindow 每条规则保留的最近提取次数，作为滚动基线
+	healthWindow = 20
+	// minBaselineSamples 基线至少需要的提取次数，不足时只检查列表和必填字段是否完全未命中
+	minBaselineSamples = 3
+	// itemDropRatio 列表项数低于基线中位数的该比例时视为骤降
+	itemDropRatio = 0.3
+	// fieldDropRatio 字段命中率低于基线的该比例时视为下降
+	fieldDropRatio = 0.5
+	// minMissStreak 列表选择器连续全部未命中的次数达到该值时才认为页面已改版
+	minMissStreak = 3
+)
+
+// Sample 单次提取的命中统计
+type Sample struct {
+	Time         time.Time      `json:"time"`
+	ListSelector string         `json:"listSelector,omitempty"` // 命中的列表选择器
+	Items        int            `json:"items"`
+	Records      int            `json:"records"`
+	Fields       map[string]int `json:"fields"`              // 各字段命中的项数
+	Selectors    map[string]int `json:"selectors,omitempty"` // 各字段选择器命中的项数，键为 字段名 选择器
+}
+
+// Issue 提取结果相对基线的异常
+type Issue struct {
+	Rule    string `json:"rule"`
+	Source  string `json:"source"`
+	Field   string `json:"field,omitempty"` // 为空表示列表整体的问题
+	Severe  bool   `json:"severe"`          // 规则没有提取到任何可用数据
+	Message string `json:"message"`
+}
+
+// String 输出可读的异常描述
+func (i Issue) String() string {
+	if i.Field != "" {
+		return fmt.Sprintf("规则 %s 字段 %s: %s", i.Rule, i.Field, i.Message)
+	}
+	return fmt.Sprintf("规则 %s: %s", i.Rule, i.Message)
+}
+
+// Monitor 记录每条规则的命中情况，与最近几次提取的基线比较，发现选择器失效或页面改版
+type Monitor struct {
+	mu      sync.Mutex
+	path    string              // 基线文件，为空时只保存在内存中
+	history map[string][]Sample // 按规则名保存最近的提取统计
+}
+
+// NewMonitor 创建监控器并加载已保存的基线，path 为空时不持久化，文件不存在时从空基线开始
+// 基线文件无法读取或已损坏时仍返回从空基线开始的监控器，同时返回错误
+func NewMonitor(path string) (*Monitor, error) {
+	m := &Monitor{path: path, history: make(map[string][]Sample)}
+	if path == "" {
+		return m, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return m, fmt.Errorf("读取提取基线失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &m.history); err != nil {
+		m.history = make(map[string][]Sample)
+		return m, fmt.Errorf("解析提取基线失败: %v", err)
+	}
+	return m, nil
+}
+
+// Observe 将本次提取结果与基线比较，返回发现的异常，并把本次结果计入基线
+// 返回的错误仅表示基线保存失败，异常检查结果仍然有效
+func (m *Monitor) Observe(rule *Rule, rep Report) ([]Issue, error) {
+	sample := newSample(rep)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	issues := checkSample(rule, sample, m.history[rule.Name])
+	history := append(m.history[rule.Name], sample)
+	if len(history) > healthWindow {
+		history = history[len(history)-healthWindow:]
+	}
+	m.history[rule.Name] = history
+	return issues, m.save()
+}
+
+// Baseline 返回规则最近的提取统计（按时间先后）
+func (m *Monitor) Baseline(name string) []Sample {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.history[name])
+}
+
+// save 将基线写入文件，先写临时文件再改名，避免中断时留下不完整的文件
+func (m *Monitor) save() error {
+	if m.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(m.history)
+	if err != nil {
+		return fmt.Errorf("保存提取基线失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o755); err != nil {
+		return fmt.Errorf("保存提取基线失败: %v", err)
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("保存提取基线失败: %v", err)
+	}
+	if err := os.Rename(tmp, m.path); err != nil {
+		return fmt.Errorf("保存提取基线失败: %v", err)
+	}
+	return nil
+}
+
+// newSample 从校验报告中取出命中统计
+func newSample(rep Report) Sample {
+	s := Sample{
+		Time:         time.Now(),
+		ListSelector: rep.ListSelector,
+		Items:        rep.Items,
+		Records:      rep.Records,
+		Fields:       make(map[string]int, len(rep.Fields)),
+		Selectors:    make(map[string]int),
+	}
+	for _, f := range rep.Fields {
+		s.Fields[f.Name] = f.Matched
+		for selector, n := range f.Selectors {
+			s.Selectors[f.Name+" "+selectorLabel(selector)] = n
+		}
+	}
+	return s
+}
+
+// checkSample 检查本次提取：列表连续多次或必填字段完全未命中，以及列表项数、字段命中率明显低于基线
+func checkSample(rule *Rule, s Sample, baseline []Sample) []Issue {
+	var issues []Issue
+	add := func(field string, severe bool, format string, args ...any) {
+		issues = append(issues, Issue{Rule: rule.Name, Source: rule.Source, Field: field, Severe: severe, Message: fmt.Sprintf(format, args...)})
+	}
+	hasBaseline := len(baseline) >= minBaselineSamples
+	baseRecords := median(baseline, func(b Sample) float64 { return float64(b.Records) })
+	missingRequired := false
+	for name, f := range rule.Fields {
+		if f.Required && s.Fields[name] == 0 {
+			missingRequired = true
+		}
+	}
+
+	switch {
+	case len(rule.List) > 0 && s.Items == 0:
+		// 列表全部未命中也可能是搜索确实没有结果，连续多次未命中才认为页面已改版
+		if n := missStreak(baseline) + 1; n >= minMissStreak {
+			add("", true, "列表选择器连续 %d 次全部未命中，页面可能已改版", n)
+		}
+	case s.Records == 0 && hasBaseline && baseRecords > 0:
+		add("", true, "未提取到数据，最近 %d 次提取中位数为 %.0f 项，页面可能已改版", len(baseline), baseRecords)
+	case s.Records == 0 && !missingRequired:
+		add("", true, "共 %d 项，但没有一项包含全部必填字段", s.Items)
+	case hasBaseline && baseRecords >= 5 && float64(s.Records) < baseRecords*itemDropRatio:
+		add("", false, "只提取到 %d 项，最近 %d 次提取中位数为 %.0f 项", s.Records, len(baseline), baseRecords)
+	}
+	if s.Items == 0 {
+		return issues
+	}
+
+	// 主选择器失效、退回备用选择器时提示更新规则
+	if len(rule.List) > 1 && s.ListSelector != "" && s.ListSelector != rule.List[0] && hasBaseline {
+		primary := 0
+		for _, b := range baseline {
+			if b.ListSelector == rule.List[0] {
+				primary++
+			}
+		}
+		if primary*2 > len(baseline) {
+			add("", false, "主列表选择器 %s 未命中，已退回 %s", rule.List[0], s.ListSelector)
+		}
+	}
+
+	for name, f := range rule.Fields {
+		matched := s.Fields[name]
+		if f.Required && matched == 0 {
+			add(name, s.Records == 0, "必填字段在 %d 项中全部未命中", s.Items)
+			continue
+		}
+		if !hasBaseline {
+			continue
+		}
+		ratio := float64(matched) / float64(s.Items)
+		baseRatio := median(baseline, func(b Sample) float64 {
+			if b.Items == 0 {
+				return 0
+			}
+			return float64(b.Fields[name]) / float64(b.Items)
+		})
+		if baseRatio >= 0.5 && ratio < baseRatio*fieldDropRatio {
+			add(name, false, "命中率从 %.0f%% 降至 %.0f%%", baseRatio*100, ratio*100)
+		}
+	}
+	slices.SortStableFunc(issues, func(a, b Issue) int { return cmp.Compare(a.Field, b.Field) })
+	return issues
+}
+
+// missStreak 返回基线末尾连续列表未命中的次数
+func missStreak(baseline []Sample) int {
+	n := 0
+	for i := len(baseline) - 1; i >= 0 && baseline[i].Items == 0; i-- {
+		n++
+	}
+	return n
+}
+
+// median 返回基线中某项统计的中位数，基线为空时为0
+func median(samples []Sample, value func(Sample) float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	values := make([]float64, len(samples))
+	for i, s := range samples {
+		values[i] = value(s)
+	}
+	slices.Sort(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
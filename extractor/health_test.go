@@ -0,0 +1,60 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckSample(t *testing.T) {
+	rule := &Rule{
+		Name:   "xueqiu_search",
+		Source: "xueqiu",
+		List:   Selectors{"table a", "td a"},
+		Fields: map[string]Field{"url": {Attr: "href", Required: true}},
+	}
+	hit := func(n int) Sample {
+		return Sample{ListSelector: "table a", Items: n, Records: n, Fields: map[string]int{"url": n}}
+	}
+	miss := Sample{Fields: map[string]int{}}
+	healthy := []Sample{hit(8), hit(10), hit(9)}
+
+	tests := []struct {
+		name     string
+		sample   Sample
+		baseline []Sample
+		want     []string // 期望的异常描述片段，为空表示没有异常
+		severe   bool
+	}{
+		{"正常", hit(9), healthy, nil, false},
+		// 搜索确实没有结果时列表选择器不会命中，不应判定为改版
+		{"列表偶尔未命中", miss, healthy, nil, false},
+		{"列表连续两次未命中", miss, append(healthy, miss), nil, false},
+		{"列表连续三次未命中", miss, append(healthy, miss, miss), []string{"连续 3 次全部未命中，页面可能已改版"}, true},
+		{"没有基线时连续未命中", miss, []Sample{miss, miss}, []string{"连续 3 次全部未命中"}, true},
+		{"列表命中但没有数据", Sample{ListSelector: "table a", Items: 5, Fields: map[string]int{}}, healthy,
+			[]string{"未提取到数据，最近 3 次提取中位数为 9 项，页面可能已改版", "必填字段在 5 项中全部未命中"}, true},
+		{"数量骤降", hit(2), healthy, []string{"只提取到 2 项"}, false},
+		{"退回备用选择器", Sample{ListSelector: "td a", Items: 9, Records: 9, Fields: map[string]int{"url": 9}}, healthy,
+			[]string{"主列表选择器 table a 未命中"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := checkSample(rule, tt.sample, tt.baseline)
+			if len(issues) != len(tt.want) {
+				t.Fatalf("异常 %v，期望 %d 个", issues, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(issues[i].Message, want) {
+					t.Errorf("异常 %d = %q，期望包含 %q", i, issues[i].Message, want)
+				}
+			}
+			severe := false
+			for _, issue := range issues {
+				severe = severe || issue.Severe
+			}
+			if severe != tt.severe {
+				t.Errorf("严重 = %v，期望 %v", severe, tt.severe)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package extractor
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultRules 内置规则，规则目录中的同名规则会覆盖它们
+//
+//go:embed rules/*.yaml
+var defaultRules embed.FS
+
+// Registry 按名称管理提取规则
+type Registry struct {
+	mu    sync.RWMutex
+	rules map[string]*Rule
+}
+
+// NewRegistry 创建空的规则注册表
+func NewRegistry() *Registry {
+	return &Registry{rules: make(map[string]*Rule)}
+}
+
+// DefaultRegistry 创建加载了内置规则的注册表
+func DefaultRegistry() (*Registry, error) {
+	r := NewRegistry()
+	files, err := fs.Glob(defaultRules, "rules/*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := defaultRules.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := r.Load(data, file); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Load 解析一个规则文件的内容并注册其中的规则，同名规则被覆盖，返回注册的规则数
+func (r *Registry) Load(data []byte, name string) (int, error) {
+	var set RuleSet
+	if err := yaml.Unmarshal(data, &set); err != nil {
+		return 0, fmt.Errorf("解析规则文件 %s 失败: %v", name, err)
+	}
+	for _, rule := range set.Rules {
+		if rule.Source == "" {
+			rule.Source = set.Site
+		}
+		if err := rule.compile(); err != nil {
+			return 0, fmt.Errorf("规则文件 %s: %v", name, err)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rule := range set.Rules {
+		r.rules[rule.Name] = rule
+	}
+	return len(set.Rules), nil
+}
+
+// LoadFile 加载单个规则文件
+func (r *Registry) LoadFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("读取规则文件失败: %v", err)
+	}
+	return r.Load(data, path)
+}
+
+// LoadDir 加载目录下全部 .yaml、.yml 规则文件，目录不存在时不报错
+func (r *Registry) LoadDir(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("读取规则目录失败: %v", err)
+	}
+	total := 0
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		n, err := r.LoadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// Get 按名称获取规则
+func (r *Registry) Get(name string) (*Rule, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rule, ok := r.rules[name]
+	return rule, ok
+}
+
+// Names 返回全部规则名（按名称排序）
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.rules))
+	for name := range r.rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
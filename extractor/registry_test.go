@@ -0,0 +1,182 @@
+package extractor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stock_agent/newstime"
+
+	"github.com/PuerkitoBio/goquery"
+	"gopkg.in/yaml.v3"
+)
+
+const testRules = `
+site: xueqiu
+rules:
+  - name: xueqiu_search
+    description: 第一版
+    list: "td a"
+    fields:
+      url:
+        attr: href
+        required: true
+  - name: xueqiu_hot
+    source: xueqiu_api
+    fields:
+      title:
+        selector: h1
+`
+
+func TestRegistryLoad(t *testing.T) {
+	r := NewRegistry()
+	n, err := r.Load([]byte(testRules), "xueqiu.yaml")
+	if err != nil || n != 2 {
+		t.Fatalf("Load = %d %v，期望 2 条规则", n, err)
+	}
+	// 未指定 source 时使用规则文件的 site
+	if rule, ok := r.Get("xueqiu_search"); !ok || rule.Source != "xueqiu" {
+		t.Errorf("xueqiu_search = %+v %v", rule, ok)
+	}
+	if rule, _ := r.Get("xueqiu_hot"); rule.Source != "xueqiu_api" {
+		t.Errorf("指定的 source = %q，期望 xueqiu_api", rule.Source)
+	}
+
+	// 同名规则被后加载的覆盖，其余规则保留
+	override := "rules:\n  - name: xueqiu_search\n    description: 第二版\n"
+	if _, err := r.Load([]byte(override), "override.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if rule, _ := r.Get("xueqiu_search"); rule.Description != "第二版" || rule.Source != "" {
+		t.Errorf("覆盖后 = %+v", rule)
+	}
+	if got := strings.Join(r.Names(), ","); got != "xueqiu_hot,xueqiu_search" {
+		t.Errorf("Names = %s", got)
+	}
+
+	// 任一规则无效时整个文件都不注册
+	invalid := []string{
+		"rules:\n  - description: 没有名称\n",
+		"rules:\n  - name: bad_pattern\n    fields:\n      time:\n        pattern: \"([0-9]+\"\n",
+		"rules:\n  - name: ok_rule\n  - name: bad_list\n    list: \"td >> a\"\n",
+		"rules:\n  - name: bad_wait\n    wait:\n      selector: \"[href\"\n",
+		"rules: [",
+	}
+	for _, data := range invalid {
+		if _, err := r.Load([]byte(data), "invalid.yaml"); err == nil {
+			t.Errorf("无效规则应返回错误: %q", data)
+		}
+	}
+	if _, ok := r.Get("ok_rule"); ok {
+		t.Error("文件中有无效规则时不应注册其他规则")
+	}
+}
+
+func TestRegistryLoadDir(t *testing.T) {
+	r, err := DefaultRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	builtin := len(r.Names())
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"xueqiu.yaml":     "site: xueqiu\nrules:\n  - name: xueqiu_search\n    description: 本地规则\n    list: \".stock-list a\"\n",
+		"custom.YML":      "rules:\n  - name: custom_page\n",
+		"notes.txt":       "rules:\n  - name: ignored\n",
+		"backup.yaml.bak": "rules:\n  - name: ignored_backup\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub.yaml"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	n, err := r.LoadDir(dir)
+	if err != nil || n != 2 {
+		t.Fatalf("LoadDir = %d %v，期望 2 条规则", n, err)
+	}
+	// 同名规则覆盖内置规则，新规则追加
+	if rule, _ := r.Get("xueqiu_search"); rule.Description != "本地规则" || len(rule.List) != 1 || rule.List[0] != ".stock-list a" {
+		t.Errorf("覆盖后 = %+v", rule)
+	}
+	if got := len(r.Names()); got != builtin+1 {
+		t.Errorf("规则数 %d，期望 %d", got, builtin+1)
+	}
+	for _, name := range []string{"ignored", "ignored_backup"} {
+		if _, ok := r.Get(name); ok {
+			t.Errorf("%s 不是规则文件，不应加载", name)
+		}
+	}
+
+	// 目录不存在时不报错
+	if n, err := r.LoadDir(filepath.Join(dir, "missing")); n != 0 || err != nil {
+		t.Errorf("目录不存在时 LoadDir = %d %v", n, err)
+	}
+	bad := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bad, "bad.yaml"), []byte("rules:\n  - list: a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.LoadDir(bad); err == nil {
+		t.Error("目录中有无效规则时应返回错误")
+	}
+}
+
+func TestSelectorsYAML(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{`selector: "td a"`, []string{"td a"}},
+		{`selector: ["h1", ".title"]`, []string{"h1", ".title"}},
+		{"selector:\n  - h1\n  - .title\n", []string{"h1", ".title"}},
+		{`attr: href`, nil},
+	}
+	for _, tt := range tests {
+		var f Field
+		if err := yaml.Unmarshal([]byte(tt.in), &f); err != nil {
+			t.Fatalf("%q: %v", tt.in, err)
+		}
+		if strings.Join(f.Selector, "|") != strings.Join(tt.want, "|") || len(f.Selector) != len(tt.want) {
+			t.Errorf("%q 解析为 %q，期望 %q", tt.in, f.Selector, tt.want)
+		}
+	}
+	var f Field
+	if err := yaml.Unmarshal([]byte("selector: {a: 1}"), &f); err == nil {
+		t.Error("选择器为映射时应返回错误")
+	}
+}
+
+// TestDefaultRules 在保存的页面上运行每条内置规则，站点改版后更新规则时同时更新 testdata 中的页面
+func TestDefaultRules(t *testing.T) {
+	r, err := DefaultRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := time.Date(2025, 4, 30, 10, 0, 0, 0, newstime.Shanghai)
+	for _, name := range r.Names() {
+		t.Run(name, func(t *testing.T) {
+			rule, _ := r.Get(name)
+			f, err := os.Open(filepath.Join("testdata", name+".html"))
+			if err != nil {
+				t.Fatalf("缺少规则的样例页面: %v", err)
+			}
+			defer f.Close()
+			doc, err := goquery.NewDocumentFromReader(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			report := rule.Validate(doc, ref)
+			if !report.OK() {
+				t.Fatalf("校验未通过:\n%s", report)
+			}
+			if _, ok := rule.Fields["time"]; ok && report.TimeParsed != report.Records {
+				t.Errorf("时间解析成功 %d/%d", report.TimeParsed, report.Records)
+			}
+		})
+	}
+}
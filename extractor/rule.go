@@ -0,0 +1,286 @@
+// Package extractor 按站点的声明式规则（YAML）从页面中提取列表和字段
+// 站点改版时只需修改规则文件，无需重新编译
+package extractor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"stock_agent/newstime"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+	"gopkg.in/yaml.v3"
+)
+
+// RuleSet 单个站点的规则文件
+type RuleSet struct {
+	Site  string  `yaml:"site"` // 站点对应的数据源，如 cls、xueqiu、guba，作为规则的默认 source
+	Rules []*Rule `yaml:"rules"`
+}
+
+// Rule 单个页面的提取规则
+type Rule struct {
+	Name        string           `yaml:"name"`        // 规则名，代码按名称引用，如 xueqiu_search
+	Description string           `yaml:"description"` // 说明
+	Source      string           `yaml:"source"`      // 数据源，决定抓取方式，默认为规则文件的 site
+	URL         string           `yaml:"url"`         // 页面地址模板，{name} 由调用方替换，如 {base}/k?q={keyword}
+	Wait        Wait             `yaml:"wait"`        // 页面加载完成的条件
+	List        Selectors        `yaml:"list"`        // 列表项选择器，依次尝试，第一个有结果的生效；为空时整页作为一项
+	Fields      map[string]Field `yaml:"fields"`      // 字段，选择器相对于列表项
+	DateFormat  []string         `yaml:"date_format"` // time 字段的时间格式（Go layout），为空或不匹配时自动识别"5分钟前"等写法
+	Pagination  Pagination       `yaml:"pagination"`  // 翻页方式
+}
+
+// Wait 页面加载完成的条件，仅对浏览器抓取有效
+type Wait struct {
+	Selector string        `yaml:"selector"` // 等待该元素出现
+	Stable   bool          `yaml:"stable"`   // 等待页面DOM稳定
+	Timeout  time.Duration `yaml:"timeout"`  // 单页超时，如 60s
+}
+
+// Pagination 翻页方式，二者选一
+type Pagination struct {
+	URL      string `yaml:"url"`       // 第2页起的地址模板，{page} 为页码，其余占位符同 Rule.URL
+	LoadMore string `yaml:"load_more"` // 点击文字为该值的按钮加载更多（浏览器抓取）
+	PageSize int    `yaml:"page_size"` // 每页条数，用于计算需要翻几页
+	MaxPages int    `yaml:"max_pages"` // 最多翻页数，0 表示不限制
+}
+
+// Field 字段提取方式
+type Field struct {
+	Selector  Selectors `yaml:"selector"`   // 依次尝试的选择器，为空时取列表项自身
+	Attr      string    `yaml:"attr"`       // 取属性值，为空时取文本
+	Pattern   string    `yaml:"pattern"`    // 正则，有分组时取第一个分组，否则取整个匹配
+	Multiline bool      `yaml:"multiline"`  // 保留换行（每个文本节点一行），用于正文
+	MinLength int       `yaml:"min_length"` // 结果少于该字符数时视为未命中，继续尝试下一个选择器
+	Required  bool      `yaml:"required"`   // 必填，缺失时丢弃该列表项
+
+	pattern *regexp.Regexp
+}
+
+// Selectors 选择器列表，YAML 中可写为单个字符串或列表
+type Selectors []string
+
+// UnmarshalYAML 兼容单个字符串和字符串列表
+func (s *Selectors) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*s = Selectors{value.Value}
+		return nil
+	}
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// Record 提取出的一项
+type Record struct {
+	Fields map[string]string
+	Time   time.Time // time 字段的解析结果，没有或无法解析时为零值
+}
+
+// compile 校验规则并编译正则
+func (r *Rule) compile() error {
+	if r.Name == "" {
+		return fmt.Errorf("规则缺少 name")
+	}
+	for name, f := range r.Fields {
+		if f.Pattern != "" {
+			re, err := regexp.Compile(f.Pattern)
+			if err != nil {
+				return fmt.Errorf("规则 %s 字段 %s 的 pattern 无效: %v", r.Name, name, err)
+			}
+			f.pattern = re
+		}
+		for _, selector := range f.Selector {
+			if err := checkSelector(selector); err != nil {
+				return fmt.Errorf("规则 %s 字段 %s: %v", r.Name, name, err)
+			}
+		}
+		r.Fields[name] = f
+	}
+	for _, selector := range append(append(Selectors{}, r.List...), r.Wait.Selector) {
+		if err := checkSelector(selector); err != nil {
+			return fmt.Errorf("规则 %s: %v", r.Name, err)
+		}
+	}
+	return nil
+}
+
+// checkSelector 检查CSS选择器语法
+func checkSelector(selector string) error {
+	if selector == "" {
+		return nil
+	}
+	if _, err := cascadia.Compile(selector); err != nil {
+		return fmt.Errorf("选择器 %q 无效: %v", selector, err)
+	}
+	return nil
+}
+
+// PageURL 返回第 page 页的地址（从1开始），vars 替换模板中的 {name}，调用方负责转义
+// 第2页起使用 pagination.url，未配置时返回空字符串
+func (r *Rule) PageURL(vars map[string]string, page int) string {
+	template := r.URL
+	if page > 1 {
+		template = r.Pagination.URL
+		if template == "" {
+			return ""
+		}
+	}
+	pairs := []string{"{page}", fmt.Sprint(page)}
+	for k, v := range vars {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// Items 返回列表项和命中的选择器；没有配置列表时整页作为一项
+func (r *Rule) Items(doc *goquery.Document) (*goquery.Selection, string) {
+	if len(r.List) == 0 {
+		return doc.Selection, ""
+	}
+	for _, selector := range r.List {
+		if items := doc.Find(selector); items.Length() > 0 {
+			return items, selector
+		}
+	}
+	return doc.Find(r.List[0]), ""
+}
+
+// Field 提取列表项中的字段，返回值和命中的选择器；规则中没有该字段或未命中时返回空
+func (r *Rule) Field(item *goquery.Selection, name string) (string, string) {
+	f, ok := r.Fields[name]
+	if !ok {
+		return "", ""
+	}
+	if len(f.Selector) == 0 {
+		return f.value(item), ""
+	}
+	for _, selector := range f.Selector {
+		sel := item.Find(selector)
+		if sel.Length() == 0 {
+			continue
+		}
+		if v := f.value(sel.First()); v != "" {
+			return v, selector
+		}
+	}
+	return "", ""
+}
+
+// Selection 返回字段第一个命中的元素，用于需要自行处理元素的字段（如正文）
+func (r *Rule) Selection(item *goquery.Selection, name string) *goquery.Selection {
+	_, selector := r.Field(item, name)
+	if selector == "" {
+		if f, ok := r.Fields[name]; ok && len(f.Selector) == 0 {
+			return item
+		}
+		return nil
+	}
+	return item.Find(selector).First()
+}
+
+// value 取元素的属性或文本，并按 pattern 和 min_length 过滤
+func (f Field) value(sel *goquery.Selection) string {
+	var v string
+	if f.Attr != "" {
+		v = strings.TrimSpace(sel.AttrOr(f.Attr, ""))
+	} else {
+		v = text(sel, f.Multiline)
+	}
+	if f.pattern != nil {
+		m := f.pattern.FindStringSubmatch(v)
+		switch {
+		case m == nil:
+			v = ""
+		case len(m) > 1:
+			v = strings.TrimSpace(m[1])
+		default:
+			v = strings.TrimSpace(m[0])
+		}
+	}
+	if len([]rune(v)) < f.MinLength {
+		return ""
+	}
+	return v
+}
+
+// ParseTime 按 date_format 解析时间，不匹配时自动识别，仍失败时在文本中查找时间
+func (r *Rule) ParseTime(value string, ref time.Time) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range r.DateFormat {
+		// 不含年份的格式解析结果为0年，交给自动识别按抓取时间推断年份
+		if t, err := time.ParseInLocation(layout, value, newstime.Shanghai); err == nil && t.Year() > 0 {
+			return t, true
+		}
+	}
+	if t, err := newstime.Parse(value, ref); err == nil {
+		return t, true
+	}
+	return newstime.Find(value, ref)
+}
+
+// Extract 按规则提取全部列表项，缺少必填字段的项被丢弃
+func (r *Rule) Extract(doc *goquery.Document, ref time.Time) []Record {
+	items, _ := r.Items(doc)
+	var records []Record
+	for _, item := range items.EachIter() {
+		rec, ok := r.extractItem(item, ref)
+		if ok {
+			records = append(records, rec)
+		}
+	}
+	return records
+}
+
+// extractItem 提取单个列表项的全部字段
+func (r *Rule) extractItem(item *goquery.Selection, ref time.Time) (Record, bool) {
+	rec := Record{Fields: make(map[string]string, len(r.Fields))}
+	for name, f := range r.Fields {
+		v, _ := r.Field(item, name)
+		if v == "" && f.Required {
+			return Record{}, false
+		}
+		rec.Fields[name] = v
+	}
+	if v := rec.Fields["time"]; v != "" {
+		rec.Time, _ = r.ParseTime(v, ref)
+	}
+	return rec, true
+}
+
+// text 返回元素内的文本（跳过脚本和样式）：多行时每个文本节点一行，否则合并空白为一行
+func text(sel *goquery.Selection, multiline bool) string {
+	if !multiline {
+		return strings.Join(strings.Fields(sel.Text()), " ")
+	}
+	var lines []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "script", "style", "noscript":
+				return
+			}
+		}
+		if n.Type == html.TextNode {
+			if t := strings.TrimSpace(n.Data); t != "" {
+				lines = append(lines, t)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for _, n := range sel.Nodes {
+		walk(n)
+	}
+	return strings.Join(lines, "\n")
+}
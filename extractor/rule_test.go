@@ -0,0 +1,147 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"stock_agent/newstime"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// compiled 编译规则，失败时终止测试
+func compiled(t *testing.T, r *Rule) *Rule {
+	t.Helper()
+	if err := r.compile(); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func parseDoc(t *testing.T, page string) *goquery.Document {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestPageURL(t *testing.T) {
+	rule := &Rule{URL: "{base}/list,{code}.html", Pagination: Pagination{URL: "{base}/list,{code}_{page}.html"}}
+	vars := map[string]string{"base": "https://guba.eastmoney.com", "code": "601288"}
+	if got := rule.PageURL(vars, 1); got != "https://guba.eastmoney.com/list,601288.html" {
+		t.Errorf("第1页 = %q", got)
+	}
+	if got := rule.PageURL(vars, 3); got != "https://guba.eastmoney.com/list,601288_3.html" {
+		t.Errorf("第3页 = %q", got)
+	}
+	// 未知占位符保持原样
+	if got := (&Rule{URL: "{base}/k?q={keyword}"}).PageURL(vars, 1); got != "https://guba.eastmoney.com/k?q={keyword}" {
+		t.Errorf("未提供的占位符 = %q", got)
+	}
+	// 没有配置翻页地址时第2页起为空
+	if got := (&Rule{URL: "{base}/k"}).PageURL(vars, 2); got != "" {
+		t.Errorf("没有翻页地址时 = %q，期望为空", got)
+	}
+}
+
+func TestField(t *testing.T) {
+	rule := compiled(t, &Rule{Name: "test", Fields: map[string]Field{
+		// 第一个选择器的文本太短，继续尝试下一个
+		"content": {Selector: Selectors{".summary", ".body"}, MinLength: 10},
+		"code":    {Selector: Selectors{"a"}, Attr: "href", Pattern: `/S/([A-Z]{2}\d{6})`},
+		"price":   {Selector: Selectors{".price"}, Pattern: `\d+\.\d+`},
+		"missing": {Selector: Selectors{".none"}},
+		"self":    {Attr: "data-id"},
+	}})
+	doc := parseDoc(t, `<div class="item" data-id="42">
+		<p class="summary">短讯</p>
+		<div class="body">农业银行一季度净利润同比增长2.2%</div>
+		<a href="https://xueqiu.com/S/SH601288">农业银行</a>
+		<span class="price">现价 4.71 元</span>
+	</div>`)
+	item := doc.Find(".item")
+
+	tests := []struct {
+		name, value, selector string
+	}{
+		{"content", "农业银行一季度净利润同比增长2.2%", ".body"},
+		{"code", "SH601288", "a"},
+		{"price", "4.71", ".price"},
+		{"missing", "", ""},
+		{"self", "42", ""},
+		{"unknown", "", ""},
+	}
+	for _, tt := range tests {
+		if v, selector := rule.Field(item, tt.name); v != tt.value || selector != tt.selector {
+			t.Errorf("Field(%s) = %q %q，期望 %q %q", tt.name, v, selector, tt.value, tt.selector)
+		}
+	}
+	if sel := rule.Selection(item, "content"); sel == nil || !sel.HasClass("body") {
+		t.Error("Selection(content) 应返回 .body")
+	}
+	if sel := rule.Selection(item, "self"); sel == nil || sel.AttrOr("data-id", "") != "42" {
+		t.Error("没有选择器的字段 Selection 应返回列表项自身")
+	}
+	if sel := rule.Selection(item, "missing"); sel != nil {
+		t.Error("未命中的字段 Selection 应返回 nil")
+	}
+
+	// pattern 不匹配时视为未命中
+	nomatch := compiled(t, &Rule{Name: "nomatch", Fields: map[string]Field{"code": {Selector: Selectors{"a"}, Attr: "href", Pattern: `/S/(\d{5})`}}})
+	if v, _ := nomatch.Field(item, "code"); v != "" {
+		t.Errorf("pattern 不匹配时 = %q，期望为空", v)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	rule := compiled(t, &Rule{
+		Name: "guba_list",
+		Wait: Wait{Selector: ".listbody"},
+		List: Selectors{".articleh", "tr.listitem"},
+		Fields: map[string]Field{
+			"title": {Selector: Selectors{".title a"}, Required: true},
+			"reads": {Selector: Selectors{".read"}},
+			"time":  {Selector: Selectors{".update"}},
+		},
+	})
+	doc := parseDoc(t, `<table><tbody class="listbody">
+		<tr class="listitem"><td class="read">120</td><td class="title"><a>农行分红到账</a></td><td class="update">04-29 20:15</td></tr>
+		<tr class="listitem"><td class="read">35</td><td class="title"><a>明天还能涨吗</a></td><td class="update">置顶</td></tr>
+		<tr class="listitem"><td class="read">8</td><td class="title"></td></tr>
+	</tbody></table>`)
+	ref := time.Date(2025, 4, 30, 10, 0, 0, 0, newstime.Shanghai)
+	records, report := rule.ExtractReport(doc, ref)
+
+	// 第一个列表选择器未命中，使用备用选择器；缺少必填字段的项丢弃
+	if !report.WaitFound || report.ListSelector != "tr.listitem" || report.Items != 3 || report.Records != 2 || len(records) != 2 {
+		t.Fatalf("报告 = %+v", report)
+	}
+	matched := map[string]int{}
+	for _, f := range report.Fields {
+		matched[f.Name] = f.Matched
+	}
+	if matched["title"] != 2 || matched["reads"] != 3 || matched["time"] != 2 {
+		t.Errorf("字段命中 = %v", matched)
+	}
+	if report.TimeParsed != 1 || !records[0].Time.Equal(time.Date(2025, 4, 29, 20, 15, 0, 0, newstime.Shanghai)) {
+		t.Errorf("时间解析 %d 项，第一项 %v", report.TimeParsed, records[0].Time)
+	}
+	if !report.OK() || !strings.Contains(report.String(), "结果: 通过") {
+		t.Errorf("校验应通过:\n%s", report)
+	}
+
+	// 必填字段全部未命中或没有列表项时不通过
+	empty := parseDoc(t, `<table><tbody class="listbody"><tr class="listitem"><td class="read">8</td></tr></tbody></table>`)
+	if report := rule.Validate(empty, ref); report.OK() || !strings.Contains(report.String(), "未通过") {
+		t.Errorf("必填字段未命中时应不通过:\n%s", report)
+	}
+	if report := rule.Validate(parseDoc(t, `<p>暂无数据</p>`), ref); report.OK() || report.WaitFound || report.ListSelector != "" {
+		t.Errorf("页面改版时应不通过: %+v", report)
+	}
+	if (Report{Records: 1, Fields: []FieldReport{{Name: "url", Required: true}}}).OK() {
+		t.Error("必填字段没有命中时 OK 应为 false")
+	}
+}
@@ -0,0 +1,170 @@
+package extractor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// sampleCount 校验报告中展示的样例条数
+const sampleCount = 3
+
+// Report 规则在页面上的校验结果
+type Report struct {
+	Rule         string
+	WaitSelector string        // 等待条件中的选择器
+	WaitFound    bool          // 等待的元素是否存在
+	ListSelector string        // 命中的列表选择器，没有配置列表时为空
+	Items        int           // 列表项数量
+	Records      int           // 必填字段齐全的列表项数量
+	Fields       []FieldReport // 按字段名排序
+	TimeParsed   int           // time 字段能解析的项数
+	Samples      []Record      // 前几项的提取结果
+}
+
+// FieldReport 单个字段的命中情况
+type FieldReport struct {
+	Name      string
+	Required  bool
+	Matched   int            // 提取到值的列表项数量
+	Selectors map[string]int // 各选择器命中的项数，选择器为空表示取列表项自身
+}
+
+// Validate 在页面上运行规则，统计列表和各字段的命中情况
+func (r *Rule) Validate(doc *goquery.Document, ref time.Time) Report {
+	_, report := r.ExtractReport(doc, ref)
+	return report
+}
+
+// ExtractReport 提取全部列表项，同时统计列表和各字段的命中情况
+func (r *Rule) ExtractReport(doc *goquery.Document, ref time.Time) ([]Record, Report) {
+	report := Report{Rule: r.Name, WaitSelector: r.Wait.Selector}
+	if r.Wait.Selector != "" {
+		report.WaitFound = doc.Find(r.Wait.Selector).Length() > 0
+	}
+	items, selector := r.Items(doc)
+	report.ListSelector = selector
+	report.Items = items.Length()
+
+	fields := make(map[string]*FieldReport, len(r.Fields))
+	for name, f := range r.Fields {
+		fields[name] = &FieldReport{Name: name, Required: f.Required, Selectors: make(map[string]int)}
+	}
+	var records []Record
+	for _, item := range items.EachIter() {
+		for name := range r.Fields {
+			if v, selector := r.Field(item, name); v != "" {
+				fields[name].Matched++
+				fields[name].Selectors[selector]++
+			}
+		}
+		rec, ok := r.extractItem(item, ref)
+		if !ok {
+			continue
+		}
+		records = append(records, rec)
+		if !rec.Time.IsZero() {
+			report.TimeParsed++
+		}
+		if len(report.Samples) < sampleCount {
+			report.Samples = append(report.Samples, rec)
+		}
+	}
+	report.Records = len(records)
+	for _, f := range fields {
+		report.Fields = append(report.Fields, *f)
+	}
+	sort.Slice(report.Fields, func(i, j int) bool { return report.Fields[i].Name < report.Fields[j].Name })
+	return records, report
+}
+
+// OK 列表有结果且每个必填字段都有命中
+func (rep Report) OK() bool {
+	if rep.Records == 0 {
+		return false
+	}
+	for _, f := range rep.Fields {
+		if f.Required && f.Matched == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// String 输出可读的校验报告
+func (rep Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "规则: %s\n", rep.Rule)
+	if rep.WaitSelector != "" {
+		fmt.Fprintf(&b, "等待元素 %s: %s\n", rep.WaitSelector, foundLabel(rep.WaitFound))
+	}
+	if rep.ListSelector != "" || rep.Items != 1 {
+		list := rep.ListSelector
+		if list == "" {
+			list = "未命中"
+		}
+		fmt.Fprintf(&b, "列表: %s，共 %d 项，有效 %d 项\n", list, rep.Items, rep.Records)
+	}
+	for _, f := range rep.Fields {
+		required := ""
+		if f.Required {
+			required = "（必填）"
+		}
+		fmt.Fprintf(&b, "字段 %s%s: %d/%d", f.Name, required, f.Matched, rep.Items)
+		if len(f.Selectors) > 0 {
+			var hits []string
+			for selector, n := range f.Selectors {
+				hits = append(hits, fmt.Sprintf("%s ×%d", selectorLabel(selector), n))
+			}
+			sort.Strings(hits)
+			fmt.Fprintf(&b, "，命中 %s", strings.Join(hits, "；"))
+		}
+		b.WriteString("\n")
+	}
+	if rep.TimeParsed > 0 {
+		fmt.Fprintf(&b, "时间解析成功: %d/%d\n", rep.TimeParsed, rep.Records)
+	}
+	for i, rec := range rep.Samples {
+		fmt.Fprintf(&b, "样例 %d:\n", i+1)
+		names := make([]string, 0, len(rec.Fields))
+		for name := range rec.Fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			v := []rune(strings.ReplaceAll(rec.Fields[name], "\n", " "))
+			if len(v) > 80 {
+				v = append(v[:80], []rune("...")...)
+			}
+			fmt.Fprintf(&b, "  %s: %s\n", name, string(v))
+		}
+		if !rec.Time.IsZero() {
+			fmt.Fprintf(&b, "  (解析时间): %s\n", rec.Time.Format("2006-01-02 15:04:05"))
+		}
+	}
+	if rep.OK() {
+		b.WriteString("结果: 通过\n")
+	} else {
+		b.WriteString("结果: 未通过，列表或必填字段未命中\n")
+	}
+	return b.String()
+}
+
+// foundLabel 元素是否存在的描述
+func foundLabel(ok bool) string {
+	if ok {
+		return "存在"
+	}
+	return "未找到"
+}
+
+// selectorLabel 选择器的描述，空选择器表示列表项自身
+func selectorLabel(s string) string {
+	if s == "" {
+		return "（自身）"
+	}
+	return s
+}
@@ -0,0 +1,78 @@
+package financial
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Cache 财务数据的本地文件缓存，每个证券一个JSON文件
+// 财务数据按季度披露，缓存有效期内不再请求数据源；数据源不可用时可退回过期缓存
+type Cache struct {
+	Dir string
+	TTL time.Duration
+}
+
+// CacheEntry 缓存内容
+type CacheEntry struct {
+	Symbol    string    `json:"symbol"`
+	Source    string    `json:"source"`
+	FetchedAt time.Time `json:"fetchedAt"`
+	Quarters  int       `json:"quarters"` // 请求的报告期数量，上市时间短的公司实际返回可能更少
+	Reports   []Report  `json:"reports"`
+}
+
+// Fresh 缓存是否仍在有效期内
+func (e *CacheEntry) Fresh(ttl time.Duration) bool {
+	return time.Since(e.FetchedAt) < ttl
+}
+
+// Load 读取缓存，不存在时返回 nil
+func (c *Cache) Load(symbol string) (*CacheEntry, error) {
+	data, err := os.ReadFile(c.path(symbol))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取财务缓存失败: %v", err)
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("解析财务缓存失败: %v", err)
+	}
+	return &entry, nil
+}
+
+// Store 写入缓存，先写临时文件再重命名，避免并发读到半个文件
+func (c *Cache) Store(entry *CacheEntry) error {
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return fmt.Errorf("创建财务缓存目录失败: %v", err)
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.Dir, entry.Symbol+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("写入财务缓存失败: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入财务缓存失败: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入财务缓存失败: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(entry.Symbol)); err != nil {
+		return fmt.Errorf("写入财务缓存失败: %v", err)
+	}
+	return nil
+}
+
+func (c *Cache) path(symbol string) string {
+	return filepath.Join(c.Dir, strings.ToUpper(symbol)+".json")
+}
@@ -0,0 +1,40 @@
+package financial
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	c := &Cache{Dir: t.TempDir(), TTL: 24 * time.Hour}
+	if entry, err := c.Load("SH601288"); entry != nil || err != nil {
+		t.Fatalf("缓存不存在时 Load = %v %v，期望 nil", entry, err)
+	}
+
+	fetchedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	entry := &CacheEntry{Symbol: "SH601288", Source: "eastmoney", FetchedAt: fetchedAt, Quarters: 13, Reports: []Report{
+		{Date: "2024-12-31", Revenue: Float(7.1e11), NetProfit: Float(2.8e11), ROE: Float(10.5)},
+		{Date: "2024-09-30", Revenue: Float(5.4e11)},
+	}}
+	if err := c.Store(entry); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := c.Load("sh601288")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.FetchedAt.Equal(fetchedAt) {
+		t.Errorf("FetchedAt = %v，期望 %v", loaded.FetchedAt, fetchedAt)
+	}
+	loaded.FetchedAt = entry.FetchedAt
+	if !reflect.DeepEqual(loaded, entry) {
+		t.Errorf("Load = %+v，期望 %+v", loaded, entry)
+	}
+	if !loaded.Fresh(c.TTL) {
+		t.Error("一小时前的缓存在24h有效期内")
+	}
+	if loaded.Fresh(30 * time.Minute) {
+		t.Error("一小时前的缓存超过30分钟有效期")
+	}
+}
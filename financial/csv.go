@@ -0,0 +1,139 @@
+package financial
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// columnAliases CSV表头别名，兼容常见行情软件导出的中文表头
+var columnAliases = map[string][]string{
+	"date":              {"date", "report_date", "报告期", "报告日期"},
+	"revenue":           {"revenue", "营业总收入", "营业收入"},
+	"netprofit":         {"net_profit", "netprofit", "归母净利润", "归属于母公司股东的净利润", "净利润"},
+	"roe":               {"roe", "净资产收益率", "加权净资产收益率"},
+	"grossmargin":       {"gross_margin", "grossmargin", "毛利率", "销售毛利率"},
+	"debtratio":         {"debt_ratio", "debtratio", "资产负债率"},
+	"operatingcashflow": {"operating_cash_flow", "operatingcashflow", "经营活动现金流量净额", "经营现金流"},
+}
+
+// CSVProvider 从本地CSV文件读取财务数据，文件位于 Dir 下，命名为 代码.csv，如 SH601288.csv
+type CSVProvider struct {
+	Dir string
+}
+
+// Name 数据源名称
+func (p CSVProvider) Name() string {
+	return "csv"
+}
+
+// Reports 读取对应文件，返回最近 n 个报告期
+func (p CSVProvider) Reports(ctx context.Context, symbol string, n int) ([]Report, error) {
+	f, err := os.Open(filepath.Join(p.Dir, strings.ToUpper(symbol)+".csv"))
+	if os.IsNotExist(err) {
+		return nil, ErrNoData
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开财务文件失败: %v", err)
+	}
+	defer f.Close()
+	reports, err := ReadCSV(f)
+	if err != nil {
+		return nil, fmt.Errorf("读取财务文件 %s 失败: %v", f.Name(), err)
+	}
+	sort.SliceStable(reports, func(i, j int) bool { return reports[i].Date > reports[j].Date })
+	if n > 0 && len(reports) > n {
+		reports = reports[:n]
+	}
+	return reports, nil
+}
+
+// ReadCSV 从CSV读取财务数据
+// 表头为 date,revenue,net_profit,roe,gross_margin,debt_ratio,operating_cash_flow，除 date 外均可缺省，
+// 金额单位为元，比率单位为%，收入、利润和现金流为年初至报告期末的累计值
+func ReadCSV(r io.Reader) ([]Report, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("读取表头失败: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for column, aliases := range columnAliases {
+			if _, ok := columns[column]; ok {
+				continue
+			}
+			for _, alias := range aliases {
+				if name == alias {
+					columns[column] = i
+				}
+			}
+		}
+	}
+	if _, ok := columns["date"]; !ok {
+		return nil, fmt.Errorf("缺少列: date")
+	}
+
+	var reports []Report
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("第%d行: %v", line, err)
+		}
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		if field("date") == "" {
+			continue // 空行
+		}
+		date, err := parseReportDate(field("date"))
+		if err != nil {
+			return nil, fmt.Errorf("第%d行: %v", line, err)
+		}
+		report := Report{Date: date}
+		for name, v := range map[string]**float64{
+			"revenue": &report.Revenue, "netprofit": &report.NetProfit, "roe": &report.ROE,
+			"grossmargin": &report.GrossMargin, "debtratio": &report.DebtRatio, "operatingcashflow": &report.OperatingCashFlow,
+		} {
+			text := strings.TrimSuffix(strings.ReplaceAll(field(name), ",", ""), "%")
+			if text == "" || text == "-" || text == "--" {
+				continue
+			}
+			f, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("第%d行: %s 列不是数字: %s", line, name, text)
+			}
+			*v = Float(f)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// parseReportDate 解析报告期，统一为 2006-01-02 格式
+func parseReportDate(text string) (string, error) {
+	for _, layout := range []string{"2006-01-02", "2006/01/02", "20060102", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("无法解析报告期: %s", text)
+}
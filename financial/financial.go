@@ -0,0 +1,153 @@
+// Package financial 提供上市公司主要财务指标及同比、环比增速计算
+package financial
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Report 单个报告期的主要财务指标
+// 收入、利润和现金流为年初至报告期末的累计值（与财报披露口径一致），单季度值由 Compute 推算
+type Report struct {
+	Date              string   `json:"date"`                        // 报告期，如 2024-09-30
+	Revenue           *float64 `json:"revenue,omitempty"`           // 营业总收入（元）
+	NetProfit         *float64 `json:"netProfit,omitempty"`         // 归母净利润（元）
+	ROE               *float64 `json:"roe,omitempty"`               // 加权净资产收益率（%）
+	GrossMargin       *float64 `json:"grossMargin,omitempty"`       // 销售毛利率（%），银行等行业没有
+	DebtRatio         *float64 `json:"debtRatio,omitempty"`         // 资产负债率（%）
+	OperatingCashFlow *float64 `json:"operatingCashFlow,omitempty"` // 经营活动现金流量净额（元）
+
+	RevenueYoY          *float64 `json:"revenueYoY,omitempty"`          // 营业总收入同比（%）
+	NetProfitYoY        *float64 `json:"netProfitYoY,omitempty"`        // 归母净利润同比（%）
+	QuarterRevenue      *float64 `json:"quarterRevenue,omitempty"`      // 单季度营业总收入（元）
+	QuarterNetProfit    *float64 `json:"quarterNetProfit,omitempty"`    // 单季度归母净利润（元）
+	QuarterRevenueQoQ   *float64 `json:"quarterRevenueQoQ,omitempty"`   // 单季度营业总收入环比（%）
+	QuarterNetProfitQoQ *float64 `json:"quarterNetProfitQoQ,omitempty"` // 单季度归母净利润环比（%）
+}
+
+// ErrNoData 数据源没有该证券的财务数据
+var ErrNoData = errors.New("没有财务数据")
+
+// Provider 财务数据源
+type Provider interface {
+	// Name 数据源名称，如 eastmoney、csv
+	Name() string
+	// Reports 返回最近 n 个报告期的财务指标，按报告期降序，只需填充披露值
+	Reports(ctx context.Context, symbol string, n int) ([]Report, error)
+}
+
+// Chain 按顺序尝试多个数据源，返回第一个有数据的结果
+type Chain []Provider
+
+// Reports 依次查询各数据源，返回数据和实际使用的数据源名称
+func (c Chain) Reports(ctx context.Context, symbol string, n int) ([]Report, string, error) {
+	var errs []error
+	for _, p := range c {
+		reports, err := p.Reports(ctx, symbol, n)
+		if err == nil && len(reports) > 0 {
+			return reports, p.Name(), nil
+		}
+		if ctx.Err() != nil {
+			return nil, "", ctx.Err()
+		}
+		if err == nil {
+			err = ErrNoData
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+	}
+	if len(errs) == 0 {
+		return nil, "", fmt.Errorf("未配置财务数据源")
+	}
+	return nil, "", errors.Join(errs...)
+}
+
+// Compute 按报告期降序排列，并计算同比、单季度值和单季度环比
+// 同比与上年同期累计值比较，单季度值为本期累计减去上一季度累计（一季报即为单季度）
+func Compute(reports []Report) []Report {
+	sort.SliceStable(reports, func(i, j int) bool { return reports[i].Date > reports[j].Date })
+	byDate := make(map[string]*Report, len(reports))
+	for i := range reports {
+		byDate[reports[i].Date] = &reports[i]
+	}
+
+	for i := range reports {
+		r := &reports[i]
+		if prev, ok := byDate[shiftYear(r.Date, -1)]; ok {
+			r.RevenueYoY = growth(r.Revenue, prev.Revenue)
+			r.NetProfitYoY = growth(r.NetProfit, prev.NetProfit)
+		}
+		if isFirstQuarter(r.Date) {
+			r.QuarterRevenue, r.QuarterNetProfit = r.Revenue, r.NetProfit
+		} else if prev, ok := byDate[previousQuarter(r.Date)]; ok {
+			r.QuarterRevenue = diff(r.Revenue, prev.Revenue)
+			r.QuarterNetProfit = diff(r.NetProfit, prev.NetProfit)
+		}
+	}
+	// 单季度环比依赖上一季度的单季度值，需在单季度值全部算出后计算
+	for i := range reports {
+		r := &reports[i]
+		if prev, ok := byDate[previousQuarter(r.Date)]; ok {
+			r.QuarterRevenueQoQ = growth(r.QuarterRevenue, prev.QuarterRevenue)
+			r.QuarterNetProfitQoQ = growth(r.QuarterNetProfit, prev.QuarterNetProfit)
+		}
+	}
+	return reports
+}
+
+// Float 返回指向 v 的指针，便于填充可选字段
+func Float(v float64) *float64 {
+	return &v
+}
+
+// growth 增速（%），基数为负时按绝对值计算，基数为0或缺失时返回 nil
+func growth(cur, prev *float64) *float64 {
+	if cur == nil || prev == nil || *prev == 0 {
+		return nil
+	}
+	return Float(math.Round((*cur-*prev)/math.Abs(*prev)*1e4) / 1e2)
+}
+
+func diff(cur, prev *float64) *float64 {
+	if cur == nil || prev == nil {
+		return nil
+	}
+	return Float(*cur - *prev)
+}
+
+// quarterEnds 季度末的月日
+var quarterEnds = []string{"03-31", "06-30", "09-30", "12-31"}
+
+func isFirstQuarter(date string) bool {
+	return strings.HasSuffix(date, "-"+quarterEnds[0])
+}
+
+// previousQuarter 返回上一季度末日期，一季度返回上年年末
+func previousQuarter(date string) string {
+	if len(date) != 10 {
+		return ""
+	}
+	for i, end := range quarterEnds {
+		if date[5:] == end {
+			if i == 0 {
+				return shiftYear(date[:4]+"-"+quarterEnds[3], -1)
+			}
+			return date[:5] + quarterEnds[i-1]
+		}
+	}
+	return ""
+}
+
+func shiftYear(date string, years int) string {
+	if len(date) != 10 {
+		return ""
+	}
+	var year int
+	if _, err := fmt.Sscanf(date[:4], "%d", &year); err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%04d%s", year+years, date[4:])
+}
@@ -0,0 +1,98 @@
+package financial
+
+import (
+	"fmt"
+	"testing"
+)
+
+// fmtFloat 输出可选数值，nil 输出 -
+func fmtFloat(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", *v)
+}
+
+func TestCompute(t *testing.T) {
+	yi := func(v float64) *float64 { return Float(v * 1e8) }
+	// 累计值（亿元），缺少 2023-06-30 报告期，2024 年四季度起亏损
+	reports := Compute([]Report{
+		{Date: "2024-06-30", Revenue: yi(230), NetProfit: yi(20)},
+		{Date: "2023-03-31", Revenue: yi(100), NetProfit: yi(-8)},
+		{Date: "2025-03-31", Revenue: yi(121), NetProfit: yi(-5)},
+		{Date: "2023-09-30", Revenue: yi(300), NetProfit: yi(30)},
+		{Date: "2024-12-31", Revenue: yi(460), NetProfit: yi(-10)},
+		{Date: "2024-03-31", Revenue: yi(110), NetProfit: yi(12)},
+		{Date: "2023-12-31", Revenue: yi(420), NetProfit: yi(40)},
+		{Date: "2024-09-30", Revenue: yi(330), NetProfit: yi(33)},
+	})
+
+	// 日期、营收同比、净利同比、单季营收（亿）、单季净利（亿）、单季营收环比、单季净利环比
+	want := [][7]string{
+		// 一季度单季值即累计值，环比与上年四季度单季值比较；净利润同比的基数为负，按绝对值计算
+		{"2025-03-31", "10.00", "-141.67", "121.00", "-5.00", "-6.92", "88.37"},
+		{"2024-12-31", "9.52", "-125.00", "130.00", "-43.00", "30.00", "-430.77"},
+		{"2024-09-30", "10.00", "10.00", "100.00", "13.00", "-16.67", "62.50"},
+		// 缺少上年同期，没有同比
+		{"2024-06-30", "-", "-", "120.00", "8.00", "9.09", "-33.33"},
+		{"2024-03-31", "10.00", "250.00", "110.00", "12.00", "-8.33", "20.00"},
+		// 上一季度的单季值无法推算，没有环比
+		{"2023-12-31", "-", "-", "120.00", "10.00", "-", "-"},
+		// 缺少上一季度，无法推算单季值
+		{"2023-09-30", "-", "-", "-", "-", "-", "-"},
+		{"2023-03-31", "-", "-", "100.00", "-8.00", "-", "-"},
+	}
+	if len(reports) != len(want) {
+		t.Fatalf("报告期 %d 个，期望 %d 个", len(reports), len(want))
+	}
+	toYi := func(v *float64) *float64 {
+		if v == nil {
+			return nil
+		}
+		return Float(*v / 1e8)
+	}
+	for i, r := range reports {
+		got := [7]string{r.Date, fmtFloat(r.RevenueYoY), fmtFloat(r.NetProfitYoY), fmtFloat(toYi(r.QuarterRevenue)), fmtFloat(toYi(r.QuarterNetProfit)),
+			fmtFloat(r.QuarterRevenueQoQ), fmtFloat(r.QuarterNetProfitQoQ)}
+		if got != want[i] {
+			t.Errorf("第 %d 个报告期 = %v，期望 %v", i, got, want[i])
+		}
+	}
+}
+
+func TestComputeMissingValues(t *testing.T) {
+	// 银行等没有某项指标时，对应的增速和单季值都为空
+	reports := Compute([]Report{
+		{Date: "2024-06-30", Revenue: Float(230)},
+		{Date: "2024-03-31", Revenue: Float(110), NetProfit: Float(12)},
+		{Date: "2023-06-30", Revenue: Float(0), NetProfit: Float(18)},
+	})
+	r := reports[0]
+	if r.RevenueYoY != nil {
+		t.Errorf("上年同期营收为0时同比应为空，得到 %s", fmtFloat(r.RevenueYoY))
+	}
+	if r.NetProfitYoY != nil || r.QuarterNetProfit != nil || r.QuarterNetProfitQoQ != nil {
+		t.Errorf("缺少净利润时相关字段应为空")
+	}
+	if fmtFloat(r.QuarterRevenue) != "120.00" || fmtFloat(r.QuarterRevenueQoQ) != "9.09" {
+		t.Errorf("单季营收 = %s，环比 = %s", fmtFloat(r.QuarterRevenue), fmtFloat(r.QuarterRevenueQoQ))
+	}
+}
+
+func TestPreviousQuarter(t *testing.T) {
+	tests := []struct {
+		date, want string
+	}{
+		{"2024-03-31", "2023-12-31"},
+		{"2024-06-30", "2024-03-31"},
+		{"2024-09-30", "2024-06-30"},
+		{"2024-12-31", "2024-09-30"},
+		{"2024-05-31", ""},
+		{"2024", ""},
+	}
+	for _, tt := range tests {
+		if got := previousQuarter(tt.date); got != tt.want {
+			t.Errorf("previousQuarter(%q) = %q，期望 %q", tt.date, got, tt.want)
+		}
+	}
+}
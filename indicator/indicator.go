@@ -0,0 +1,120 @@
+// Package indicator 基于K线计算常用技术指标
+//
+// 所有函数返回与输入等长的序列，数据不足以计算的位置为 NaN，可用 Valid 判断。
+// 计算口径按通达信公式定义：EMA、SMA 以第一个值为初始值，STD 为样本标准差，K线足够长时与行情软件显示值一致。
+package indicator
+
+import (
+	"math"
+
+	"stock_agent/kline"
+)
+
+// Valid 判断指标值是否有效（非 NaN）
+func Valid(v float64) bool {
+	return !math.IsNaN(v)
+}
+
+// Last 返回序列最后一个值，序列为空时返回 NaN
+func Last(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	return values[len(values)-1]
+}
+
+// Closes 返回K线的收盘价序列
+func Closes(bars []kline.Bar) []float64 {
+	values := make([]float64, len(bars))
+	for i, b := range bars {
+		values[i] = b.Close
+	}
+	return values
+}
+
+// Volumes 返回K线的成交量序列
+func Volumes(bars []kline.Bar) []float64 {
+	values := make([]float64, len(bars))
+	for i, b := range bars {
+		values[i] = b.Volume
+	}
+	return values
+}
+
+// MA 简单移动平均，前 n-1 个位置为 NaN
+func MA(values []float64, n int) []float64 {
+	out := nanSlice(len(values))
+	if n <= 0 {
+		return out
+	}
+	sum := 0.0
+	for i, v := range values {
+		sum += v
+		if i >= n {
+			sum -= values[i-n]
+		}
+		if i >= n-1 {
+			out[i] = sum / float64(n)
+		}
+	}
+	return out
+}
+
+// EMA 指数移动平均，EMA = (2*X + (n-1)*EMA') / (n+1)，以第一个值为初始值
+func EMA(values []float64, n int) []float64 {
+	return smooth(values, n+1, 2)
+}
+
+// SMA 通达信口径的移动平均，SMA = (m*X + (n-m)*SMA') / n，以第一个有效值为初始值
+// 与 EMA 的区别在于权重为 m/n，RSI、KDJ 使用该口径
+func SMA(values []float64, n, m int) []float64 {
+	return smooth(values, n, m)
+}
+
+// smooth 计算 Y = (m*X + (n-m)*Y') / n，跳过开头的 NaN
+func smooth(values []float64, n, m int) []float64 {
+	out := nanSlice(len(values))
+	if n <= 0 || m <= 0 || m > n {
+		return out
+	}
+	prev := math.NaN()
+	for i, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		if math.IsNaN(prev) {
+			prev = v
+		} else {
+			prev = (float64(m)*v + float64(n-m)*prev) / float64(n)
+		}
+		out[i] = prev
+	}
+	return out
+}
+
+// StdDev 样本标准差（除以 n-1），与通达信 STD 函数（估算标准差）一致，前 n-1 个位置为 NaN
+// 通达信的总体标准差为 STDP，BOLL 公式使用的是 STD
+func StdDev(values []float64, n int) []float64 {
+	out := nanSlice(len(values))
+	if n <= 1 {
+		return out
+	}
+	mean := MA(values, n)
+	for i := n - 1; i < len(values); i++ {
+		sum := 0.0
+		for _, v := range values[i-n+1 : i+1] {
+			d := v - mean[i]
+			sum += d * d
+		}
+		out[i] = math.Sqrt(sum / float64(n-1))
+	}
+	return out
+}
+
+func nanSlice(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+
+	"stock_agent/kline"
+)
+
+// 参考值按通达信公式定义逐步计算：短序列可手算，30根K线的读数由独立实现的同一组公式得出
+// （EMA、SMA 以第一个值为初始值，STD 为样本标准差，KDJ 的 K、D 以50为初始值）
+
+const tolerance = 1e-6
+
+func near(a, b float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	return math.Abs(a-b) < tolerance
+}
+
+func checkSeries(t *testing.T, name string, got, want []float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: 长度 %d，期望 %d", name, len(got), len(want))
+	}
+	for i := range want {
+		if !near(got[i], want[i]) {
+			t.Errorf("%s[%d] = %v，期望 %v", name, i, got[i], want[i])
+		}
+	}
+}
+
+var nan = math.NaN()
+
+func TestMovingAverages(t *testing.T) {
+	tests := []struct {
+		name string
+		got  []float64
+		want []float64
+	}{
+		{"MA3", MA([]float64{1, 2, 3, 4, 5}, 3), []float64{nan, nan, 2, 3, 4}},
+		{"MA0", MA([]float64{1, 2}, 0), []float64{nan, nan}},
+		// EMA2: Y = (2X + Y') / 3
+		{"EMA2", EMA([]float64{1, 2, 3}, 2), []float64{1, 5.0 / 3, 23.0 / 9}},
+		// SMA(X,3,1): Y = (X + 2Y') / 3
+		{"SMA3_1", SMA([]float64{10, 20, 30}, 3, 1), []float64{10, 40.0 / 3, 170.0 / 9}},
+		{"SMA跳过NaN", SMA([]float64{nan, 10, 20}, 3, 1), []float64{nan, 10, 40.0 / 3}},
+		// 样本标准差：均值5，离差平方和32，32/7 开方
+		{"STD8", StdDev([]float64{2, 4, 4, 4, 5, 5, 7, 9}, 8), []float64{nan, nan, nan, nan, nan, nan, nan, math.Sqrt(32.0 / 7)}},
+	}
+	for _, tt := range tests {
+		checkSeries(t, tt.name, tt.got, tt.want)
+	}
+}
+
+func TestRSIShortSeries(t *testing.T) {
+	// 涨幅 1、0、1，波动 1、0.5、1；SMA(X,2,1) 分别为 1、0.5、0.75 和 1、0.75、0.875
+	got := RSI([]float64{10, 11, 10.5, 11.5}, 2)
+	checkSeries(t, "RSI2", got, []float64{nan, 100, 0.5 / 0.75 * 100, 0.75 / 0.875 * 100})
+
+	flat := RSI([]float64{10, 10, 10}, 6)
+	checkSeries(t, "RSI平盘", flat, []float64{nan, 50, 50})
+}
+
+func TestTR(t *testing.T) {
+	bars := []kline.Bar{
+		{High: 10.5, Low: 9.8, Close: 10},
+		{High: 10.2, Low: 9.9, Close: 10.1}, // 振幅0.3
+		{High: 11, Low: 10.6, Close: 10.9},  // 跳空高开：H-C' = 0.9
+		{High: 10.4, Low: 10, Close: 10.2},  // 跳空低开：|L-C'| = 0.9
+	}
+	checkSeries(t, "TR", TR(bars), []float64{0.7, 0.3, 0.9, 0.9})
+	checkSeries(t, "ATR2", ATR(bars, 2), []float64{nan, 0.5, 0.6, 0.9})
+}
+
+func TestVolumeRatio(t *testing.T) {
+	got := VolumeRatio([]float64{100, 200, 300, 600}, 2)
+	checkSeries(t, "量比", got, []float64{nan, nan, 2, 2.4})
+}
+
+// referenceBars 30根日K，最高价为收盘价加0.03，最低价为收盘价减0.04
+func referenceBars() []kline.Bar {
+	closes := []float64{
+		3.50, 3.52, 3.55, 3.53, 3.58, 3.62, 3.60, 3.65, 3.70, 3.68,
+		3.66, 3.71, 3.75, 3.73, 3.78, 3.82, 3.80, 3.77, 3.74, 3.79,
+		3.85, 3.88, 3.86, 3.90, 3.94, 3.91, 3.89, 3.95, 3.99, 4.02,
+	}
+	bars := make([]kline.Bar, len(closes))
+	for i, c := range closes {
+		bars[i] = kline.Bar{Open: c, High: c + 0.03, Low: c - 0.04, Close: c, Volume: 1e6}
+	}
+	return bars
+}
+
+func TestReferenceReadings(t *testing.T) {
+	bars := referenceBars()
+	closes := Closes(bars)
+	macd := MACD(closes, 12, 26, 9)
+	kdj := KDJ(bars, 9, 3, 3)
+	boll := BOLL(closes, 20, 2)
+
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"MA5", Last(MA(closes, 5)), 3.952},
+		{"EMA12", Last(EMA(closes, 12)), 3.902746697538468},
+		{"DIF", Last(macd.DIF), 0.09497067341354049},
+		{"DEA", Last(macd.DEA), 0.08484795249375829},
+		{"MACD", Last(macd.Hist), 0.020245441839564393},
+		{"RSI6", Last(RSI(closes, 6)), 80.06909000012512},
+		{"RSI12", Last(RSI(closes, 12)), 77.7275390384778},
+		{"K", Last(kdj.K), 83.94815106763163},
+		{"D", Last(kdj.D), 81.73117340527256},
+		{"J", Last(kdj.J), 88.38210639234978},
+		{"BOLL上轨", Last(boll.Upper), 4.032969922504234},
+		{"BOLL中轨", Last(boll.Mid), 3.837},
+		{"BOLL下轨", Last(boll.Lower), 3.641030077495765},
+		{"ATR14", Last(ATR(bars, 14)), 0.07357142857142847},
+	}
+	for _, tt := range tests {
+		if !near(tt.got, tt.want) {
+			t.Errorf("%s = %v，期望 %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if Valid(kdj.K[7]) || !Valid(kdj.K[8]) {
+		t.Errorf("KDJ9 应从第9根K线开始有值")
+	}
+}
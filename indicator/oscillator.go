@@ -0,0 +1,66 @@
+package indicator
+
+import (
+	"math"
+
+	"stock_agent/kline"
+)
+
+// RSI 相对强弱指标，RSI = SMA(MAX(C-C',0), n, 1) / SMA(ABS(C-C'), n, 1) * 100，常用参数为 6、12、24
+// 第一个位置没有前收盘价，为 NaN
+func RSI(closes []float64, n int) []float64 {
+	out := nanSlice(len(closes))
+	if len(closes) < 2 {
+		return out
+	}
+	gains := nanSlice(len(closes))
+	moves := nanSlice(len(closes))
+	for i := 1; i < len(closes); i++ {
+		d := closes[i] - closes[i-1]
+		gains[i] = math.Max(d, 0)
+		moves[i] = math.Abs(d)
+	}
+	up, all := SMA(gains, n, 1), SMA(moves, n, 1)
+	for i := 1; i < len(closes); i++ {
+		switch {
+		case all[i] > 0:
+			out[i] = up[i] / all[i] * 100
+		case Valid(all[i]):
+			out[i] = 50 // 价格没有变动
+		}
+	}
+	return out
+}
+
+// KDJResult KDJ 指标序列
+type KDJResult struct {
+	K []float64
+	D []float64
+	J []float64
+}
+
+// KDJ 随机指标，RSV = (C - LLV(L,n)) / (HHV(H,n) - LLV(L,n)) * 100，
+// K = SMA(RSV, m1, 1)，D = SMA(K, m2, 1)，J = 3K - 2D，常用参数为 9、3、3
+// K、D 以50为初始值，前 n-1 个位置为 NaN
+func KDJ(bars []kline.Bar, n, m1, m2 int) KDJResult {
+	k, d, j := nanSlice(len(bars)), nanSlice(len(bars)), nanSlice(len(bars))
+	if n <= 0 || m1 <= 0 || m2 <= 0 {
+		return KDJResult{K: k, D: d, J: j}
+	}
+	prevK, prevD := 50.0, 50.0
+	for i := n - 1; i < len(bars); i++ {
+		high, low := bars[i].High, bars[i].Low
+		for _, b := range bars[i-n+1 : i] {
+			high = math.Max(high, b.High)
+			low = math.Min(low, b.Low)
+		}
+		rsv := 50.0
+		if high > low {
+			rsv = (bars[i].Close - low) / (high - low) * 100
+		}
+		prevK = (rsv + float64(m1-1)*prevK) / float64(m1)
+		prevD = (prevK + float64(m2-1)*prevD) / float64(m2)
+		k[i], d[i], j[i] = prevK, prevD, 3*prevK-2*prevD
+	}
+	return KDJResult{K: k, D: d, J: j}
+}
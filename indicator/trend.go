@@ -0,0 +1,43 @@
+package indicator
+
+// MACDResult MACD 指标序列
+type MACDResult struct {
+	DIF  []float64 // 快线：EMA(short) - EMA(long)
+	DEA  []float64 // 慢线：EMA(DIF, signal)
+	Hist []float64 // 柱：2 * (DIF - DEA)
+}
+
+// MACD 平滑异同移动平均，常用参数为 12、26、9，柱值按国内习惯乘以2
+func MACD(closes []float64, short, long, signal int) MACDResult {
+	fast, slow := EMA(closes, short), EMA(closes, long)
+	dif := make([]float64, len(closes))
+	for i := range closes {
+		dif[i] = fast[i] - slow[i]
+	}
+	dea := EMA(dif, signal)
+	hist := make([]float64, len(closes))
+	for i := range closes {
+		hist[i] = 2 * (dif[i] - dea[i])
+	}
+	return MACDResult{DIF: dif, DEA: dea, Hist: hist}
+}
+
+// BOLLResult 布林带序列
+type BOLLResult struct {
+	Upper []float64
+	Mid   []float64
+	Lower []float64
+}
+
+// BOLL 布林带，中轨为 n 日均线，上下轨为中轨加减 k 倍标准差（STD，样本标准差），常用参数为 20、2
+func BOLL(closes []float64, n int, k float64) BOLLResult {
+	mid := MA(closes, n)
+	std := StdDev(closes, n)
+	upper := make([]float64, len(closes))
+	lower := make([]float64, len(closes))
+	for i := range closes {
+		upper[i] = mid[i] + k*std[i]
+		lower[i] = mid[i] - k*std[i]
+	}
+	return BOLLResult{Upper: upper, Mid: mid, Lower: lower}
+}
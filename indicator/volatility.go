@@ -0,0 +1,41 @@
+package indicator
+
+import (
+	"math"
+
+	"stock_agent/kline"
+)
+
+// TR 真实波幅，MAX(H-L, |H-C'|, |L-C'|)，第一根K线为 H-L
+func TR(bars []kline.Bar) []float64 {
+	out := make([]float64, len(bars))
+	for i, b := range bars {
+		out[i] = b.High - b.Low
+		if i > 0 {
+			prev := bars[i-1].Close
+			out[i] = math.Max(out[i], math.Max(math.Abs(b.High-prev), math.Abs(b.Low-prev)))
+		}
+	}
+	return out
+}
+
+// ATR 平均真实波幅，取真实波幅的 n 日简单平均（与通达信口径一致），常用参数为 14
+func ATR(bars []kline.Bar, n int) []float64 {
+	return MA(TR(bars), n)
+}
+
+// VolumeRatio 量比，当根成交量与之前 n 根平均成交量之比，常用参数为 5
+// 前 n 个位置以及之前平均成交量为0时为 NaN
+func VolumeRatio(volumes []float64, n int) []float64 {
+	out := nanSlice(len(volumes))
+	if n <= 0 {
+		return out
+	}
+	avg := MA(volumes, n)
+	for i := n; i < len(volumes); i++ {
+		if avg[i-1] > 0 {
+			out[i] = volumes[i] / avg[i-1]
+		}
+	}
+	return out
+}
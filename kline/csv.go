@@ -0,0 +1,142 @@
+package kline
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// columnAliases CSV表头别名，兼容常见行情软件导出的中文表头
+var columnAliases = map[string][]string{
+	"time":   {"time", "date", "datetime", "日期", "时间"},
+	"open":   {"open", "开盘", "开盘价"},
+	"high":   {"high", "最高", "最高价"},
+	"low":    {"low", "最低", "最低价"},
+	"close":  {"close", "收盘", "收盘价"},
+	"volume": {"volume", "vol", "成交量"},
+	"amount": {"amount", "turnover", "成交额"},
+}
+
+// timeLayouts K线时间支持的格式
+var timeLayouts = []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02", "2006/01/02 15:04", "2006/01/02", "2006/1/2", "20060102"}
+
+// CSVProvider 从本地CSV文件读取K线
+// 文件位于 Dir 下，命名为 代码_周期.csv，如 SH601288_day.csv；日线也可直接命名为 SH601288.csv
+type CSVProvider struct {
+	Dir string
+}
+
+// Name 数据源名称
+func (p CSVProvider) Name() string {
+	return "csv"
+}
+
+// Bars 读取对应文件并按查询条件截取
+func (p CSVProvider) Bars(ctx context.Context, q Query) ([]Bar, error) {
+	names := []string{fmt.Sprintf("%s_%s.csv", q.Symbol, q.Period)}
+	if q.Period == Day {
+		names = append(names, q.Symbol+".csv")
+	}
+	for _, name := range names {
+		f, err := os.Open(filepath.Join(p.Dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("打开K线文件失败: %v", err)
+		}
+		bars, err := ReadCSV(f, q.Period)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("读取K线文件 %s 失败: %v", name, err)
+		}
+		return Filter(bars, q), nil
+	}
+	return nil, ErrNoData
+}
+
+// ReadCSV 从CSV读取K线，按时间升序返回
+// 表头至少包含 time,open,high,low,close 列，volume、amount 列可选，列名见 columnAliases
+func ReadCSV(r io.Reader, period Period) ([]Bar, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("读取表头失败: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for column, aliases := range columnAliases {
+			if _, ok := columns[column]; ok {
+				continue
+			}
+			for _, alias := range aliases {
+				if name == alias {
+					columns[column] = i
+				}
+			}
+		}
+	}
+	for _, required := range []string{"time", "open", "high", "low", "close"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("缺少列: %s", required)
+		}
+	}
+
+	var bars []Bar
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("第%d行: %v", line, err)
+		}
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		if field("time") == "" {
+			continue // 空行
+		}
+		t, err := parseTime(field("time"))
+		if err != nil {
+			return nil, fmt.Errorf("第%d行: %v", line, err)
+		}
+		bar := Bar{Time: t.Format(period.TimeLayout())}
+		for name, v := range map[string]*float64{"open": &bar.Open, "high": &bar.High, "low": &bar.Low, "close": &bar.Close, "volume": &bar.Volume, "amount": &bar.Amount} {
+			text := strings.ReplaceAll(field(name), ",", "")
+			if text == "" {
+				continue
+			}
+			if *v, err = strconv.ParseFloat(text, 64); err != nil {
+				return nil, fmt.Errorf("第%d行: %s 列不是数字: %s", line, name, text)
+			}
+		}
+		bars = append(bars, bar)
+	}
+	sort.SliceStable(bars, func(i, j int) bool { return bars[i].Time < bars[j].Time })
+	return bars, nil
+}
+
+func parseTime(text string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法解析时间: %s", text)
+}
@@ -0,0 +1,153 @@
+package kline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Period K线周期
+type Period string
+
+const (
+	Day   Period = "day"
+	Week  Period = "week"
+	Month Period = "month"
+	Min1  Period = "1m"
+	Min5  Period = "5m"
+	Min15 Period = "15m"
+	Min30 Period = "30m"
+	Min60 Period = "60m"
+)
+
+// DateLayout 日期格式
+const DateLayout = "2006-01-02"
+
+// periodAliases 周期的常见写法，除 1M（月线，与1分钟的 1m 区分）外均为小写
+var periodAliases = map[string]Period{
+	"day": Day, "daily": Day, "d": Day, "1d": Day, "日": Day, "日线": Day, "日k": Day,
+	"week": Week, "weekly": Week, "w": Week, "1w": Week, "周": Week, "周线": Week, "周k": Week,
+	"month": Month, "monthly": Month, "1M": Month, "1mon": Month, "月": Month, "月线": Month, "月k": Month,
+	"1m": Min1, "1min": Min1, "1分钟": Min1,
+	"5m": Min5, "5min": Min5, "5分钟": Min5,
+	"15m": Min15, "15min": Min15, "15分钟": Min15,
+	"30m": Min30, "30min": Min30, "30分钟": Min30,
+	"60m": Min60, "60min": Min60, "1h": Min60, "60分钟": Min60,
+}
+
+// ParsePeriod 解析K线周期，为空时返回日线
+// 先按原写法匹配，1M 为月线、1m 为1分钟，其余写法不区分大小写
+func ParsePeriod(s string) (Period, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Day, nil
+	}
+	if p, ok := periodAliases[s]; ok {
+		return p, nil
+	}
+	if p, ok := periodAliases[strings.ToLower(s)]; ok {
+		return p, nil
+	}
+	return "", fmt.Errorf("不支持的K线周期: %s，可选 day、week、month、1m、5m、15m、30m、60m", s)
+}
+
+// Intraday 是否为分钟线
+func (p Period) Intraday() bool {
+	switch p {
+	case Min1, Min5, Min15, Min30, Min60:
+		return true
+	}
+	return false
+}
+
+// TimeLayout 返回该周期K线时间的格式，日线及以上只保留日期
+func (p Period) TimeLayout() string {
+	if p.Intraday() {
+		return "2006-01-02 15:04"
+	}
+	return DateLayout
+}
+
+// Bar 一根K线，价格为前复权价
+type Bar struct {
+	Time   string  `json:"time"` // 日线及以上为 2006-01-02，分钟线为 2006-01-02 15:04
+	Open   float64 `json:"open"`
+	High   float64 `json:"high"`
+	Low    float64 `json:"low"`
+	Close  float64 `json:"close"`
+	Volume float64 `json:"volume"`           // 成交量（股）
+	Amount float64 `json:"amount,omitempty"` // 成交额（元）
+}
+
+// Query K线查询条件
+type Query struct {
+	Symbol string    // 雪球格式代码，如 SH601288、00700、AAPL
+	Period Period    // K线周期
+	Start  time.Time // 开始日期（含），为零值时不限制
+	End    time.Time // 结束日期（含），为零值时截至最新
+	Count  int       // 最多返回的K线数量，取区间内最近的 Count 根，<=0 时不限制
+}
+
+// ErrNoData 数据源没有该证券或该周期的数据
+var ErrNoData = errors.New("没有K线数据")
+
+// Provider K线数据源
+type Provider interface {
+	// Name 数据源名称，如 eastmoney、xueqiu、csv
+	Name() string
+	// Bars 返回按时间升序排列的K线
+	Bars(ctx context.Context, q Query) ([]Bar, error)
+}
+
+// Chain 按顺序尝试多个数据源，返回第一个有数据的结果
+type Chain []Provider
+
+// Bars 依次查询各数据源，返回数据和实际使用的数据源名称
+func (c Chain) Bars(ctx context.Context, q Query) ([]Bar, string, error) {
+	var errs []error
+	for _, p := range c {
+		bars, err := p.Bars(ctx, q)
+		if err == nil && len(bars) > 0 {
+			return bars, p.Name(), nil
+		}
+		if ctx.Err() != nil {
+			return nil, "", ctx.Err()
+		}
+		if err == nil {
+			err = ErrNoData
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+	}
+	if len(errs) == 0 {
+		return nil, "", fmt.Errorf("未配置K线数据源")
+	}
+	return nil, "", errors.Join(errs...)
+}
+
+// Filter 按查询条件截取K线：保留 [Start, End] 区间内的K线，再取最近的 Count 根
+func Filter(bars []Bar, q Query) []Bar {
+	start, end := "", ""
+	if !q.Start.IsZero() {
+		start = q.Start.Format(DateLayout)
+	}
+	if !q.End.IsZero() {
+		end = q.End.Format(DateLayout)
+	}
+	out := make([]Bar, 0, len(bars))
+	for _, b := range bars {
+		// 时间格式以日期开头，可直接按字符串比较
+		if start != "" && b.Time < start {
+			continue
+		}
+		if end != "" && len(b.Time) >= len(end) && b.Time[:len(end)] > end {
+			continue
+		}
+		out = append(out, b)
+	}
+	if q.Count > 0 && len(out) > q.Count {
+		out = out[len(out)-q.Count:]
+	}
+	return out
+}
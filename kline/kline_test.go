@@ -0,0 +1,119 @@
+package kline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePeriod(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Period
+	}{
+		{"", Day},
+		{" Daily ", Day},
+		{"日K", Day},
+		{"W", Week},
+		{"month", Month},
+		// 1M 为月线，1m 为1分钟
+		{"1M", Month},
+		{"1m", Min1},
+		{"1MIN", Min1},
+		{"5M", Min5},
+		{"1H", Min60},
+		{"30分钟", Min30},
+	}
+	for _, tt := range tests {
+		if got, err := ParsePeriod(tt.in); err != nil || got != tt.want {
+			t.Errorf("ParsePeriod(%q) = %q %v，期望 %q", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"2h", "M", "季"} {
+		if _, err := ParsePeriod(in); err == nil {
+			t.Errorf("ParsePeriod(%q) 应返回错误", in)
+		}
+	}
+}
+
+// stubProvider 返回固定K线或错误，并记录请求次数
+type stubProvider struct {
+	name  string
+	bars  []Bar
+	err   error
+	calls int
+}
+
+func (p *stubProvider) Name() string { return p.name }
+
+func (p *stubProvider) Bars(_ context.Context, _ Query) ([]Bar, error) {
+	p.calls++
+	return p.bars, p.err
+}
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+	failed := &stubProvider{name: "eastmoney", err: errors.New("HTTP 502")}
+	empty := &stubProvider{name: "csv"}
+	ok := &stubProvider{name: "xueqiu", bars: []Bar{{Time: "2025-04-30", Close: 4.71}}}
+	unused := &stubProvider{name: "backup", bars: []Bar{{Time: "2025-04-30"}}}
+
+	// 前面的数据源失败或没有数据时依次尝试，使用第一个有数据的
+	bars, source, err := Chain{failed, empty, ok, unused}.Bars(ctx, Query{Symbol: "SH601288"})
+	if err != nil || source != "xueqiu" || len(bars) != 1 || unused.calls != 0 {
+		t.Errorf("Bars = %v %q %v，backup 请求 %d 次", bars, source, err, unused.calls)
+	}
+
+	// 全部失败时汇总各数据源的错误
+	_, _, err = Chain{failed, empty}.Bars(ctx, Query{})
+	if err == nil || !errors.Is(err, ErrNoData) || !strings.Contains(err.Error(), "eastmoney: HTTP 502") || !strings.Contains(err.Error(), "csv: ") {
+		t.Errorf("全部失败 = %v", err)
+	}
+	if _, _, err := (Chain{}).Bars(ctx, Query{}); err == nil {
+		t.Error("没有数据源时应返回错误")
+	}
+
+	// ctx 取消后不再尝试后面的数据源
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	ok.calls = 0
+	if _, _, err := (Chain{failed, ok}).Bars(canceled, Query{}); !errors.Is(err, context.Canceled) || ok.calls != 0 {
+		t.Errorf("ctx 取消后 = %v，xueqiu 请求 %d 次", err, ok.calls)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	date := func(s string) time.Time {
+		t, _ := time.Parse(DateLayout, s)
+		return t
+	}
+	days := []Bar{{Time: "2025-04-25"}, {Time: "2025-04-28"}, {Time: "2025-04-29"}, {Time: "2025-04-30"}}
+	minutes := []Bar{{Time: "2025-04-29 14:55"}, {Time: "2025-04-29 15:00"}, {Time: "2025-04-30 09:35"}, {Time: "2025-04-30 15:00"}}
+
+	tests := []struct {
+		name string
+		bars []Bar
+		q    Query
+		want string
+	}{
+		{"不限制", days, Query{}, "2025-04-25,2025-04-28,2025-04-29,2025-04-30"},
+		{"区间含首尾", days, Query{Start: date("2025-04-28"), End: date("2025-04-29")}, "2025-04-28,2025-04-29"},
+		{"区间内最近N根", days, Query{Start: date("2025-04-26"), Count: 2}, "2025-04-29,2025-04-30"},
+		{"数量多于K线", days, Query{Count: 10}, "2025-04-25,2025-04-28,2025-04-29,2025-04-30"},
+		// 分钟线的结束日期包含当天全部K线
+		{"分钟线结束日期", minutes, Query{End: date("2025-04-29")}, "2025-04-29 14:55,2025-04-29 15:00"},
+		{"分钟线开始日期", minutes, Query{Start: date("2025-04-30"), Count: 1}, "2025-04-30 15:00"},
+		{"没有K线", days, Query{Start: date("2025-05-06")}, ""},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, b := range Filter(tt.bars, tt.q) {
+			got = append(got, b.Time)
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("%s: Filter = %v，期望 %s", tt.name, got, tt.want)
+		}
+	}
+}
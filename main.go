@@ -3,20 +3,34 @@ package main
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	"stock_agent/browser"
 	"stock_agent/config"
+	"stock_agent/extractor"
+	"stock_agent/security"
 	"stock_agent/tools"
 
+	"github.com/PuerkitoBio/goquery"
 	"github.com/firebase/genkit/go/ai"
 	"github.com/firebase/genkit/go/genkit"
 	"github.com/firebase/genkit/go/plugins/compat_oai"
 )
 
 func main() {
+	// 子命令：用保存的页面校验提取规则，无需配置文件
+	if len(os.Args) > 1 && os.Args[1] == "validate-rules" {
+		os.Exit(runValidateRules(os.Args[2:]))
+	}
+
 	ctx := context.Background()
 
 	// 加载配置文件
@@ -36,6 +50,106 @@ func main() {
 
 	// 设置全局genkit实例（供tools使用）
 	tools.SetGenkitInstance(g)
+
+	// 加载证券主数据（供代码解析使用），文件缺失时仅依赖大模型解析
+	securities := security.NewMaster()
+	for _, file := range config.Security.Files {
+		n, err := securities.LoadPath(file)
+		if err != nil {
+			log.Printf("加载证券主数据失败: %v", err)
+		}
+		if n > 0 {
+			log.Printf("已加载证券主数据 %s，共 %d 条", file, n)
+		}
+	}
+	for _, file := range config.Sector.Files {
+		n, err := securities.LoadSectorPath(file)
+		if err != nil {
+			log.Printf("加载板块数据失败: %v", err)
+			continue
+		}
+		log.Printf("已加载板块数据 %s，共 %d 个板块", file, n)
+	}
+	tools.SetSecurityMaster(securities)
+	tools.SetSectorOnline(config.Sector.Online)
+
+	// 启动共享浏览器页面池（首次爬取时才真正启动浏览器）
+	browserManager := browser.NewManager(browser.Options{
+		PoolSize:    config.Browser.PoolSize,
+		Bin:         config.Browser.Bin,
+		ShowBrowser: config.Browser.ShowBrowser,
+	})
+	defer browserManager.Close()
+	tools.SetBrowserManager(browserManager)
+
+	// 加载页面提取规则：内置规则，再用规则目录中的同名规则覆盖
+	rules, err := extractor.DefaultRegistry()
+	if err != nil {
+		log.Fatalf("加载内置提取规则失败: %v", err)
+	}
+	if n, err := rules.LoadDir(config.Extractor.RulesDir); err != nil {
+		log.Fatalf("加载提取规则失败: %v", err)
+	} else if n > 0 {
+		log.Printf("已从 %s 加载 %d 条提取规则", config.Extractor.RulesDir, n)
+	}
+	tools.SetExtractorRegistry(rules)
+	// 记录每次提取的命中情况，明显低于基线时在日志和分析报告中告警
+	monitor, err := extractor.NewMonitor(config.Extractor.HealthFile)
+	if err != nil {
+		log.Printf("%v，重新开始统计", err)
+	}
+	tools.SetHealthMonitor(monitor)
+
+	// 设置各数据源的页面抓取方式
+	if err := tools.SetFetcherModes(config.Fetcher.Default, config.Fetcher.Sources); err != nil {
+		log.Fatalf("抓取方式配置错误: %v", err)
+	}
+	cacheDir := config.Cache.Dir
+	if config.Cache.Disabled {
+		cacheDir = ""
+	}
+	if err := tools.SetPageCache(cacheDir, config.Cache.DefaultTTL, config.Cache.TTL, int64(config.Cache.MaxSizeMB)<<20); err != nil {
+		log.Fatalf("页面缓存配置错误: %v", err)
+	}
+	if err := tools.SetArchive(config.Archive.Mode, config.Archive.Dir); err != nil {
+		log.Fatalf("抓取存档配置错误: %v", err)
+	}
+	if config.Archive.Mode != "off" {
+		log.Printf("抓取存档模式: %s，目录: %s", config.Archive.Mode, config.Archive.Dir)
+	}
+	if err := tools.SetKlineProviders(config.Kline.Providers, config.Kline.CSVDir); err != nil {
+		log.Fatalf("K线数据源配置错误: %v", err)
+	}
+	if err := tools.SetFinancialProviders(config.Financial.Providers, config.Financial.CSVDir, config.Financial.CacheDir, config.Financial.CacheTTL); err != nil {
+		log.Fatalf("财务数据源配置错误: %v", err)
+	}
+	tools.SetFeedURLs(config.Feed.URLs)
+	tools.SetCrawlOptions(tools.CrawlOptions{
+		Workers:      config.Crawler.Workers,
+		HostInterval: config.Crawler.HostInterval,
+		Deadline:     config.Crawler.Deadline,
+	})
+
+	// Ctrl-C 中断正在进行的对话轮次；空闲时或收到 SIGTERM 则关闭浏览器并退出，避免残留Chrome进程
+	var turnMu sync.Mutex
+	var cancelTurn context.CancelFunc
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		for sig := range sigCh {
+			turnMu.Lock()
+			cancel := cancelTurn
+			turnMu.Unlock()
+			if sig == os.Interrupt && cancel != nil {
+				fmt.Println("\n⏹ 正在中断当前请求...")
+				cancel()
+				continue
+			}
+			fmt.Println("\n👋 正在关闭浏览器...")
+			browserManager.Close()
+			os.Exit(0)
+		}
+	}()
 	// 查询农业银行相关股票信息，爬取30条新闻，并生成分析报告，并生成Markdown报告
 	// 定义工具
 	toolList := tools.InitTools(g)
@@ -44,10 +158,11 @@ func main() {
 	var history []*ai.Message
 
 	fmt.Println("🤖 股票行情查询Agent（支持搜索新闻、AI分析和Markdown生成）")
-	fmt.Println("输入 'exit' 退出，例如：")
+	fmt.Println("输入 'exit' 退出，输入 'cache' 查看页面缓存命中情况，例如：")
 	fmt.Println("  - 帮我查询腾讯的股票新闻并生成分析报告")
 	fmt.Println("  - 搜索阿里巴巴的最新30条新闻")
 	fmt.Println("  - 分析AAPL的股票新闻并导出Markdown文件")
+	fmt.Println("  - 分析光伏板块前5只成分股，生成板块报告")
 
 	history = append(history, ai.NewMessage(ai.RoleSystem, map[string]any{}, ai.NewTextPart(`
 		你是一位专业的股票分析师,当用户输入股票关键词时，请先搜索相关新闻，然后基于新闻内容，输出一份详细的股票分析报告，采用markdown格式
@@ -68,18 +183,37 @@ func main() {
 			fmt.Println("👋 再见！")
 			break
 		}
+		if strings.ToLower(userInput) == "cache" {
+			fmt.Println(tools.PageCacheStats())
+			continue
+		}
 
 		// 添加用户消息
 		history = append(history, ai.NewUserMessage(ai.NewTextPart(userInput)))
 
 		// 调用模型（自动处理工具调用循环）
 		fmt.Print("AI: ")
-		resp, err := genkit.Generate(ctx, g,
+		turnCtx, cancel := context.WithCancel(ctx)
+		turnMu.Lock()
+		cancelTurn = cancel
+		turnMu.Unlock()
+		resp, err := genkit.Generate(turnCtx, g,
 			ai.WithModelName(config.AI.ModelName),
 			ai.WithMessages(history...),
 			ai.WithTools(toolList...),
 			ai.WithMaxTurns(10), // 最多10轮工具调用循环
 		)
+		turnMu.Lock()
+		cancelTurn = nil
+		turnMu.Unlock()
+		interrupted := turnCtx.Err() != nil
+		cancel()
+		if interrupted {
+			// 丢弃未完成的用户消息，避免影响下一轮对话
+			history = history[:len(history)-1]
+			fmt.Println("\n⏹ 已中断")
+			continue
+		}
 		if err != nil {
 			fmt.Printf("\n❌ 错误: %v\n", err)
 			log.Printf("详细错误: %+v", err)
@@ -94,3 +228,63 @@ func main() {
 		history = append(history, ai.NewModelMessage(ai.NewTextPart(text)))
 	}
 }
+
+// runValidateRules 用保存的页面校验提取规则，输出各字段的命中情况，返回进程退出码
+// 用法: validate-rules [-rules 规则目录] [规则名 页面文件]，不带参数时列出全部规则
+func runValidateRules(args []string) int {
+	fs := flag.NewFlagSet("validate-rules", flag.ContinueOnError)
+	dir := fs.String("rules", "rules", "规则目录，其中的同名规则覆盖内置规则")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "用法: validate-rules [-rules 规则目录] [规则名 页面文件]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	registry, err := extractor.DefaultRegistry()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载内置提取规则失败: %v\n", err)
+		return 1
+	}
+	if _, err := registry.LoadDir(*dir); err != nil {
+		fmt.Fprintf(os.Stderr, "加载提取规则失败: %v\n", err)
+		return 1
+	}
+
+	if fs.NArg() == 0 {
+		for _, name := range registry.Names() {
+			rule, _ := registry.Get(name)
+			fmt.Printf("%-16s %s\n", name, rule.Description)
+		}
+		return 0
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	rule, ok := registry.Get(fs.Arg(0))
+	if !ok {
+		fmt.Fprintf(os.Stderr, "没有名为 %s 的规则，可用规则: %s\n", fs.Arg(0), strings.Join(registry.Names(), ", "))
+		return 1
+	}
+	f, err := os.Open(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "打开页面文件失败: %v\n", err)
+		return 1
+	}
+	defer f.Close()
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "解析页面失败: %v\n", err)
+		return 1
+	}
+
+	report := rule.Validate(doc, time.Now())
+	fmt.Print(report.String())
+	if !report.OK() {
+		return 1
+	}
+	return 0
+}
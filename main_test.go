@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureOutput 运行 fn 并返回其写到标准输出和标准错误的内容
+func captureOutput(t *testing.T, fn func()) string {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = f, f
+	defer func() { os.Stdout, os.Stderr = oldStdout, oldStderr }()
+	fn()
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRunValidateRules(t *testing.T) {
+	rulesDir := t.TempDir()
+	page := filepath.Join("extractor", "testdata", "xueqiu_search.html")
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+		want string
+	}{
+		{"列出规则", []string{"-rules", rulesDir}, 0, "xueqiu_search"},
+		{"校验通过", []string{"-rules", rulesDir, "xueqiu_search", page}, 0, "结果: 通过"},
+		{"规则与页面不符", []string{"-rules", rulesDir, "cls_depth", page}, 1, "未通过"},
+		{"没有该规则", []string{"-rules", rulesDir, "xueqiu_hot", page}, 1, "没有名为 xueqiu_hot 的规则"},
+		{"页面不存在", []string{"-rules", rulesDir, "xueqiu_search", filepath.Join(rulesDir, "missing.html")}, 1, "打开页面文件失败"},
+		{"参数个数不对", []string{"-rules", rulesDir, "xueqiu_search"}, 2, "用法"},
+		{"未知参数", []string{"-verbose"}, 2, "用法"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var code int
+			output := captureOutput(t, func() { code = runValidateRules(tt.args) })
+			if code != tt.code || !strings.Contains(output, tt.want) {
+				t.Errorf("退出码 %d，期望 %d；输出:\n%s", code, tt.code, output)
+			}
+		})
+	}
+
+	// 规则目录中的同名规则覆盖内置规则
+	override := "site: xueqiu\nrules:\n  - name: xueqiu_search\n    list: \".stock-list a\"\n    fields:\n      url:\n        attr: href\n        required: true\n"
+	if err := os.WriteFile(filepath.Join(rulesDir, "xueqiu.yaml"), []byte(override), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var code int
+	output := captureOutput(t, func() { code = runValidateRules([]string{"-rules", rulesDir, "xueqiu_search", page}) })
+	if code != 1 || !strings.Contains(output, "未通过") {
+		t.Errorf("覆盖后的规则应不通过，退出码 %d；输出:\n%s", code, output)
+	}
+	if err := os.WriteFile(filepath.Join(rulesDir, "xueqiu.yaml"), []byte("rules: ["), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	output = captureOutput(t, func() { code = runValidateRules([]string{"-rules", rulesDir}) })
+	if code != 1 || !strings.Contains(output, "加载提取规则失败") {
+		t.Errorf("规则文件无效时退出码 %d；输出:\n%s", code, output)
+	}
+}
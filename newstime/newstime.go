@@ -0,0 +1,295 @@
+// Package newstime 将网页上显示的发布时间（刚刚、5分钟前、昨天 14:32、10-15 09:30、2025年10月15日等）
+// 按抓取时间换算为北京时间的绝对时间
+package newstime
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Shanghai 北京时间，解析结果统一使用该时区
+var Shanghai = time.FixedZone("CST", 8*3600)
+
+const (
+	// futureTolerance 不带年份的日期晚于抓取时间超过该值时视为去年
+	futureTolerance = 24 * time.Hour
+	// clockTolerance 只有时分的时间晚于抓取时间超过该值时视为昨天（允许站点与本机有少量时钟偏差）
+	clockTolerance = 10 * time.Minute
+)
+
+// layouts 带完整日期的标准格式，RSS、Atom 和接口返回的时间多为这些格式
+var layouts = []string{
+	time.RFC3339Nano,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05.000",
+}
+
+// prefixes 时间前常见的说明文字
+var prefixes = []string{"发布时间", "发表时间", "更新时间", "发布于", "发表于", "更新于", "编辑于", "时间"}
+
+// fullWidth 全角数字和标点转半角
+var fullWidth = strings.NewReplacer(
+	"０", "0", "１", "1", "２", "2", "３", "3", "４", "4",
+	"５", "5", "６", "6", "７", "7", "８", "8", "９", "9",
+	"：", ":", "－", "-", "／", "/", "．", ".", "　", " ",
+)
+
+// cjkMarkers 中文日期、时间单位转为分隔符，转换后为 2025-10-15 14:32 的形式
+var cjkMarkers = strings.NewReplacer(
+	"年", "-", "月", "-", "日", " ", "号", " ",
+	"时", ":", "点半", ":30", "点", ":", "分", ":", "秒", "",
+)
+
+// findClock findPattern 中的时分部分
+const findClock = `\s*(?:凌晨|早上|早晨|上午|中午|下午|傍晚|晚上)?\s*\d{1,2}[:：]\d{2}(?:[:：]\d{2})?`
+
+var (
+	spacePattern    = regexp.MustCompile(`\s+`)
+	unixPattern     = regexp.MustCompile(`^\d{10}(\d{3})?$`)
+	relativePattern = regexp.MustCompile(`^(\d+|[零〇一二两三四五六七八九十百半]+)\s*个?\s*(秒钟|秒|分钟|分|小时|钟头|天|日|周|星期|礼拜|月|年)之?前$`)
+	dayWordPattern  = regexp.MustCompile(`^(今天|今日|昨天|昨日|前天)`)
+	datePattern     = regexp.MustCompile(`^(?:(\d{4})[-/.])?(\d{1,2})[-/.](\d{1,2})`)
+	clockPattern    = regexp.MustCompile(`^(凌晨|早上|早晨|上午|中午|下午|傍晚|晚上)?\s*(\d{1,2})(?::(\d{1,2}))?(?::(\d{1,2}))?$`)
+
+	// findPattern 在一段文本中查找时间表达式，供 Find 使用，时分前可带上午、下午等时段
+	findPattern = regexp.MustCompile(`\d{4}\s*[-/.年]\s*\d{1,2}\s*[-/.月]\s*\d{1,2}\s*日?(?:\s*(?:T|\s)?` + findClock + `)?` +
+		`|(?:今天|今日|昨天|昨日|前天)(?:` + findClock + `)?` +
+		`|\d{1,2}月\d{1,2}日(?:` + findClock + `)?` +
+		`|(?:\d+|[一二两三四五六七八九十半]+)\s*个?\s*(?:秒钟|秒|分钟|小时|天|周|个月|年)前` +
+		`|刚刚|刚才`)
+)
+
+// dayOffsets 日期词相对抓取日的天数
+var dayOffsets = map[string]int{"今天": 0, "今日": 0, "昨天": -1, "昨日": -1, "前天": -2}
+
+// Parse 将时间文本换算为北京时间，ref 为抓取时间（为零时使用当前时间）
+// 支持：刚刚；N秒/分钟/小时/天/周/个月/年前（数字或中文数字，含"半小时前"）；今天/昨天/前天 [时:分]；
+// 时:分[:秒]（当天）；月-日 [时:分]（当年）；年-月-日 [时:分[:秒]]，分隔符可为 - / . 或 年月日；
+// RFC3339、RFC1123 等标准格式；10位或13位Unix时间戳
+// 不带年份的日期晚于抓取时间一天以上时视为去年，只有时分且晚于抓取时间时视为昨天
+func Parse(text string, ref time.Time) (time.Time, error) {
+	if ref.IsZero() {
+		ref = time.Now()
+	}
+	ref = ref.In(Shanghai)
+	s := clean(text)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("时间为空")
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, s, Shanghai); err == nil {
+			return t.In(Shanghai), nil
+		}
+	}
+	if unixPattern.MatchString(s) {
+		n, _ := strconv.ParseInt(s, 10, 64)
+		if len(s) == 13 {
+			return time.UnixMilli(n).In(Shanghai), nil
+		}
+		return time.Unix(n, 0).In(Shanghai), nil
+	}
+	if t, ok := parseRelative(s, ref); ok {
+		return t, nil
+	}
+	if t, ok := parseCalendar(s, ref); ok {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("无法解析时间: %s", text)
+}
+
+// Find 在一段文本中查找第一个可解析的时间表达式并换算为北京时间
+func Find(text string, ref time.Time) (time.Time, bool) {
+	for _, match := range findPattern.FindAllString(text, -1) {
+		if t, err := Parse(match, ref); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// clean 转半角、合并空白并去掉"发布于"等前缀
+func clean(text string) string {
+	s := strings.TrimSpace(spacePattern.ReplaceAllString(fullWidth.Replace(text), " "))
+	for _, prefix := range prefixes {
+		if rest, ok := strings.CutPrefix(s, prefix); ok {
+			s = strings.TrimSpace(strings.TrimLeft(rest, ": "))
+			break
+		}
+	}
+	return s
+}
+
+// parseRelative 解析"刚刚"和"N分钟前"等相对时间
+func parseRelative(s string, ref time.Time) (time.Time, bool) {
+	if s == "刚刚" || s == "刚才" || strings.EqualFold(s, "just now") {
+		return ref, true
+	}
+	m := relativePattern.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, false
+	}
+	half := m[1] == "半"
+	n, ok := parseNumber(m[1])
+	if !ok && !half {
+		return time.Time{}, false
+	}
+	switch m[2] {
+	case "秒钟", "秒":
+		return ref.Add(-time.Duration(n) * time.Second), true
+	case "分钟", "分":
+		if half {
+			return ref.Add(-30 * time.Second), true
+		}
+		return ref.Add(-time.Duration(n) * time.Minute), true
+	case "小时", "钟头":
+		if half {
+			return ref.Add(-30 * time.Minute), true
+		}
+		return ref.Add(-time.Duration(n) * time.Hour), true
+	case "天", "日":
+		if half {
+			return ref.Add(-12 * time.Hour), true
+		}
+		return ref.AddDate(0, 0, -n), true
+	case "周", "星期", "礼拜":
+		return ref.AddDate(0, 0, -7*n), true
+	case "月":
+		if half {
+			return ref.AddDate(0, 0, -15), true
+		}
+		return ref.AddDate(0, -n, 0), true
+	case "年":
+		if half {
+			return ref.AddDate(0, -6, 0), true
+		}
+		return ref.AddDate(-n, 0, 0), true
+	}
+	return time.Time{}, false
+}
+
+// parseCalendar 解析日期词、日期和时分的组合
+func parseCalendar(s string, ref time.Time) (time.Time, bool) {
+	year, month, day := ref.Date()
+	dated, inferYear := false, false
+	// 日期词中的"日"会被当作单位替换，需先于单位转换处理
+	if m := dayWordPattern.FindStringSubmatch(s); m != nil {
+		year, month, day = ref.AddDate(0, 0, dayOffsets[m[1]]).Date()
+		s = s[len(m[0]):]
+		dated = true
+	}
+	s = strings.TrimSpace(spacePattern.ReplaceAllString(cjkMarkers.Replace(s), " "))
+	if m := datePattern.FindStringSubmatch(s); !dated && m != nil {
+		mon, _ := strconv.Atoi(m[2])
+		d, _ := strconv.Atoi(m[3])
+		if mon < 1 || mon > 12 || d < 1 || d > 31 {
+			return time.Time{}, false
+		}
+		if m[1] != "" {
+			year, _ = strconv.Atoi(m[1])
+		} else {
+			inferYear = true
+		}
+		month, day = time.Month(mon), d
+		s = s[len(m[0]):]
+		dated = true
+	}
+
+	s = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), "T"))
+	hour, minute, second := 0, 0, 0
+	if s != "" {
+		m := clockPattern.FindStringSubmatch(strings.TrimRight(s, ": "))
+		// 没有日期时至少要有分钟或上午、下午等时段，避免把普通数字当成时间
+		if m == nil || (!dated && m[1] == "" && m[3] == "") {
+			return time.Time{}, false
+		}
+		hour, _ = strconv.Atoi(m[2])
+		minute, _ = strconv.Atoi(m[3])
+		second, _ = strconv.Atoi(m[4])
+		hour = adjustHour(m[1], hour)
+		if hour > 23 || minute > 59 || second > 59 {
+			return time.Time{}, false
+		}
+	} else if !dated {
+		return time.Time{}, false
+	}
+
+	t := time.Date(year, month, day, hour, minute, second, 0, Shanghai)
+	// 排除 02-30 这类不存在的日期
+	if t.Day() != day {
+		return time.Time{}, false
+	}
+	switch {
+	case inferYear && t.After(ref.Add(futureTolerance)):
+		t = t.AddDate(-1, 0, 0)
+	case !dated && t.After(ref.Add(clockTolerance)):
+		t = t.AddDate(0, 0, -1)
+	}
+	return t, true
+}
+
+// adjustHour 按上午、下午等时段将12小时制换算为24小时制
+func adjustHour(period string, hour int) int {
+	switch period {
+	case "下午", "傍晚", "晚上":
+		if hour < 12 {
+			return hour + 12
+		}
+	case "中午":
+		if hour < 11 {
+			return hour + 12
+		}
+	case "凌晨", "早上", "早晨", "上午":
+		if hour == 12 {
+			return 0
+		}
+	}
+	return hour
+}
+
+// chineseDigits 中文数字
+var chineseDigits = map[rune]int{'零': 0, '〇': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8, '九': 9}
+
+// parseNumber 解析阿拉伯数字或一百以内的中文数字，如 3、十五、二十、两
+func parseNumber(text string) (int, bool) {
+	if n, err := strconv.Atoi(text); err == nil {
+		return n, true
+	}
+	total, digit, seen := 0, 0, false
+	for _, r := range text {
+		switch {
+		case r == '十':
+			if !seen {
+				digit = 1
+			}
+			total += digit * 10
+			digit, seen = 0, false
+			continue
+		case r == '百':
+			if !seen {
+				digit = 1
+			}
+			total += digit * 100
+			digit, seen = 0, false
+			continue
+		}
+		d, ok := chineseDigits[r]
+		if !ok {
+			return 0, false
+		}
+		digit, seen = d, true
+	}
+	if text == "" {
+		return 0, false
+	}
+	return total + digit, true
+}
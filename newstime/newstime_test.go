@@ -0,0 +1,97 @@
+package newstime
+
+import (
+	"testing"
+	"time"
+)
+
+func at(year int, month time.Month, day, hour, minute, second int) time.Time {
+	return time.Date(year, month, day, hour, minute, second, 0, Shanghai)
+}
+
+func TestParse(t *testing.T) {
+	ref := at(2025, 10, 15, 14, 30, 0)
+	newYear := at(2025, 1, 1, 0, 10, 0)
+	tests := []struct {
+		text string
+		ref  time.Time
+		want time.Time
+	}{
+		{"刚刚", ref, ref},
+		{"5分钟前", ref, at(2025, 10, 15, 14, 25, 0)},
+		{"半小时前", ref, at(2025, 10, 15, 14, 0, 0)},
+		{"三小时前", ref, at(2025, 10, 15, 11, 30, 0)},
+		{"2天前", ref, at(2025, 10, 13, 14, 30, 0)},
+		{"今天 09:15", ref, at(2025, 10, 15, 9, 15, 0)},
+		{"昨天 23:45", ref, at(2025, 10, 14, 23, 45, 0)},
+		{"前天", ref, at(2025, 10, 13, 0, 0, 0)},
+		{"昨天下午3:20", ref, at(2025, 10, 14, 15, 20, 0)},
+		{"10-14 09:30", ref, at(2025, 10, 14, 9, 30, 0)},
+		{"10月14日 09:30", ref, at(2025, 10, 14, 9, 30, 0)},
+		{"2024-03-08 10:00:05", ref, at(2024, 3, 8, 10, 0, 5)},
+		{"2024年3月8日", ref, at(2024, 3, 8, 0, 0, 0)},
+		{"2024/03/08", ref, at(2024, 3, 8, 0, 0, 0)},
+		{"发布于 2024-03-08 10:00", ref, at(2024, 3, 8, 10, 0, 0)},
+		{"14:02", ref, at(2025, 10, 15, 14, 2, 0)},
+		{"下午2点半", ref, at(2025, 10, 15, 14, 30, 0)},
+		{"2025-10-15T06:30:00Z", ref, at(2025, 10, 15, 14, 30, 0)},
+		{"Wed, 15 Oct 2025 14:30:00 +0800", ref, ref},
+		{"1760509800", ref, ref},
+		// 跨年：年初看到的年末日期属于去年
+		{"12-31 22:00", newYear, at(2024, 12, 31, 22, 0, 0)},
+		{"昨天 23:30", newYear, at(2024, 12, 31, 23, 30, 0)},
+		{"2小时前", newYear, at(2024, 12, 31, 22, 10, 0)},
+		// 跨零点：刚过零点时看到的深夜时间属于前一天
+		{"23:50", newYear, at(2024, 12, 31, 23, 50, 0)},
+		{"00:05", newYear, at(2025, 1, 1, 0, 5, 0)},
+		// 允许站点时钟略快于本机
+		{"14:35", ref, at(2025, 10, 15, 14, 35, 0)},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.text, tt.ref)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.text, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("Parse(%q) = %v，期望 %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	ref := at(2025, 10, 15, 14, 30, 0)
+	for _, text := range []string{"", "阅读 3.2万", "12", "02-30 10:00", "25:10", "2025-13-01"} {
+		if got, err := Parse(text, ref); err == nil {
+			t.Errorf("Parse(%q) = %v，期望返回错误", text, got)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	ref := at(2025, 10, 15, 14, 30, 0)
+	tests := []struct {
+		text string
+		want time.Time
+	}{
+		{"今天上午 10:20 公司发布公告", at(2025, 10, 15, 10, 20, 0)},
+		{"昨天下午 3:05 更新", at(2025, 10, 14, 15, 5, 0)},
+		{"记者 王晓 2025年10月14日 晚上 8:15 北京", at(2025, 10, 14, 20, 15, 0)},
+		{"来源：财联社 10月15日 09:30 阅读 1.2万", at(2025, 10, 15, 9, 30, 0)},
+		{"作者 张三 · 3分钟前 · 来自雪球", at(2025, 10, 15, 14, 27, 0)},
+		{"发布于 2024-03-08 10:00", at(2024, 3, 8, 10, 0, 0)},
+	}
+	for _, tt := range tests {
+		got, ok := Find(tt.text, ref)
+		if !ok {
+			t.Errorf("Find(%q) 未找到时间", tt.text)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("Find(%q) = %v，期望 %v", tt.text, got, tt.want)
+		}
+	}
+	if got, ok := Find("暂无更新", ref); ok {
+		t.Errorf("Find 不应在无时间的文本中找到时间，得到 %v", got)
+	}
+}
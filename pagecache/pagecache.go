@@ -0,0 +1,288 @@
+// Package pagecache 按规范化URL缓存抓取结果的本地文件缓存
+// 每类页面使用各自的有效期，同一会话中重复查询时不必重新启动浏览器抓取
+package pagecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Forever 永不过期的有效期，用于公告PDF等发布后不再变化的内容
+const Forever time.Duration = -1
+
+// Stats 单类缓存的命中统计
+type Stats struct {
+	Hits     int // 命中
+	Misses   int // 未缓存
+	Expired  int // 已过期，重新抓取
+	Bypassed int // 调用方要求忽略缓存
+	Stores   int // 写入
+	Evicted  int // 超出大小上限被删除
+	Errors   int // 读写失败
+}
+
+// Cache 抓取结果的文件缓存，目录下每类一个子目录，每个URL一个文件，文件修改时间即抓取时间
+type Cache struct {
+	Dir string
+	// MaxBytes 缓存目录的总大小上限，超出时按抓取时间从旧到新删除，0 表示不限制
+	MaxBytes int64
+
+	mu    sync.Mutex
+	stats map[string]*Stats
+	// size 目录当前总大小，首次写入时统计，之后随写入累加
+	size  int64
+	sized bool
+
+	pruneMu sync.Mutex
+}
+
+// ParseTTL 解析有效期：Go 时长格式如 2m、24h，forever 表示永不过期，0 表示不缓存
+func ParseTTL(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "forever") {
+		return Forever, nil
+	}
+	ttl, err := time.ParseDuration(s)
+	if err != nil || ttl < 0 {
+		return 0, fmt.Errorf("无效的缓存有效期 %q，应为 2m、24h 或 forever", s)
+	}
+	return ttl, nil
+}
+
+// NormalizeURL 规范化URL作为缓存键：协议和主机小写、去掉默认端口、片段和 utm_ 跟踪参数，查询参数按名称排序
+func NormalizeURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return strings.TrimSpace(raw)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	u.Host = host
+	u.Fragment, u.RawFragment = "", ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	query := u.Query()
+	for name := range query {
+		if strings.HasPrefix(strings.ToLower(name), "utm_") {
+			query.Del(name)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// Get 读取缓存，ttl 为0、不存在或已过期时返回 false，并计入统计
+func (c *Cache) Get(class, rawURL string, ttl time.Duration) ([]byte, bool) {
+	if ttl == 0 {
+		return nil, false
+	}
+	path := c.path(class, rawURL)
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		c.count(class, func(s *Stats) { s.Misses++ })
+		return nil, false
+	}
+	if err != nil {
+		c.count(class, func(s *Stats) { s.Errors++ })
+		return nil, false
+	}
+	if ttl != Forever && time.Since(info.ModTime()) >= ttl {
+		c.count(class, func(s *Stats) { s.Expired++ })
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		c.count(class, func(s *Stats) { s.Errors++ })
+		return nil, false
+	}
+	c.count(class, func(s *Stats) { s.Hits++ })
+	return data, true
+}
+
+// Put 写入缓存，先写临时文件再重命名，避免并发读到半个文件
+// 设置了 MaxBytes 时，写入后总大小超出上限会删除最早的缓存
+func (c *Cache) Put(class, rawURL string, data []byte) error {
+	path := c.path(class, rawURL)
+	var old int64
+	if info, err := os.Stat(path); err == nil {
+		old = info.Size()
+	}
+	err := c.put(path, data)
+	c.count(class, func(s *Stats) {
+		if err != nil {
+			s.Errors++
+		} else {
+			s.Stores++
+		}
+	})
+	if err != nil || c.MaxBytes <= 0 {
+		return err
+	}
+
+	c.mu.Lock()
+	c.size += int64(len(data)) - old
+	over := !c.sized || c.size > c.MaxBytes
+	c.mu.Unlock()
+	if over {
+		if _, err := c.Prune(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Prune 按抓取时间从旧到新删除缓存文件，直到总大小不超过 MaxBytes，返回删除的文件数
+// 永不过期的PDF等同样会被删除，需要时重新下载
+func (c *Cache) Prune() (int, error) {
+	c.pruneMu.Lock()
+	defer c.pruneMu.Unlock()
+
+	type entry struct {
+		path, class string
+		size        int64
+		modTime     time.Time
+	}
+	var entries []entry
+	var total int64
+	err := filepath.WalkDir(c.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasSuffix(path, ".tmp") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // 文件已被删除
+		}
+		class := filepath.Base(filepath.Dir(path))
+		entries = append(entries, entry{path: path, class: class, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("统计页面缓存大小失败: %v", err)
+	}
+
+	removed := 0
+	if c.MaxBytes > 0 && total > c.MaxBytes {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].modTime.Before(entries[j].modTime) })
+		for _, e := range entries {
+			if total <= c.MaxBytes {
+				break
+			}
+			if err := os.Remove(e.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			total -= e.size
+			removed++
+			c.count(e.class, func(s *Stats) { s.Evicted++ })
+		}
+	}
+	c.mu.Lock()
+	c.size, c.sized = total, true
+	c.mu.Unlock()
+	return removed, nil
+}
+
+func (c *Cache) put(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("创建页面缓存目录失败: %v", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("写入页面缓存失败: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入页面缓存失败: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入页面缓存失败: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("写入页面缓存失败: %v", err)
+	}
+	return nil
+}
+
+// Bypass 记录一次忽略缓存的请求
+func (c *Cache) Bypass(class string) {
+	c.count(class, func(s *Stats) { s.Bypassed++ })
+}
+
+// Stats 返回各类缓存的命中统计
+func (c *Cache) Stats() map[string]Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := make(map[string]Stats, len(c.stats))
+	for class, s := range c.stats {
+		stats[class] = *s
+	}
+	return stats
+}
+
+// String 输出可读的命中统计，按类别排序
+func (c *Cache) String() string {
+	stats := c.Stats()
+	if len(stats) == 0 {
+		return "页面缓存尚未使用"
+	}
+	classes := make([]string, 0, len(stats))
+	for class := range stats {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	var b strings.Builder
+	fmt.Fprintf(&b, "页面缓存（%s）:\n", c.Dir)
+	for _, class := range classes {
+		s := stats[class]
+		fmt.Fprintf(&b, "  %-14s 命中 %d，未缓存 %d，过期 %d，跳过 %d，写入 %d", class, s.Hits, s.Misses, s.Expired, s.Bypassed, s.Stores)
+		if s.Evicted > 0 {
+			fmt.Fprintf(&b, "，淘汰 %d", s.Evicted)
+		}
+		if s.Errors > 0 {
+			fmt.Fprintf(&b, "，失败 %d", s.Errors)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (c *Cache) count(class string, update func(*Stats)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stats == nil {
+		c.stats = make(map[string]*Stats)
+	}
+	s, ok := c.stats[class]
+	if !ok {
+		s = &Stats{}
+		c.stats[class] = s
+	}
+	update(s)
+}
+
+func (c *Cache) path(class, rawURL string) string {
+	sum := sha256.Sum256([]byte(NormalizeURL(rawURL)))
+	return filepath.Join(c.Dir, class, hex.EncodeToString(sum[:16]))
+}
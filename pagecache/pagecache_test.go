@@ -0,0 +1,168 @@
+package pagecache
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// age 把缓存文件的抓取时间改为 d 之前
+func age(t *testing.T, c *Cache, class, rawURL string, d time.Duration) {
+	t.Helper()
+	past := time.Now().Add(-d)
+	if err := os.Chtimes(c.path(class, rawURL), past, past); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetPut(t *testing.T) {
+	c := &Cache{Dir: t.TempDir()}
+	const pageURL = "https://www.cls.cn/telegraph?keyword=农业银行"
+
+	if _, ok := c.Get("cls_telegram", pageURL, time.Minute); ok {
+		t.Fatal("空缓存不应命中")
+	}
+	if err := c.Put("cls_telegram", pageURL, []byte("第一次")); err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := c.Get("cls_telegram", pageURL, time.Minute); !ok || string(data) != "第一次" {
+		t.Errorf("Get = %q %v，期望命中", data, ok)
+	}
+	// ttl 为0表示不缓存，不计入统计
+	if _, ok := c.Get("cls_telegram", pageURL, 0); ok {
+		t.Error("ttl 为0时不应命中")
+	}
+	// 不同类别互不影响
+	if _, ok := c.Get("cls_depth", pageURL, time.Minute); ok {
+		t.Error("不同类别不应命中")
+	}
+
+	age(t, c, "cls_telegram", pageURL, 2*time.Minute)
+	if _, ok := c.Get("cls_telegram", pageURL, time.Minute); ok {
+		t.Error("过期后不应命中")
+	}
+	// 重新写入覆盖旧内容并刷新抓取时间
+	if err := c.Put("cls_telegram", pageURL, []byte("第二次")); err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := c.Get("cls_telegram", pageURL, time.Minute); !ok || string(data) != "第二次" {
+		t.Errorf("覆盖后 Get = %q %v", data, ok)
+	}
+
+	want := map[string]Stats{
+		"cls_telegram": {Hits: 2, Misses: 1, Expired: 1, Stores: 2},
+		"cls_depth":    {Misses: 1},
+	}
+	if got := c.Stats(); len(got) != len(want) || got["cls_telegram"] != want["cls_telegram"] || got["cls_depth"] != want["cls_depth"] {
+		t.Errorf("Stats = %+v，期望 %+v", got, want)
+	}
+	c.Bypass("cls_telegram")
+	if got := c.Stats()["cls_telegram"].Bypassed; got != 1 {
+		t.Errorf("Bypassed = %d，期望 1", got)
+	}
+	if s := c.String(); !strings.Contains(s, "命中 2，未缓存 1，过期 1，跳过 1，写入 2") {
+		t.Errorf("String = %q", s)
+	}
+}
+
+func TestGetForever(t *testing.T) {
+	c := &Cache{Dir: t.TempDir()}
+	const pdfURL = "http://static.cninfo.com.cn/finalpage/2025-04-29/1223.PDF"
+	if err := c.Put("pdf", pdfURL, []byte("%PDF-1.4")); err != nil {
+		t.Fatal(err)
+	}
+	age(t, c, "pdf", pdfURL, 3*365*24*time.Hour)
+	if _, ok := c.Get("pdf", pdfURL, Forever); !ok {
+		t.Error("永不过期的缓存应命中")
+	}
+	if _, ok := c.Get("pdf", pdfURL, 24*time.Hour); ok {
+		t.Error("按24h有效期应已过期")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	c := &Cache{Dir: t.TempDir(), MaxBytes: 25}
+	data := []byte("0123456789")
+	for i, rawURL := range []string{"https://a.com/1", "https://a.com/2"} {
+		if err := c.Put("page", rawURL, data); err != nil {
+			t.Fatal(err)
+		}
+		age(t, c, "page", rawURL, time.Duration(10-i)*time.Minute)
+	}
+	// 第三个文件写入后超出上限，删除最早的一个
+	if err := c.Put("pdf", "https://a.com/3", data); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("page", "https://a.com/1", Forever); ok {
+		t.Error("最早的缓存应已删除")
+	}
+	for _, key := range [][2]string{{"page", "https://a.com/2"}, {"pdf", "https://a.com/3"}} {
+		if _, ok := c.Get(key[0], key[1], Forever); !ok {
+			t.Errorf("%s %s 不应删除", key[0], key[1])
+		}
+	}
+	if got := c.Stats()["page"].Evicted; got != 1 {
+		t.Errorf("Evicted = %d，期望 1", got)
+	}
+	// 覆盖写入不增加总大小
+	if err := c.Put("pdf", "https://a.com/3", data); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("page", "https://a.com/2", Forever); !ok {
+		t.Error("覆盖写入后不应再删除缓存")
+	}
+
+	unlimited := &Cache{Dir: c.Dir}
+	if n, err := unlimited.Prune(); n != 0 || err != nil {
+		t.Errorf("不限制大小时 Prune = %d %v", n, err)
+	}
+}
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"HTTPS://WWW.CLS.CN/telegraph?b=2&a=1", "https://www.cls.cn/telegraph?a=1&b=2"},
+		{"https://www.cls.cn:443/detail/1001#comment", "https://www.cls.cn/detail/1001"},
+		{"http://xueqiu.com:80", "http://xueqiu.com/"},
+		{"https://xueqiu.com/S/SH601288?utm_source=wx&UTM_medium=share", "https://xueqiu.com/S/SH601288"},
+	}
+	for _, tt := range tests {
+		if a, b := NormalizeURL(tt.a), NormalizeURL(tt.b); a != b {
+			t.Errorf("NormalizeURL(%q) = %q，NormalizeURL(%q) = %q，期望相同", tt.a, a, tt.b, b)
+		}
+	}
+	different := [][2]string{
+		{"https://www.cls.cn/detail/1001", "https://www.cls.cn/detail/1002"},
+		{"https://www.cls.cn:8443/detail/1001", "https://www.cls.cn/detail/1001"},
+		{"https://www.cls.cn/telegraph?keyword=农行", "https://www.cls.cn/telegraph?keyword=茅台"},
+	}
+	for _, tt := range different {
+		if NormalizeURL(tt[0]) == NormalizeURL(tt[1]) {
+			t.Errorf("%q 与 %q 不应视为同一页面", tt[0], tt[1])
+		}
+	}
+}
+
+func TestParseTTL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"2m", 2 * time.Minute},
+		{" 24h ", 24 * time.Hour},
+		{"Forever", Forever},
+		{"0", 0},
+	}
+	for _, tt := range tests {
+		if got, err := ParseTTL(tt.in); err != nil || got != tt.want {
+			t.Errorf("ParseTTL(%q) = %v %v，期望 %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"-1m", "一天", ""} {
+		if _, err := ParseTTL(in); err == nil {
+			t.Errorf("ParseTTL(%q) 应返回错误", in)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package security
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// columnAliases CSV表头别名，兼容本项目的英文表头以及交易所导出的证券列表
+var columnAliases = map[string][]string{
+	"code":     {"code", "symbol", "证券代码", "股票代码", "a股代码", "代码"},
+	"exchange": {"exchange", "market", "交易所", "市场", "上市地"},
+	"name":     {"name", "证券简称", "股票简称", "a股简称", "简称", "名称"},
+	"pinyin":   {"pinyin", "拼音", "拼音简称"},
+	"industry": {"industry", "所属行业", "行业", "行业名称"},
+	"listdate": {"list_date", "listdate", "上市日期", "a股上市日期"},
+	"aliases":  {"aliases", "alias", "别名"},
+}
+
+// listDateLayouts 上市日期支持的格式
+var listDateLayouts = []string{"2006-01-02", "20060102", "2006/01/02", "2006/1/2", "2006-1-2", "2006年01月02日", "2006年1月2日"}
+
+// LoadPath 加载CSV文件，或目录下的全部 .csv 文件，返回加载的总条数
+// 返回错误时已加载的条数仍然有效
+func (m *Master) LoadPath(path string) (int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("读取证券文件失败: %v", err)
+	}
+	if !info.IsDir() {
+		return m.LoadCSV(path)
+	}
+
+	files, err := filepath.Glob(filepath.Join(path, "*.csv"))
+	if err != nil {
+		return 0, fmt.Errorf("读取证券目录失败: %v", err)
+	}
+	sort.Strings(files)
+	// 单个文件出错时继续加载其余文件，错误汇总返回
+	total := 0
+	var errs []string
+	for _, file := range files {
+		n, err := m.LoadCSV(file)
+		total += n
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return total, fmt.Errorf("%s", strings.Join(errs, "；"))
+	}
+	return total, nil
+}
+
+// LoadCSV 从CSV文件加载证券，返回加载的条数
+// 文件首行为表头，至少包含代码和简称两列，支持UTF-8和GBK编码，格式见 ReadCSV
+func (m *Master) LoadCSV(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("打开证券文件失败: %v", err)
+	}
+
+	// 交易所网站导出的文件通常为GBK编码
+	if !utf8.Valid(data) {
+		decoded, err := simplifiedchinese.GBK.NewDecoder().Bytes(data)
+		if err != nil {
+			return 0, fmt.Errorf("证券文件 %s 既不是UTF-8也不是GBK编码: %v", path, err)
+		}
+		data = decoded
+	}
+
+	n, err := m.ReadCSV(bytes.NewReader(data))
+	if err != nil {
+		return n, fmt.Errorf("读取证券文件 %s 失败: %v", path, err)
+	}
+	return n, nil
+}
+
+// ReadCSV 从CSV读取证券，返回读取的条数
+// 表头列名见 columnAliases，常用列为 code,exchange,name,pinyin,industry,list_date,aliases，
+// 多个别名用分号分隔；缺少 exchange 列时按代码推断交易所，拼音为空时自动生成；
+// 被 Excel 去掉前导零的代码按交易所补足，港股 700 存为 00700，exchange 为 SZ 的 1 存为 000001
+// 无法推断交易所、或补零后与交易所不符的行跳过，读完后在返回的错误中统一列出，其余行照常加入
+func (m *Master) ReadCSV(r io.Reader) (int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("读取表头失败: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for column, aliases := range columnAliases {
+			if _, ok := columns[column]; !ok && contains(aliases, name) {
+				columns[column] = i
+			}
+		}
+	}
+	for _, required := range []string{"code", "name"} {
+		if _, ok := columns[required]; !ok {
+			return 0, fmt.Errorf("缺少列: %s", required)
+		}
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	count := 0
+	var skipped, mismatched []string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, fmt.Errorf("解析CSV失败: %v", err)
+		}
+		// 按文件中的实际行号报告，csv.Reader 会跳过空行
+		line, _ := reader.FieldPos(0)
+		s := Security{
+			Code:     field(record, "code"),
+			Exchange: normalizeExchange(field(record, "exchange")),
+			Name:     field(record, "name"),
+			Pinyin:   field(record, "pinyin"),
+			Industry: field(record, "industry"),
+		}
+		if s.Code == "" && s.Name == "" {
+			continue // 空行
+		}
+		if s.Code == "" || s.Name == "" {
+			return count, fmt.Errorf("第%d行: 代码和简称不能为空", line)
+		}
+		if s.Exchange == "" {
+			s.Exchange = InferExchange(s.Code)
+		}
+		if s.Exchange == "" {
+			// 跳过该行继续导入，读完后统一报告
+			skipped = append(skipped, fmt.Sprintf("第%d行 %s", line, s.Code))
+			continue
+		}
+		if padded := padCode(s.Code, s.Exchange); padded != s.Code && s.Market() == MarketA && InferExchange(padded) != s.Exchange {
+			// 补零后的A股代码不属于该交易所，说明代码本身有误
+			mismatched = append(mismatched, fmt.Sprintf("第%d行 %s", line, s.Code))
+			continue
+		}
+		if date := field(record, "listdate"); date != "" {
+			listDate, err := parseListDate(date)
+			if err != nil {
+				return count, fmt.Errorf("第%d行: %v", line, err)
+			}
+			s.ListDate = listDate
+		}
+		if aliases := field(record, "aliases"); aliases != "" {
+			s.Aliases = strings.FieldsFunc(aliases, func(r rune) bool { return r == ';' || r == '；' })
+		}
+		m.Add(s)
+		count++
+	}
+	var errs []string
+	if len(skipped) > 0 {
+		errs = append(errs, fmt.Sprintf("无法推断所属交易所，已跳过 %d 行（%s），请补充 exchange 列", len(skipped), summarize(skipped, maxReportedRows)))
+	}
+	if len(mismatched) > 0 {
+		errs = append(errs, fmt.Sprintf("代码补足6位后与交易所不符，已跳过 %d 行（%s），请检查代码列", len(mismatched), summarize(mismatched, maxReportedRows)))
+	}
+	if len(errs) > 0 {
+		return count, fmt.Errorf("%s", strings.Join(errs, "；"))
+	}
+	return count, nil
+}
+
+// maxReportedRows 错误信息中最多列出的跳过行数
+const maxReportedRows = 10
+
+// summarize 用顿号连接前 n 项，超出部分以"等"省略
+func summarize(items []string, n int) string {
+	if len(items) <= n {
+		return strings.Join(items, "、")
+	}
+	return strings.Join(items[:n], "、") + " 等"
+}
+
+// InferExchange 按代码推断交易所：6位数字为A股（92 开头为北交所），5位及以下数字为港股，字母为美股
+// 5位以下的数字代码无法区分港股和去掉前导零的A股，一律按港股处理，A股需在 exchange 列注明
+func InferExchange(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return ""
+	}
+	if strings.Trim(code, "0123456789") != "" {
+		if strings.Trim(code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ.-") == "" {
+			return ExchangeUS
+		}
+		return ""
+	}
+	if len(code) <= 5 {
+		return ExchangeHK
+	}
+	if len(code) != 6 {
+		return ""
+	}
+	switch {
+	case strings.HasPrefix(code, "92"):
+		// 北交所新代码段 920xxx
+		return ExchangeBJ
+	case code[0] == '6' || code[0] == '9':
+		return ExchangeSH
+	case code[0] == '0' || code[0] == '2' || code[0] == '3':
+		return ExchangeSZ
+	case code[0] == '4' || code[0] == '8':
+		return ExchangeBJ
+	}
+	return ""
+}
+
+// normalizeExchange 将中文或常见英文写法统一为交易所代码
+func normalizeExchange(exchange string) string {
+	switch strings.ToUpper(strings.TrimSpace(exchange)) {
+	case "SH", "SSE", "上海", "上交所", "上海证券交易所":
+		return ExchangeSH
+	case "SZ", "SZSE", "深圳", "深交所", "深圳证券交易所":
+		return ExchangeSZ
+	case "BJ", "BSE", "北京", "北交所", "北京证券交易所":
+		return ExchangeBJ
+	case "HK", "HKEX", "香港", "港股", "港交所", "香港交易所":
+		return ExchangeHK
+	case "US", "NASDAQ", "NYSE", "AMEX", "美股", "纳斯达克", "纽交所":
+		return ExchangeUS
+	}
+	return ""
+}
+
+// parseListDate 解析上市日期，统一为 2006-01-02 格式
+func parseListDate(text string) (string, error) {
+	for _, layout := range listDateLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("无法解析上市日期: %s", text)
+}
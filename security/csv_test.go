@@ -0,0 +1,157 @@
+package security
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInferExchange(t *testing.T) {
+	tests := []struct {
+		code, want string
+	}{
+		{"601288", ExchangeSH},
+		{"688981", ExchangeSH},
+		{"900901", ExchangeSH}, // 沪市B股
+		{"920001", ExchangeBJ}, // 北交所新代码段
+		{"920118", ExchangeBJ},
+		{"000001", ExchangeSZ},
+		{"200596", ExchangeSZ},
+		{"300750", ExchangeSZ},
+		{"430047", ExchangeBJ},
+		{"830799", ExchangeBJ},
+		{"00700", ExchangeHK},
+		{"700", ExchangeHK}, // 存入时补足为 00700
+		{"aapl", ExchangeUS},
+		{"BRK.B", ExchangeUS},
+		{"510300", ""},
+		{"1234567", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := InferExchange(tt.code); got != tt.want {
+			t.Errorf("InferExchange(%q) = %q，期望 %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	data := `code,name,exchange,list_date,aliases
+601288,农业银行,,2010-07-15,农行;中国农业银行
+920001,纬达光电,,20230310,
+510300,沪深300ETF,,,
+
+000001,平安银行,深交所,1991/4/3,
+159915,创业板ETF,,,
+`
+	m := NewMaster()
+	n, err := m.ReadCSV(strings.NewReader(data))
+	if n != 3 {
+		t.Errorf("读取 %d 条，期望 3 条", n)
+	}
+	// 无法推断交易所的行全部跳过并一起报告，不影响后面的行
+	if err == nil || !strings.Contains(err.Error(), "已跳过 2 行（第4行 510300、第7行 159915）") {
+		t.Errorf("错误 = %v，期望列出跳过的两行", err)
+	}
+
+	tests := []struct {
+		symbol, name, listDate string
+	}{
+		{"SH601288", "农业银行", "2010-07-15"},
+		{"BJ920001", "纬达光电", "2023-03-10"},
+		{"SZ000001", "平安银行", "1991-04-03"},
+	}
+	for _, tt := range tests {
+		s, ok := m.BySymbol(tt.symbol)
+		if !ok {
+			t.Errorf("缺少 %s", tt.symbol)
+			continue
+		}
+		if s.Name != tt.name || s.ListDate != tt.listDate {
+			t.Errorf("%s = %s %s，期望 %s %s", tt.symbol, s.Name, s.ListDate, tt.name, tt.listDate)
+		}
+	}
+	if s, _ := m.BySymbol("SH601288"); strings.Join(s.Aliases, ",") != "农行,中国农业银行" {
+		t.Errorf("别名 = %q", s.Aliases)
+	}
+}
+
+func TestReadCSVPadCode(t *testing.T) {
+	// Excel 打开后保存的文件会去掉代码的前导零
+	data := `code,name,exchange
+700,腾讯控股,
+5,汇丰控股,港交所
+1,平安银行,SZ
+2594,比亚迪,深交所
+600,浦发银行,SH
+688981,中芯国际,SH
+`
+	m := NewMaster()
+	n, err := m.ReadCSV(strings.NewReader(data))
+	if n != 5 {
+		t.Errorf("读取 %d 条，期望 5 条", n)
+	}
+	// 600 补零后为深市代码 000600，与 SH 不符，不能猜测为 600000
+	if err == nil || !strings.Contains(err.Error(), "已跳过 1 行（第6行 600）") {
+		t.Errorf("错误 = %v，期望报告与交易所不符的行", err)
+	}
+	tests := []struct {
+		symbol, name string
+	}{
+		{"00700", "腾讯控股"},
+		{"00005", "汇丰控股"},
+		{"SZ000001", "平安银行"},
+		{"SZ002594", "比亚迪"},
+		{"SH688981", "中芯国际"},
+	}
+	for _, tt := range tests {
+		if s, ok := m.BySymbol(tt.symbol); !ok || s.Name != tt.name {
+			t.Errorf("BySymbol(%s) = %+v %v，期望 %s", tt.symbol, s, ok, tt.name)
+		}
+	}
+	for _, symbol := range []string{"700", "SZ1", "SH000600"} {
+		if _, ok := m.BySymbol(symbol); ok {
+			t.Errorf("不应以未补零的代码 %s 存入", symbol)
+		}
+	}
+	// 港股代码的各种写法统一后都能查到
+	if _, ok := m.BySymbol(NormalizeSymbol("0700.HK")); !ok {
+		t.Error("BySymbol(NormalizeSymbol(0700.HK)) 未命中")
+	}
+}
+
+func TestPadCode(t *testing.T) {
+	tests := []struct {
+		code, exchange, want string
+	}{
+		{"700", ExchangeHK, "00700"},
+		{"00700", ExchangeHK, "00700"},
+		{"1", ExchangeSZ, "000001"},
+		{"600", ExchangeSH, "000600"},
+		{"30047", ExchangeBJ, "030047"},
+		{"601288", ExchangeSH, "601288"},
+		{"AAPL", ExchangeUS, "AAPL"},
+		{"700", ExchangeUS, "700"},
+		{"", ExchangeHK, ""},
+	}
+	for _, tt := range tests {
+		if got := padCode(tt.code, tt.exchange); got != tt.want {
+			t.Errorf("padCode(%q, %q) = %q，期望 %q", tt.code, tt.exchange, got, tt.want)
+		}
+	}
+}
+
+func TestReadCSVMissingColumn(t *testing.T) {
+	if _, err := NewMaster().ReadCSV(strings.NewReader("code,industry\n601288,银行\n")); err == nil {
+		t.Error("缺少简称列时应返回错误")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	items := []string{"a", "b", "c"}
+	if got := summarize(items, 3); got != "a、b、c" {
+		t.Errorf("summarize = %q", got)
+	}
+	if got := summarize(items, 2); got != "a、b 等" {
+		t.Errorf("summarize = %q", got)
+	}
+}
@@ -0,0 +1,114 @@
+package security
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// MatchFuzzy 模糊匹配（错别字、缺字或拼音首字母前缀）
+const MatchFuzzy = "fuzzy"
+
+// minFuzzyScore 模糊匹配的最低得分
+const minFuzzyScore = 0.4
+
+// Search 模糊查询证券，按相似度降序返回最多 limit 个候选
+// 中文按编辑距离与包含关系打分，英文按代码和拼音首字母前缀打分
+func (m *Master) Search(query string, limit int) []Candidate {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.searchLocked(query, limit)
+}
+
+func (m *Master) searchLocked(query string, limit int) []Candidate {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+	upper := strings.ToUpper(query)
+	latin := isLatin(upper)
+
+	var candidates []Candidate
+	for _, s := range m.list {
+		score := 0.0
+		if latin {
+			score = prefixScore(upper, s.Code)
+			for _, abbr := range m.pinyin[s] {
+				score = max(score, prefixScore(upper, abbr))
+			}
+		} else {
+			for _, name := range s.names() {
+				score = max(score, nameSimilarity(query, name))
+			}
+		}
+		if score >= minFuzzyScore {
+			candidates = append(candidates, Candidate{Security: *s, Symbol: s.Symbol(), Confidence: score, MatchedBy: MatchFuzzy})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Confidence != candidates[j].Confidence {
+			return candidates[i].Confidence > candidates[j].Confidence
+		}
+		return candidates[i].Symbol < candidates[j].Symbol
+	})
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates
+}
+
+// nameSimilarity 计算两个名称的相似度（0-1），完全包含时按覆盖比例打分
+func nameSimilarity(query, name string) float64 {
+	if query == name {
+		return 1
+	}
+	q, n := []rune(query), []rune(name)
+	longest := max(len(q), len(n))
+	if longest == 0 {
+		return 0
+	}
+	similarity := 1 - float64(levenshtein(q, n))/float64(longest)
+	if strings.Contains(name, query) || strings.Contains(query, name) {
+		similarity = max(similarity, float64(min(len(q), len(n)))/float64(longest))
+	}
+	return similarity
+}
+
+// prefixScore 英文查询作为代码或拼音首字母前缀时，按覆盖比例打分
+func prefixScore(query, target string) float64 {
+	if target == "" || !strings.HasPrefix(target, query) {
+		return 0
+	}
+	return float64(utf8.RuneCountInString(query)) / float64(utf8.RuneCountInString(target))
+}
+
+// levenshtein 按字符计算编辑距离
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func isLatin(s string) bool {
+	for _, r := range s {
+		if r > 127 {
+			return false
+		}
+	}
+	return true
+}
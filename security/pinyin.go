@@ -0,0 +1,106 @@
+package security
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// maxAbbreviations 多音字组合过多时最多保留的拼音首字母数量
+const maxAbbreviations = 8
+
+// phraseInitials 证券简称中常见多音字词的惯用读法，优先于逐字注音的默认读法
+var phraseInitials = map[string]string{
+	"银行": "YH",
+	"重庆": "CQ",
+	"长江": "CJ",
+	"长城": "CC",
+	"长安": "CA",
+	"长春": "CC",
+	"长沙": "CS",
+	"长虹": "CH",
+	"重工": "ZG",
+	"重型": "ZX",
+	"乐视": "LS",
+	"音乐": "YY",
+}
+
+// Abbreviations 返回名称的拼音首字母（大写），多音字会产生多种组合，第一个为最常用读法
+// 英文字母和数字原样保留，其他符号忽略，例如“京东方A”返回 JDFA
+func Abbreviations(name string) []string {
+	args := pinyin.NewArgs()
+	args.Style = pinyin.FirstLetter
+	args.Heteronym = true
+
+	runes := []rune(name)
+	letters := make([][]string, len(runes))
+	for i, r := range runes {
+		if r < unicode.MaxASCII {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				letters[i] = []string{strings.ToUpper(string(r))}
+			}
+			continue
+		}
+		// 去重同一个字的多种读法中首字母相同的情况
+		for _, readings := range pinyin.Pinyin(string(r), args) {
+			for _, l := range readings {
+				if l = strings.ToUpper(l); !contains(letters[i], l) {
+					letters[i] = append(letters[i], l)
+				}
+			}
+		}
+	}
+	// 惯用读法提前，使第一个组合为最常用读法
+	for i := range runes {
+		for phrase, initials := range phraseInitials {
+			p := []rune(phrase)
+			if i+len(p) > len(runes) || string(runes[i:i+len(p)]) != phrase {
+				continue
+			}
+			for j, l := range initials {
+				letters[i+j] = preferFirst(letters[i+j], string(l))
+			}
+		}
+	}
+
+	abbrs := []string{""}
+	for _, uniq := range letters {
+		if len(uniq) == 0 {
+			continue
+		}
+		next := make([]string, 0, len(abbrs)*len(uniq))
+		for _, prefix := range abbrs {
+			for _, l := range uniq {
+				if len(next) < maxAbbreviations {
+					next = append(next, prefix+l)
+				}
+			}
+		}
+		abbrs = next
+	}
+	if len(abbrs) == 1 && abbrs[0] == "" {
+		return nil
+	}
+	return abbrs
+}
+
+// preferFirst 将 letter 移到读法列表最前面
+func preferFirst(letters []string, letter string) []string {
+	out := []string{letter}
+	for _, l := range letters {
+		if l != letter {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
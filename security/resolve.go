@@ -0,0 +1,173 @@
+package security
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// 匹配方式
+const (
+	MatchSymbol  = "symbol"  // 代码精确匹配
+	MatchName    = "name"    // 简称精确匹配
+	MatchAlias   = "alias"   // 别名精确匹配
+	MatchPinyin  = "pinyin"  // 拼音首字母匹配
+	MatchMention = "mention" // 输入文本中提到了简称或别名
+)
+
+// fuzzyConfidenceScale 模糊匹配的置信度折扣，保证模糊结果低于精确匹配
+const fuzzyConfidenceScale = 0.75
+
+// Candidate 证券解析候选
+type Candidate struct {
+	Security
+	Symbol     string  `json:"symbol"`     // 雪球格式代码，可直接传给搜索和行情工具
+	Confidence float64 `json:"confidence"` // 置信度 0-1
+	MatchedBy  string  `json:"matchedBy"`
+}
+
+var (
+	codeTokenPattern  = regexp.MustCompile(`(?i)\b(SH|SZ|BJ)?\d{5,6}(\.(SH|SZ|BJ|HK))?\b`)
+	latinTokenPattern = regexp.MustCompile(`\b[A-Za-z]{2,6}\b`)
+)
+
+// Resolve 解析输入中提到的证券，按置信度降序返回最多 limit 个候选
+// 输入可以是代码、简称、别名、拼音首字母，或包含这些内容的一句话
+func (m *Master) Resolve(query string, limit int) []Candidate {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	found := make(map[string]Candidate)
+	add := func(s *Security, confidence float64, matchedBy string) {
+		symbol := s.Symbol()
+		if old, ok := found[symbol]; ok && old.Confidence >= confidence {
+			return
+		}
+		found[symbol] = Candidate{Security: *s, Symbol: symbol, Confidence: confidence, MatchedBy: matchedBy}
+	}
+
+	// 整个输入精确匹配
+	upper := strings.ToUpper(query)
+	if s, ok := m.bySymbol[normalizeSymbol(upper)]; ok {
+		add(s, 1, MatchSymbol)
+	}
+	for _, s := range m.byCode[upper] {
+		add(s, 1, MatchSymbol)
+	}
+	for _, s := range m.byName[query] {
+		if s.Name == query {
+			add(s, 1, MatchName)
+		} else {
+			add(s, 0.95, MatchAlias)
+		}
+	}
+	for _, s := range m.byPinyin[upper] {
+		add(s, 0.85, MatchPinyin)
+	}
+
+	// 输入文本中出现的代码
+	for _, token := range codeTokenPattern.FindAllString(query, -1) {
+		token = strings.ToUpper(token)
+		if s, ok := m.bySymbol[normalizeSymbol(token)]; ok {
+			add(s, 0.95, MatchSymbol)
+		}
+		for _, s := range m.byCode[token] {
+			add(s, 0.9, MatchSymbol)
+		}
+	}
+	for _, token := range latinTokenPattern.FindAllString(query, -1) {
+		token = strings.ToUpper(token)
+		for _, s := range m.byCode[token] {
+			add(s, 0.9, MatchSymbol)
+		}
+		for _, s := range m.byPinyin[token] {
+			add(s, 0.7, MatchPinyin)
+		}
+	}
+
+	// 输入文本中提到的简称或别名，较短的名称被较长名称包含时只保留较长的
+	var mentioned []string
+	for name := range m.byName {
+		if utf8.RuneCountInString(name) >= 2 && name != query && strings.Contains(query, name) {
+			mentioned = append(mentioned, name)
+		}
+	}
+	for _, name := range mentioned {
+		if containedInOther(name, mentioned) {
+			continue
+		}
+		for _, s := range m.byName[name] {
+			if s.Name == name {
+				add(s, 0.9, MatchMention)
+			} else {
+				add(s, 0.85, MatchMention)
+			}
+		}
+	}
+
+	// 以上都未命中时模糊匹配，置信度打折，交由调用方决定是否需要进一步确认
+	if len(found) == 0 {
+		for _, c := range m.searchLocked(query, limit) {
+			add(&c.Security, fuzzyConfidenceScale*c.Confidence, MatchFuzzy)
+		}
+	}
+
+	candidates := make([]Candidate, 0, len(found))
+	for _, c := range found {
+		candidates = append(candidates, c)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Confidence != candidates[j].Confidence {
+			return candidates[i].Confidence > candidates[j].Confidence
+		}
+		return candidates[i].Symbol < candidates[j].Symbol
+	})
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates
+}
+
+// NormalizeSymbol 将股票代码统一为雪球格式：601288.SH、SH:601288 等写法转为 SH601288，
+// 0700.HK 补足为 00700，A股纯数字代码按代码推断交易所，美股代码转大写
+func NormalizeSymbol(symbol string) string {
+	symbol = normalizeSymbol(strings.TrimSpace(symbol))
+	if len(symbol) == 6 && strings.Trim(symbol, "0123456789") == "" {
+		if ex := InferExchange(symbol); ex != "" {
+			return ex + symbol
+		}
+	}
+	return symbol
+}
+
+// normalizeSymbol 将 601288.SH、SH:601288、0700.HK 等写法统一为雪球格式
+// 只处理数字代码的后缀，WISH 等以 SH 结尾的美股代码保持不变
+func normalizeSymbol(symbol string) string {
+	symbol = strings.NewReplacer(":", "", ".", "").Replace(strings.ToUpper(symbol))
+	isCode := func(code string) bool { return code != "" && strings.Trim(code, "0123456789") == "" }
+	for _, ex := range []string{ExchangeSH, ExchangeSZ, ExchangeBJ} {
+		if code, ok := strings.CutSuffix(symbol, ex); ok && isCode(code) {
+			return ex + code
+		}
+	}
+	if code, ok := strings.CutSuffix(symbol, ExchangeHK); ok && isCode(code) {
+		return padCode(code, ExchangeHK)
+	}
+	return symbol
+}
+
+// containedInOther 判断 name 是否为其他更长名称的一部分
+func containedInOther(name string, names []string) bool {
+	for _, other := range names {
+		if other != name && len(other) > len(name) && strings.Contains(other, name) {
+			return true
+		}
+	}
+	return false
+}
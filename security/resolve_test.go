@@ -0,0 +1,93 @@
+package security
+
+import "testing"
+
+func testMaster() *Master {
+	m := NewMaster()
+	m.Add(Security{Code: "601288", Exchange: ExchangeSH, Name: "农业银行", Aliases: []string{"农行"}})
+	m.Add(Security{Code: "601988", Exchange: ExchangeSH, Name: "中国银行"})
+	m.Add(Security{Code: "300750", Exchange: ExchangeSZ, Name: "宁德时代"})
+	m.Add(Security{Code: "00700", Exchange: ExchangeHK, Name: "腾讯控股", Aliases: []string{"腾讯"}})
+	m.Add(Security{Code: "AAPL", Exchange: ExchangeUS, Name: "苹果"})
+	m.Add(Security{Code: "WISH", Exchange: ExchangeUS, Name: "ContextLogic"})
+	return m
+}
+
+func TestResolve(t *testing.T) {
+	m := testMaster()
+	tests := []struct {
+		query      string
+		symbol     string
+		confidence float64
+		matchedBy  string
+	}{
+		{"SH601288", "SH601288", 1, MatchSymbol},
+		{"601288.sh", "SH601288", 1, MatchSymbol},
+		{"601288", "SH601288", 1, MatchSymbol},
+		{"0700.HK", "00700", 1, MatchSymbol},
+		{"wish", "WISH", 1, MatchSymbol},
+		{"农业银行", "SH601288", 1, MatchName},
+		{"农行", "SH601288", 0.95, MatchAlias},
+		{"nyyh", "SH601288", 0.85, MatchPinyin},
+		{"帮我看看 300750 的走势", "SZ300750", 0.9, MatchSymbol},
+		{"帮我看看 AAPL 的走势", "AAPL", 0.9, MatchSymbol},
+		{"宁德时代最近怎么样", "SZ300750", 0.9, MatchMention},
+		{"腾讯最近怎么样", "00700", 0.85, MatchMention},
+		// 错别字只能模糊匹配，置信度打折
+		{"宁德时待", "SZ300750", fuzzyConfidenceScale * 0.75, MatchFuzzy},
+	}
+	for _, tt := range tests {
+		candidates := m.Resolve(tt.query, 3)
+		if len(candidates) == 0 {
+			t.Errorf("Resolve(%q) 没有候选", tt.query)
+			continue
+		}
+		got := candidates[0]
+		if got.Symbol != tt.symbol || got.Confidence != tt.confidence || got.MatchedBy != tt.matchedBy {
+			t.Errorf("Resolve(%q) = {%s %.4f %s}，期望 {%s %.4f %s}", tt.query, got.Symbol, got.Confidence, got.MatchedBy, tt.symbol, tt.confidence, tt.matchedBy)
+		}
+	}
+}
+
+func TestResolveLimit(t *testing.T) {
+	m := testMaster()
+	if got := m.Resolve("  ", 3); got != nil {
+		t.Errorf("空输入应没有候选，得到 %v", got)
+	}
+	// 两只银行股得分相同，按代码排序后截取
+	candidates := m.Resolve("银行", 1)
+	if len(candidates) != 1 || candidates[0].Symbol != "SH601288" || candidates[0].MatchedBy != MatchFuzzy {
+		t.Errorf("Resolve(银行, 1) = %+v，期望只返回模糊匹配的 SH601288", candidates)
+	}
+	if candidates := m.Resolve("银行", 0); len(candidates) != 2 {
+		t.Errorf("limit 为0时应返回全部候选，得到 %d 个", len(candidates))
+	}
+}
+
+func TestNormalizeSymbol(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"SH601288", "SH601288"},
+		{"601288", "SH601288"},
+		{" 601288.sh ", "SH601288"},
+		{"SH:601288", "SH601288"},
+		{"000001", "SZ000001"},
+		{"300750.SZ", "SZ300750"},
+		{"830799", "BJ830799"},
+		{"0700.HK", "00700"},
+		{"700HK", "00700"},
+		{"00700", "00700"},
+		{"aapl", "AAPL"},
+		// 以交易所代码结尾的美股代码不能当成后缀
+		{"WISH", "WISH"},
+		{"PUSH", "PUSH"},
+		{"BJ", "BJ"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeSymbol(tt.in); got != tt.want {
+			t.Errorf("NormalizeSymbol(%q) = %q，期望 %q", tt.in, got, tt.want)
+		}
+	}
+}
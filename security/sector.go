@@ -0,0 +1,255 @@
+package security
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 板块类型
+const (
+	SectorIndustry = "行业"
+	SectorConcept  = "概念"
+)
+
+// Sector 板块及其成分股
+type Sector struct {
+	Name    string   `json:"name"`              // 板块名称，如 光伏、银行
+	Type    string   `json:"type,omitempty"`    // 板块类型：行业 或 概念
+	Aliases []string `json:"aliases,omitempty"` // 别名，如 太阳能
+	Symbols []string `json:"symbols"`           // 成分股雪球格式代码，按重要性降序
+}
+
+// sectorSuffixes 查询板块时忽略的后缀，如“银行板块”“光伏概念股”
+var sectorSuffixes = []string{"相关股票", "概念股", "板块", "概念", "行业", "个股"}
+
+// sectorColumnAliases 板块CSV表头别名
+var sectorColumnAliases = map[string][]string{
+	"sector":  {"sector", "name", "板块", "板块名称"},
+	"type":    {"type", "类型", "板块类型"},
+	"aliases": {"aliases", "alias", "别名"},
+	"symbols": {"symbols", "stocks", "成分股"},
+}
+
+// AddSector 添加板块，同名板块重复添加时后者覆盖前者
+// 成分股代码支持 601288、601288.SH、SH601288 等写法，统一为雪球格式
+func (m *Master) AddSector(s Sector) {
+	s.Name = strings.TrimSpace(s.Name)
+	s.Type = strings.TrimSpace(s.Type)
+	symbols := make([]string, 0, len(s.Symbols))
+	for _, symbol := range s.Symbols {
+		if symbol = NormalizeSymbol(symbol); symbol != "" && !contains(symbols, symbol) {
+			symbols = append(symbols, symbol)
+		}
+	}
+	s.Symbols = symbols
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, old := range m.sectors {
+		if old.Name == s.Name {
+			m.sectors[i] = &s
+			return
+		}
+	}
+	m.sectors = append(m.sectors, &s)
+}
+
+// Sectors 按名称或别名查询板块，精确匹配优先，其次为名称包含关系
+// 未配置匹配的板块时，按证券的所属行业聚合出行业板块
+func (m *Master) Sectors(query string) []Sector {
+	query = TrimSectorSuffix(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var exact, partial []Sector
+	for _, s := range m.sectors {
+		names := append([]string{s.Name}, s.Aliases...)
+		switch {
+		case contains(names, query):
+			exact = append(exact, *s)
+		case matchesAny(query, names):
+			partial = append(partial, *s)
+		}
+	}
+	if sectors := append(exact, partial...); len(sectors) > 0 {
+		return sectors
+	}
+
+	// 按所属行业聚合，行业名称相同的证券归为一个板块
+	byIndustry := make(map[string]*Sector)
+	var industries []string
+	for _, sec := range m.list {
+		if sec.Industry == "" || !strings.Contains(sec.Industry, query) {
+			continue
+		}
+		s, ok := byIndustry[sec.Industry]
+		if !ok {
+			s = &Sector{Name: sec.Industry, Type: SectorIndustry}
+			byIndustry[sec.Industry] = s
+			industries = append(industries, sec.Industry)
+		}
+		s.Symbols = append(s.Symbols, sec.Symbol())
+	}
+	sort.Strings(industries)
+	sectors := make([]Sector, 0, len(industries))
+	for _, industry := range industries {
+		sectors = append(sectors, *byIndustry[industry])
+	}
+	return sectors
+}
+
+// Constituents 返回板块成分股的证券信息，主数据中不存在的代码只填充代码
+func (m *Master) Constituents(s Sector) []Security {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	list := make([]Security, 0, len(s.Symbols))
+	for _, symbol := range s.Symbols {
+		if sec, ok := m.bySymbol[symbol]; ok {
+			list = append(list, *sec)
+			continue
+		}
+		list = append(list, symbolOnly(symbol))
+	}
+	return list
+}
+
+// LoadSectorPath 加载板块CSV文件，或目录下的全部 .csv 文件，返回加载的板块数
+func (m *Master) LoadSectorPath(path string) (int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("读取板块文件失败: %v", err)
+	}
+	files := []string{path}
+	if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "*.csv")); err != nil {
+			return 0, fmt.Errorf("读取板块目录失败: %v", err)
+		}
+		sort.Strings(files)
+	}
+
+	total := 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return total, fmt.Errorf("打开板块文件失败: %v", err)
+		}
+		if !utf8.Valid(data) {
+			if data, err = simplifiedchinese.GBK.NewDecoder().Bytes(data); err != nil {
+				return total, fmt.Errorf("板块文件 %s 既不是UTF-8也不是GBK编码: %v", file, err)
+			}
+		}
+		n, err := m.ReadSectorCSV(bytes.NewReader(data))
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("读取板块文件 %s 失败: %v", file, err)
+		}
+	}
+	return total, nil
+}
+
+// ReadSectorCSV 从CSV读取板块，返回读取的板块数
+// 表头为 sector,type,aliases,symbols，别名和成分股均以分号分隔，成分股按重要性降序排列
+func (m *Master) ReadSectorCSV(r io.Reader) (int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("读取表头失败: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for column, aliases := range sectorColumnAliases {
+			if _, ok := columns[column]; !ok && contains(aliases, name) {
+				columns[column] = i
+			}
+		}
+	}
+	for _, required := range []string{"sector", "symbols"} {
+		if _, ok := columns[required]; !ok {
+			return 0, fmt.Errorf("缺少列: %s", required)
+		}
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	split := func(text string) []string {
+		return strings.FieldsFunc(text, func(r rune) bool { return r == ';' || r == '；' })
+	}
+
+	count := 0
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, fmt.Errorf("第%d行: %v", line, err)
+		}
+		name := field(record, "sector")
+		if name == "" {
+			continue // 空行
+		}
+		s := Sector{
+			Name:    name,
+			Type:    field(record, "type"),
+			Aliases: split(field(record, "aliases")),
+			Symbols: split(field(record, "symbols")),
+		}
+		if len(s.Symbols) == 0 {
+			return count, fmt.Errorf("第%d行: 板块 %s 没有成分股", line, name)
+		}
+		m.AddSector(s)
+		count++
+	}
+	return count, nil
+}
+
+// symbolOnly 由雪球格式代码还原出只有代码和交易所的证券
+func symbolOnly(symbol string) Security {
+	for _, ex := range []string{ExchangeSH, ExchangeSZ, ExchangeBJ} {
+		if code, ok := strings.CutPrefix(symbol, ex); ok && strings.Trim(code, "0123456789") == "" {
+			return Security{Code: code, Exchange: ex}
+		}
+	}
+	return Security{Code: symbol, Exchange: InferExchange(symbol)}
+}
+
+// TrimSectorSuffix 去掉板块查询中“板块”“概念股”等后缀，去掉后为空时保留原文
+func TrimSectorSuffix(query string) string {
+	for _, suffix := range sectorSuffixes {
+		if trimmed, ok := strings.CutSuffix(query, suffix); ok && trimmed != "" {
+			return trimmed
+		}
+	}
+	return query
+}
+
+// matchesAny 判断查询与任一名称是否互相包含
+func matchesAny(query string, names []string) bool {
+	for _, name := range names {
+		if name != "" && (strings.Contains(name, query) || strings.Contains(query, name)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// sectorNames 返回板块名称，以逗号分隔
+func sectorNames(sectors []Sector) string {
+	names := make([]string, len(sectors))
+	for i, s := range sectors {
+		names[i] = s.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func TestSectors(t *testing.T) {
+	m := NewMaster()
+	m.Add(Security{Code: "601288", Exchange: ExchangeSH, Name: "农业银行", Industry: "银行"})
+	m.Add(Security{Code: "600036", Exchange: ExchangeSH, Name: "招商银行", Industry: "银行"})
+	m.Add(Security{Code: "601012", Exchange: ExchangeSH, Name: "隆基绿能", Industry: "光伏设备"})
+	m.Add(Security{Code: "300274", Exchange: ExchangeSZ, Name: "阳光电源", Industry: "电网设备"})
+	m.Add(Security{Code: "002129", Exchange: ExchangeSZ, Name: "TCL中环", Industry: "光伏设备"})
+	m.AddSector(Sector{Name: "光伏概念", Type: SectorConcept, Symbols: []string{"300274"}})
+	m.AddSector(Sector{Name: "光伏", Type: SectorIndustry, Aliases: []string{"太阳能"}, Symbols: []string{"601012.SH", "SZ002129", "601012"}})
+	m.AddSector(Sector{Name: "储能", Type: SectorConcept, Symbols: []string{"300274"}})
+
+	tests := []struct {
+		query, want string
+	}{
+		// 精确匹配排在包含匹配之前，不受添加顺序影响
+		{"光伏", "光伏,光伏概念"},
+		{"太阳能", "光伏"},
+		// 去掉“板块”“概念股”等后缀后再匹配
+		{"储能板块", "储能"},
+		{"光伏概念股", "光伏,光伏概念"},
+		// 没有配置的板块按证券的所属行业聚合
+		{"银行板块", "银行"},
+		{"设备", "光伏设备,电网设备"},
+		{"白酒", ""},
+		{"  ", ""},
+	}
+	for _, tt := range tests {
+		if got := sectorNames(m.Sectors(tt.query)); got != tt.want {
+			t.Errorf("Sectors(%q) = %q，期望 %q", tt.query, got, tt.want)
+		}
+	}
+
+	// 成分股统一为雪球格式并去重
+	if s := m.Sectors("光伏")[0]; strings.Join(s.Symbols, ",") != "SH601012,SZ002129" {
+		t.Errorf("光伏成分股 = %v", s.Symbols)
+	}
+	banks := m.Sectors("银行")[0]
+	if banks.Type != SectorIndustry || strings.Join(banks.Symbols, ",") != "SH601288,SH600036" {
+		t.Errorf("按行业聚合 = %+v", banks)
+	}
+
+	// 同名板块后添加的覆盖先添加的
+	m.AddSector(Sector{Name: "储能", Type: SectorConcept, Symbols: []string{"601012"}})
+	if s := m.Sectors("储能"); len(s) != 1 || strings.Join(s[0].Symbols, ",") != "SH601012" {
+		t.Errorf("覆盖后 = %+v", s)
+	}
+}
+
+func TestReadSectorCSV(t *testing.T) {
+	data := "\ufeff板块名称,板块类型,别名,成分股\n" +
+		"光伏,行业,太阳能；光伏发电,601012;SZ002129；300274.SZ\n" +
+		"\n" +
+		"银行,,,601288;601288.SH\n"
+	m := NewMaster()
+	n, err := m.ReadSectorCSV(strings.NewReader(data))
+	if err != nil || n != 2 {
+		t.Fatalf("ReadSectorCSV = %d %v，期望 2 个板块", n, err)
+	}
+	pv := m.Sectors("光伏发电")
+	if len(pv) != 1 || pv[0].Type != SectorIndustry || strings.Join(pv[0].Symbols, ",") != "SH601012,SZ002129,SZ300274" {
+		t.Errorf("光伏 = %+v", pv)
+	}
+	if banks := m.Sectors("银行"); len(banks) != 1 || strings.Join(banks[0].Symbols, ",") != "SH601288" {
+		t.Errorf("银行 = %+v", banks)
+	}
+	// 主数据中没有的成分股只填充代码和交易所
+	constituents := m.Constituents(pv[0])
+	if len(constituents) != 3 || constituents[0].Code != "601012" || constituents[0].Exchange != ExchangeSH || constituents[2].Exchange != ExchangeSZ {
+		t.Errorf("成分股 = %+v", constituents)
+	}
+
+	bad := []struct {
+		data, want string
+	}{
+		{"sector,type\n光伏,行业\n", "缺少列: symbols"},
+		{"sector,symbols\n光伏,601012\n储能,\n", "第3行: 板块 储能 没有成分股"},
+	}
+	for _, tt := range bad {
+		if _, err := NewMaster().ReadSectorCSV(strings.NewReader(tt.data)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ReadSectorCSV(%q) = %v，期望包含 %q", tt.data, err, tt.want)
+		}
+	}
+}
+
+func TestLoadSectorPathGBK(t *testing.T) {
+	dir := t.TempDir()
+	gbk, err := simplifiedchinese.GBK.NewEncoder().String("板块,成分股\n白酒,600519;000858\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"a_gbk.csv":  gbk,
+		"b_utf8.csv": "sector,symbols\n银行,601288\n",
+		"notes.txt":  "sector,symbols\n忽略,601988\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := NewMaster()
+	if n, err := m.LoadSectorPath(dir); err != nil || n != 2 {
+		t.Fatalf("LoadSectorPath = %d %v，期望 2 个板块", n, err)
+	}
+	if s := m.Sectors("白酒"); len(s) != 1 || strings.Join(s[0].Symbols, ",") != "SH600519,SZ000858" {
+		t.Errorf("GBK 编码的板块 = %+v", s)
+	}
+	if s := m.Sectors("忽略"); len(s) != 0 {
+		t.Errorf("非CSV文件不应加载: %+v", s)
+	}
+}
+
+func TestTrimSectorSuffix(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"银行板块", "银行"},
+		{"光伏概念股", "光伏"},
+		{"锂电池相关股票", "锂电池"},
+		{"半导体行业", "半导体"},
+		{"板块", "板块"},
+		{"银行", "银行"},
+	}
+	for _, tt := range tests {
+		if got := TrimSectorSuffix(tt.in); got != tt.want {
+			t.Errorf("TrimSectorSuffix(%q) = %q，期望 %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package security
+
+import (
+	"strings"
+	"sync"
+)
+
+// 交易所/市场代码
+const (
+	ExchangeSH = "SH" // 上海证券交易所
+	ExchangeSZ = "SZ" // 深圳证券交易所
+	ExchangeBJ = "BJ" // 北京证券交易所
+	ExchangeHK = "HK" // 香港交易所
+	ExchangeUS = "US" // 美股
+)
+
+// 市场
+const (
+	MarketA  = "A"  // A股（沪深北）
+	MarketHK = "HK" // 港股
+	MarketUS = "US" // 美股
+)
+
+// Security 证券基础信息
+type Security struct {
+	Code     string   `json:"code"`               // 证券代码，如 601288、00700、AAPL
+	Exchange string   `json:"exchange"`           // 交易所，如 SH、SZ、HK、US
+	Name     string   `json:"name"`               // 证券简称
+	Pinyin   string   `json:"pinyin"`             // 简称拼音首字母，如 NYYH，为空时自动生成
+	Industry string   `json:"industry,omitempty"` // 所属行业
+	ListDate string   `json:"listDate,omitempty"` // 上市日期，格式 2006-01-02
+	Aliases  []string `json:"aliases,omitempty"`
+}
+
+// Market 返回证券所属市场：A、HK 或 US
+func (s *Security) Market() string {
+	switch s.Exchange {
+	case ExchangeHK:
+		return MarketHK
+	case ExchangeUS:
+		return MarketUS
+	}
+	return MarketA
+}
+
+// Symbol 返回雪球格式的代码：A股为交易所前缀+代码，港股和美股为代码本身
+func (s *Security) Symbol() string {
+	switch s.Exchange {
+	case ExchangeSH, ExchangeSZ, ExchangeBJ:
+		return s.Exchange + s.Code
+	}
+	return s.Code
+}
+
+// Master 证券主数据，支持按代码、简称、拼音首字母和别名查询，以及按板块查询成分股
+type Master struct {
+	mu       sync.RWMutex
+	list     []*Security
+	bySymbol map[string]*Security
+	byCode   map[string][]*Security
+	byName   map[string][]*Security // 简称和别名
+	byPinyin map[string][]*Security
+	pinyin   map[*Security][]string // 每个证券的全部拼音首字母
+	sectors  []*Sector
+}
+
+// NewMaster 创建空的证券主数据
+func NewMaster() *Master {
+	return &Master{
+		bySymbol: make(map[string]*Security),
+		byCode:   make(map[string][]*Security),
+		byName:   make(map[string][]*Security),
+		byPinyin: make(map[string][]*Security),
+		pinyin:   make(map[*Security][]string),
+	}
+}
+
+// Add 添加证券，同一交易所同一代码重复添加时后者覆盖前者
+// 纯数字代码按交易所补足前导零，兼容 Excel 去掉前导零后的 700、1 等写法
+func (m *Master) Add(s Security) {
+	s.Exchange = strings.ToUpper(strings.TrimSpace(s.Exchange))
+	s.Code = padCode(strings.ToUpper(strings.TrimSpace(s.Code)), s.Exchange)
+	s.Name = strings.TrimSpace(s.Name)
+	s.Pinyin = strings.ToUpper(strings.TrimSpace(s.Pinyin))
+	if s.Pinyin == "" {
+		if abbrs := Abbreviations(s.Name); len(abbrs) > 0 {
+			s.Pinyin = abbrs[0]
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if old, ok := m.bySymbol[s.Symbol()]; ok {
+		m.removeLocked(old)
+	}
+	sec := &s
+	m.list = append(m.list, sec)
+	m.bySymbol[sec.Symbol()] = sec
+	m.byCode[sec.Code] = append(m.byCode[sec.Code], sec)
+	for _, name := range sec.names() {
+		m.byName[name] = append(m.byName[name], sec)
+	}
+	m.pinyin[sec] = sec.pinyinKeys()
+	for _, abbr := range m.pinyin[sec] {
+		m.byPinyin[abbr] = append(m.byPinyin[abbr], sec)
+	}
+}
+
+// padCode 为纯数字代码补足前导零：港股5位，A股6位，其他代码原样返回
+func padCode(code, exchange string) string {
+	if code == "" || strings.Trim(code, "0123456789") != "" {
+		return code
+	}
+	width := 0
+	switch exchange {
+	case ExchangeHK:
+		width = 5
+	case ExchangeSH, ExchangeSZ, ExchangeBJ:
+		width = 6
+	}
+	for len(code) < width {
+		code = "0" + code
+	}
+	return code
+}
+
+// Len 返回证券数量
+func (m *Master) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.list)
+}
+
+// All 返回全部证券
+func (m *Master) All() []Security {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	list := make([]Security, len(m.list))
+	for i, s := range m.list {
+		list[i] = *s
+	}
+	return list
+}
+
+// BySymbol 按雪球格式代码查询，如 SH601288、00700、AAPL
+func (m *Master) BySymbol(symbol string) (Security, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.bySymbol[strings.ToUpper(strings.TrimSpace(symbol))]
+	if !ok {
+		return Security{}, false
+	}
+	return *s, true
+}
+
+// ByCode 按证券代码查询，不同市场可能存在相同代码
+func (m *Master) ByCode(code string) []Security {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return copyList(m.byCode[strings.ToUpper(strings.TrimSpace(code))])
+}
+
+// ByName 按简称或别名精确查询
+func (m *Master) ByName(name string) []Security {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return copyList(m.byName[strings.TrimSpace(name)])
+}
+
+// ByPinyin 按拼音首字母精确查询（不区分大小写）
+func (m *Master) ByPinyin(abbr string) []Security {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return copyList(m.byPinyin[strings.ToUpper(strings.TrimSpace(abbr))])
+}
+
+// ByMarket 返回指定市场（A、HK、US）的全部证券
+func (m *Master) ByMarket(market string) []Security {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	market = strings.ToUpper(strings.TrimSpace(market))
+	var list []Security
+	for _, s := range m.list {
+		if s.Market() == market {
+			list = append(list, *s)
+		}
+	}
+	return list
+}
+
+// ByIndustry 返回所属行业包含 industry 的全部证券
+func (m *Master) ByIndustry(industry string) []Security {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	industry = strings.TrimSpace(industry)
+	var list []Security
+	for _, s := range m.list {
+		if industry != "" && strings.Contains(s.Industry, industry) {
+			list = append(list, *s)
+		}
+	}
+	return list
+}
+
+// names 返回简称和全部别名
+func (s *Security) names() []string {
+	names := make([]string, 0, 1+len(s.Aliases))
+	if s.Name != "" {
+		names = append(names, s.Name)
+	}
+	for _, alias := range s.Aliases {
+		if alias = strings.TrimSpace(alias); alias != "" && alias != s.Name {
+			names = append(names, alias)
+		}
+	}
+	return names
+}
+
+// pinyinKeys 返回用于索引的拼音首字母：显式配置的拼音加上简称和别名的各种多音字读法
+func (s *Security) pinyinKeys() []string {
+	seen := make(map[string]bool)
+	var keys []string
+	add := func(abbr string) {
+		if abbr != "" && !seen[abbr] {
+			seen[abbr] = true
+			keys = append(keys, abbr)
+		}
+	}
+	add(s.Pinyin)
+	for _, name := range s.names() {
+		for _, abbr := range Abbreviations(name) {
+			add(abbr)
+		}
+	}
+	return keys
+}
+
+func (m *Master) removeLocked(old *Security) {
+	m.list = removeFrom(m.list, old)
+	delete(m.bySymbol, old.Symbol())
+	m.byCode[old.Code] = removeFrom(m.byCode[old.Code], old)
+	for _, name := range old.names() {
+		m.byName[name] = removeFrom(m.byName[name], old)
+	}
+	for _, abbr := range m.pinyin[old] {
+		m.byPinyin[abbr] = removeFrom(m.byPinyin[abbr], old)
+	}
+	delete(m.pinyin, old)
+}
+
+func removeFrom(list []*Security, target *Security) []*Security {
+	out := list[:0]
+	for _, s := range list {
+		if s != target {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func copyList(list []*Security) []Security {
+	out := make([]Security, len(list))
+	for i, s := range list {
+		out[i] = *s
+	}
+	return out
+}
@@ -1,34 +1,175 @@
 package tools
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
+
+	"stock_agent/security"
 
 	"github.com/firebase/genkit/go/ai"
 	"github.com/firebase/genkit/go/genkit"
 )
 
 type AnalyzeInput struct {
-	Keyword string `json:"keyword" jsonschema_description:"用户想要分析的板块关键词，通过先查询板块，再查出板块内的股票，再分析股票"`
+	Keyword string `json:"keyword" jsonschema_description:"用户输入中提到的股票名称、代码、拼音首字母或整句话；查询板块及其成分股请使用 searchSector"`
+}
+
+const (
+	// maxResolveCandidates 最多返回的候选个股数量
+	maxResolveCandidates = 3
+	// minResolveConfidence 本地解析的最高置信度低于该值时交给大模型判断
+	minResolveConfidence = 0.8
+	// matchLLM 由大模型识别出的候选
+	matchLLM = "llm"
+)
+
+var globalSecurities *security.Master
+
+// SetSecurityMaster 设置全局证券主数据（供代码解析和搜索工具使用）
+func SetSecurityMaster(m *security.Master) {
+	globalSecurities = m
+}
+
+func getSecurityMaster() *security.Master {
+	return globalSecurities
 }
 
-func Analyze(ctx *ai.ToolContext, input AnalyzeInput) (string, error) {
+// Analyze 解析用户输入中提到的个股，返回带置信度的候选列表（Genkit Tool）
+func Analyze(ctx *ai.ToolContext, input AnalyzeInput) ([]security.Candidate, error) {
 	fmt.Printf("分析用户输入: %s\n", input.Keyword)
+
+	// 优先使用本地证券主数据解析
+	var candidates []security.Candidate
+	if m := getSecurityMaster(); m != nil {
+		candidates = m.Resolve(input.Keyword, maxResolveCandidates)
+	}
+	if len(candidates) > 0 && candidates[0].Confidence >= minResolveConfidence {
+		log.Printf("本地解析用户输入结果: %v", candidateSymbols(candidates))
+		return candidates, nil
+	}
+
+	// 本地无法确定时，交给大模型识别股票名称，再回到主数据解析代码
+	llmCandidates, err := resolveWithLLM(ctx, input.Keyword)
+	if err != nil {
+		if len(candidates) > 0 {
+			log.Printf("大模型解析失败，返回本地候选: %v", err)
+			return candidates, nil
+		}
+		return nil, err
+	}
+	candidates = mergeCandidates(candidates, llmCandidates)
+	if len(candidates) > maxResolveCandidates {
+		candidates = candidates[:maxResolveCandidates]
+	}
+	log.Printf("分析用户输入结果: %v", candidateSymbols(candidates))
+	return candidates, nil
+}
+
+// resolveWithLLM 让大模型识别输入中的股票，并尽量映射到本地主数据
+func resolveWithLLM(ctx *ai.ToolContext, keyword string) ([]security.Candidate, error) {
 	g := getGenkitInstance()
 	if g == nil {
-		return "", fmt.Errorf("genkit实例未初始化")
+		return nil, fmt.Errorf("genkit实例未初始化")
 	}
 
-	prompt := fmt.Sprintf(`从用户的输入中，分析用户想要了解哪些股票,并返回股票列表,最多返回三个,不要返回任何其他内容。
-	用户输入: %s`, input.Keyword)
+	prompt := fmt.Sprintf(`从用户的输入中，分析用户想要了解哪些股票,最多返回三个。
+	只返回JSON数组，不要返回任何其他内容，每个元素形如 {"name":"股票简称","code":"股票代码"}，不确定代码时 code 留空。
+	用户输入: %s`, keyword)
 	resp, err := genkit.Generate(ctx, g,
 		ai.WithModelName("xiaomimimo/mimo-v2-flash"),
 		ai.WithMessages(ai.NewUserMessage(ai.NewTextPart(prompt))),
 		ai.WithMaxTurns(1),
 	)
 	if err != nil {
-		return "", fmt.Errorf("分析失败: %v", err)
+		return nil, fmt.Errorf("分析失败: %v", err)
+	}
+	text := resp.Text()
+	log.Printf("大模型解析用户输入结果: %s", text)
+
+	// 去掉可能的markdown代码块
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(text, "```json")
+	text = strings.Trim(text, "`\n ")
+	var stocks []struct {
+		Name string `json:"name"`
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal([]byte(text), &stocks); err != nil {
+		return nil, fmt.Errorf("解析大模型返回结果失败: %v", err)
+	}
+
+	m := getSecurityMaster()
+	var candidates []security.Candidate
+	for _, stock := range stocks {
+		var resolved []security.Candidate
+		if m != nil {
+			if stock.Code != "" {
+				resolved = m.Resolve(stock.Code, 1)
+			}
+			if len(resolved) == 0 && stock.Name != "" {
+				resolved = m.Resolve(stock.Name, 1)
+			}
+		}
+		if len(resolved) > 0 {
+			c := resolved[0]
+			c.MatchedBy = matchLLM
+			c.Confidence = 0.7 * c.Confidence
+			candidates = append(candidates, c)
+			continue
+		}
+		// 主数据中不存在，保留大模型给出的名称和代码
+		candidates = append(candidates, security.Candidate{
+			Security:   security.Security{Code: stock.Code, Name: stock.Name},
+			Symbol:     normalizeXqSymbol(stock.Code),
+			Confidence: 0.5,
+			MatchedBy:  matchLLM,
+		})
+	}
+	return candidates, nil
+}
+
+// mergeCandidates 合并两组候选，同一代码保留置信度较高的一条
+func mergeCandidates(a, b []security.Candidate) []security.Candidate {
+	merged := make([]security.Candidate, 0, len(a)+len(b))
+	index := make(map[string]int)
+	for _, c := range append(append([]security.Candidate{}, a...), b...) {
+		key := c.Symbol
+		if key == "" {
+			key = c.Name
+		}
+		if i, ok := index[key]; ok {
+			if c.Confidence > merged[i].Confidence {
+				merged[i] = c
+			}
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, c)
+	}
+	// 按置信度降序
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Confidence > merged[j].Confidence
+	})
+	return merged
+}
+
+func candidateSymbols(candidates []security.Candidate) []string {
+	symbols := make([]string, len(candidates))
+	for i, c := range candidates {
+		symbols[i] = fmt.Sprintf("%s(%s %.2f)", c.Name, c.Symbol, c.Confidence)
+	}
+	return symbols
+}
+
+// keywordForSymbol 返回代码对应的证券简称，主数据中不存在时返回代码本身
+func keywordForSymbol(symbol string) string {
+	if m := getSecurityMaster(); m != nil {
+		if s, ok := m.BySymbol(normalizeXqSymbol(symbol)); ok {
+			return s.Name
+		}
 	}
-	log.Printf("分析用户输入结果: %s", resp.Text())
-	return resp.Text(), nil
+	return symbol
 }
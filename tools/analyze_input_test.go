@@ -0,0 +1,132 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"stock_agent/security"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// fakeModel 以固定回复代替大模型，记录调用次数
+type fakeModel struct {
+	reply string
+	err   error
+	calls int
+}
+
+// useFakeModel 测试期间用 fakeModel 代替解析用户输入的大模型
+func useFakeModel(t *testing.T) *fakeModel {
+	t.Helper()
+	fake := &fakeModel{}
+	g := genkit.Init(context.Background())
+	genkit.DefineModel(g, "xiaomimimo/mimo-v2-flash", &ai.ModelOptions{Supports: &ai.ModelSupports{Multiturn: true}},
+		func(_ context.Context, req *ai.ModelRequest, _ ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			fake.calls++
+			if fake.err != nil {
+				return nil, fake.err
+			}
+			return &ai.ModelResponse{Request: req, Message: ai.NewModelTextMessage(fake.reply)}, nil
+		})
+	oldGenkit := getGenkitInstance()
+	t.Cleanup(func() { SetGenkitInstance(oldGenkit) })
+	SetGenkitInstance(g)
+	return fake
+}
+
+func TestAnalyze(t *testing.T) {
+	oldMaster := getSecurityMaster()
+	t.Cleanup(func() { SetSecurityMaster(oldMaster) })
+	m := security.NewMaster()
+	m.Add(security.Security{Code: "601288", Exchange: security.ExchangeSH, Name: "农业银行", Aliases: []string{"农行"}})
+	m.Add(security.Security{Code: "601988", Exchange: security.ExchangeSH, Name: "中国银行"})
+	m.Add(security.Security{Code: "300750", Exchange: security.ExchangeSZ, Name: "宁德时代"})
+	SetSecurityMaster(m)
+	fake := useFakeModel(t)
+	ctx := &ai.ToolContext{Context: context.Background()}
+
+	type want struct {
+		symbol     string
+		confidence float64
+		matchedBy  string
+	}
+	tests := []struct {
+		name    string
+		keyword string
+		reply   string
+		err     error
+		llm     bool // 是否调用大模型
+		want    []want
+	}{
+		// 本地解析置信度不低于 0.8 时不调用大模型
+		{"名称", "农业银行", "", nil, false, []want{{"SH601288", 1, security.MatchName}}},
+		{"别名", "农行", "", nil, false, []want{{"SH601288", 0.95, security.MatchAlias}}},
+		{"拼音", "nyyh", "", nil, false, []want{{"SH601288", 0.85, security.MatchPinyin}}},
+		// 模糊匹配低于 0.8，交给大模型；主数据中的股票置信度按 0.7 折算，与本地候选合并后按置信度排序
+		{"模糊匹配", "宁德时待", "```json\n[{\"name\":\"宁德时代\",\"code\":\"300750\"}]\n```", nil, true,
+			[]want{{"SZ300750", 0.7, matchLLM}}},
+		{"本地没有结果", "那个做电池的龙头", `[{"name":"宁德时代","code":""},{"name":"比亚迪","code":"002594"}]`, nil, true,
+			[]want{{"SZ300750", 0.7, matchLLM}, {"SZ002594", 0.5, matchLLM}}},
+		// 大模型失败时退回本地候选
+		{"大模型失败", "宁德时待", "", errors.New("请求超时"), true, []want{{"SZ300750", 0.5625, security.MatchFuzzy}}},
+		{"返回不是JSON", "宁德时待", "抱歉，我无法确定", nil, true, []want{{"SZ300750", 0.5625, security.MatchFuzzy}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake.reply, fake.err, fake.calls = tt.reply, tt.err, 0
+			candidates, err := Analyze(ctx, AnalyzeInput{Keyword: tt.keyword})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if called := fake.calls > 0; called != tt.llm {
+				t.Errorf("调用大模型 = %v，期望 %v", called, tt.llm)
+			}
+			if len(candidates) != len(tt.want) {
+				t.Fatalf("候选 %v，期望 %d 个", candidateSymbols(candidates), len(tt.want))
+			}
+			for i, w := range tt.want {
+				c := candidates[i]
+				if c.Symbol != w.symbol || c.MatchedBy != w.matchedBy || c.Confidence < w.confidence-1e-9 || c.Confidence > w.confidence+1e-9 {
+					t.Errorf("候选 %d = {%s %.4f %s}，期望 {%s %.4f %s}", i, c.Symbol, c.Confidence, c.MatchedBy, w.symbol, w.confidence, w.matchedBy)
+				}
+			}
+		})
+	}
+
+	// 本地没有候选且大模型失败时返回错误
+	fake.reply, fake.err = "", errors.New("请求超时")
+	if _, err := Analyze(ctx, AnalyzeInput{Keyword: "那个做电池的龙头"}); err == nil {
+		t.Error("本地没有候选且大模型失败时应返回错误")
+	}
+}
+
+func TestMergeCandidates(t *testing.T) {
+	local := []security.Candidate{
+		{Symbol: "SZ300750", Confidence: 0.5625, MatchedBy: security.MatchFuzzy},
+		{Symbol: "SH601988", Confidence: 0.45, MatchedBy: security.MatchFuzzy},
+	}
+	llm := []security.Candidate{
+		{Symbol: "SZ300750", Confidence: 0.7, MatchedBy: matchLLM},
+		{Security: security.Security{Name: "某未上市公司"}, Confidence: 0.5, MatchedBy: matchLLM},
+		{Security: security.Security{Name: "某未上市公司"}, Confidence: 0.4, MatchedBy: matchLLM},
+	}
+	merged := mergeCandidates(local, llm)
+	// 同一代码保留置信度高的一条，没有代码时按名称去重
+	got := make([]string, len(merged))
+	for i, c := range merged {
+		got[i] = c.Symbol + c.Name + "/" + c.MatchedBy
+	}
+	want := []string{"SZ300750/llm", "某未上市公司/llm", "SH601988/fuzzy"}
+	if len(got) != len(want) {
+		t.Fatalf("合并结果 %v，期望 %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("合并结果 %v，期望 %v", got, want)
+			break
+		}
+	}
+}
@@ -8,22 +8,28 @@ import (
 	"strings"
 	"time"
 
+	"stock_agent/kline"
+
 	"github.com/firebase/genkit/go/ai"
 	"github.com/firebase/genkit/go/genkit"
 )
 
 // AnalyzeNewsInput 分析新闻的输入参数
 type AnalyzeNewsInput struct {
-	Keyword   string     `json:"keyword" jsonschema_description:"股票关键词，例如：腾讯、阿里巴巴、AAPL等"`
-	NewsItems []NewsItem `json:"newsItems" jsonschema_description:"要分析的新闻列表，必须是数组格式，每个元素包含title、content、url、time字段"`
+	Keyword   string         `json:"keyword" jsonschema_description:"股票关键词，例如：腾讯、阿里巴巴、AAPL等"`
+	NewsItems []NewsItem     `json:"newsItems" jsonschema_description:"要分析的新闻列表，必须是数组格式，每个元素包含title、content、url、time字段，搜索工具返回的source、publishedAt、author、tags等字段请原样保留"`
+	Quote     *QuoteSnapshot `json:"quote,omitempty" jsonschema_description:"可选，xqQuote 工具返回的行情快照，原样传入即可"`
+	Symbol    string         `json:"symbol,omitempty" jsonschema_description:"可选，股票代码，如 SH601288；提供时自动获取日K线和最近4个报告期的财务指标，将近期走势、技术指标和基本面纳入分析"`
 }
 
 // UnmarshalJSON 自定义反序列化，处理类型错误
 func (a *AnalyzeNewsInput) UnmarshalJSON(data []byte) error {
 	// 使用 interface{} 来接收原始数据，避免类型检查失败
 	aux := &struct {
-		Keyword   interface{} `json:"keyword"`
-		NewsItems interface{} `json:"newsItems"`
+		Keyword   interface{}     `json:"keyword"`
+		NewsItems interface{}     `json:"newsItems"`
+		Quote     json.RawMessage `json:"quote"`
+		Symbol    interface{}     `json:"symbol"`
 	}{}
 	
 	if err := json.Unmarshal(data, &aux); err != nil {
@@ -39,6 +45,29 @@ func (a *AnalyzeNewsInput) UnmarshalJSON(data []byte) error {
 		}
 	}
 	
+	// 处理 symbol - 兼容数字形式的A股代码
+	switch symbol := aux.Symbol.(type) {
+	case string:
+		a.Symbol = symbol
+	case float64:
+		a.Symbol = fmt.Sprintf("%06.0f", symbol)
+	}
+
+	// 处理 quote - 兼容对象和JSON字符串两种形式
+	if len(aux.Quote) > 0 && string(aux.Quote) != "null" {
+		raw := aux.Quote
+		var quoteStr string
+		if err := json.Unmarshal(raw, &quoteStr); err == nil {
+			raw = []byte(quoteStr)
+		}
+		var quote QuoteSnapshot
+		if err := json.Unmarshal(raw, &quote); err != nil {
+			log.Printf("解析 quote 失败，已忽略: %v", err)
+		} else {
+			a.Quote = &quote
+		}
+	}
+
 	// 处理 newsItems - 处理各种可能的类型
 	if aux.NewsItems == nil {
 		a.NewsItems = []NewsItem{}
@@ -50,63 +79,121 @@ func (a *AnalyzeNewsInput) UnmarshalJSON(data []byte) error {
 		NewsItems []NewsItem `json:"newsItems"`
 	}
 	if err := json.Unmarshal(data, &tempInput); err == nil && len(tempInput.NewsItems) > 0 {
-		a.NewsItems = tempInput.NewsItems
+		a.NewsItems = normalizeNewsItems(tempInput.NewsItems)
 		return nil
 	}
 	
-	// 尝试处理 []interface{}
+	// 如果是字符串，尝试解析为 JSON
+	if itemsStr, ok := aux.NewsItems.(string); ok {
+		log.Printf("检测到 newsItems 是字符串，尝试解析 JSON")
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(itemsStr), &parsed); err != nil {
+			log.Printf("解析 newsItems JSON 字符串失败: %v", err)
+			// 如果解析失败，返回空数组而不是错误，让函数处理
+			a.NewsItems = []NewsItem{}
+			return nil
+		}
+		aux.NewsItems = parsed
+	}
+	
+	// 尝试处理 []interface{}，逐个字段宽松解析，兼容只有 title、content、url、time 的旧格式
 	if itemsArray, ok := aux.NewsItems.([]interface{}); ok {
 		parsedItems := make([]NewsItem, 0, len(itemsArray))
 		for i, item := range itemsArray {
 			if itemMap, ok := item.(map[string]interface{}); ok {
-				newsItem := NewsItem{}
-				if title, ok := itemMap["title"].(string); ok {
-					newsItem.Title = title
-				}
-				if content, ok := itemMap["content"].(string); ok {
-					newsItem.Content = content
-				}
-				if url, ok := itemMap["url"].(string); ok {
-					newsItem.URL = url
-				}
-				if time, ok := itemMap["time"].(string); ok {
-					newsItem.Time = time
-				}
-				parsedItems = append(parsedItems, newsItem)
+				parsedItems = append(parsedItems, newsItemFromMap(itemMap))
 			} else {
 				log.Printf("跳过无效的新闻项 %d: %v", i, item)
 			}
 		}
-		a.NewsItems = parsedItems
+		a.NewsItems = normalizeNewsItems(parsedItems)
+		log.Printf("成功解析 %d 条新闻", len(a.NewsItems))
 		return nil
 	}
 	
-	// 如果是字符串，尝试解析为 JSON
-	if itemsStr, ok := aux.NewsItems.(string); ok {
-		log.Printf("检测到 newsItems 是字符串，尝试解析 JSON")
-		var parsedItems []NewsItem
-		if err := json.Unmarshal([]byte(itemsStr), &parsedItems); err == nil {
-			a.NewsItems = parsedItems
-			log.Printf("成功解析 %d 条新闻", len(a.NewsItems))
-			return nil
-		} else {
-			log.Printf("解析 newsItems JSON 字符串失败: %v", err)
-			// 如果解析失败，返回空数组而不是错误，让函数处理
-			a.NewsItems = []NewsItem{}
-			return nil
-		}
-	}
-	
 	// 如果都不匹配，返回错误
 	return fmt.Errorf("newsItems 必须是数组格式，当前类型: %T", aux.NewsItems)
 }
 
+// newsItemFromMap 从任意JSON对象中读取新闻项字段，类型不符的字段忽略
+func newsItemFromMap(m map[string]interface{}) NewsItem {
+	str := func(key string) string {
+		switch v := m[key].(type) {
+		case string:
+			return v
+		case float64:
+			return fmt.Sprint(v)
+		}
+		return ""
+	}
+	num := func(key string) int {
+		switch v := m[key].(type) {
+		case float64:
+			return int(v)
+		case string:
+			return parseGubaCount(v)
+		}
+		return 0
+	}
+	list := func(key string) []string {
+		var values []string
+		switch v := m[key].(type) {
+		case []interface{}:
+			for _, item := range v {
+				if s, ok := item.(string); ok && s != "" {
+					values = append(values, s)
+				}
+			}
+		case string:
+			for _, s := range strings.Split(v, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					values = append(values, s)
+				}
+			}
+		}
+		return values
+	}
+
+	item := NewsItem{
+		Title:        str("title"),
+		Content:      str("content"),
+		URL:          str("url"),
+		Time:         str("time"),
+		Source:       str("source"),
+		SourceType:   str("sourceType"),
+		Author:       str("author"),
+		Symbols:      list("symbols"),
+		Tags:         list("tags"),
+		ReadCount:    num("readCount"),
+		CommentCount: num("commentCount"),
+		ClusterSize:  num("clusterSize"),
+		RelatedURLs:  list("relatedUrls"),
+		Sources:      list("sources"),
+	}
+	if t, ok := parseNewsTime(str("publishedAt")); ok {
+		item.PublishedAt = t
+	}
+	if t, ok := parseNewsTime(str("crawledAt")); ok {
+		item.CrawledAt = t
+	}
+	return item
+}
+
+// normalizeNewsItems 补齐新闻项的 time 和 publishedAt
+func normalizeNewsItems(items []NewsItem) []NewsItem {
+	for i := range items {
+		items[i].normalize()
+	}
+	return items
+}
+
 // AnalyzeStockNews 分析股票新闻（Genkit Tool）
 func AnalyzeStockNews(ctx *ai.ToolContext, input AnalyzeNewsInput) (string, error) {
 	log.Printf("开始分析新闻: %s, 收到 %d 条新闻", input.Keyword, len(input.NewsItems))
 	
+	warnings := recentSourceWarnings(newsSources(input.NewsItems)...)
 	if len(input.NewsItems) == 0 {
-		return "未找到相关新闻，建议先使用 searchStockNews 或 xqSearchStock 工具搜索新闻，然后再进行分析。", nil
+		return withSourceWarnings("未找到相关新闻，建议先使用 searchStockNews 或 xqSearchStock 工具搜索新闻，然后再进行分析。", warnings), nil
 	}
 
 	g := getGenkitInstance()
@@ -117,6 +204,38 @@ func AnalyzeStockNews(ctx *ai.ToolContext, input AnalyzeNewsInput) (string, erro
 	// 收集所有新闻内容，构建提示词
 	var newsContent strings.Builder
 	fmt.Fprintf(&newsContent, "请分析以下关于 %s 股票的新闻，并生成一份专业的分析报告。\n\n", input.Keyword)
+	if input.Quote != nil {
+		fmt.Fprintf(&newsContent, "%s\n", formatQuoteSnapshot(input.Quote))
+	}
+	if input.Symbol != "" {
+		// 多取K线用于计算指标，走势摘要只展示最近一段
+		history, err := loadPriceHistory(ctx, kline.Query{Symbol: normalizeXqSymbol(input.Symbol), Period: kline.Day, Count: indicatorBarCount})
+		if err != nil {
+			log.Printf("获取K线失败，分析时不附带走势: %v", err)
+		} else {
+			recent := *history
+			if len(recent.Bars) > analyzePriceBarCount {
+				recent.Bars = recent.Bars[len(recent.Bars)-analyzePriceBarCount:]
+			}
+			fmt.Fprintf(&newsContent, "%s\n", formatPriceAction(&recent))
+			if report := computeIndicators(history); report != nil {
+				fmt.Fprintf(&newsContent, "%s\n", formatIndicatorReport(report))
+			}
+		}
+		if financials, err := loadFinancials(ctx, normalizeXqSymbol(input.Symbol), analyzeFinancialQuarters); err != nil {
+			log.Printf("获取财务数据失败，分析时不附带基本面: %v", err)
+		} else {
+			fmt.Fprintf(&newsContent, "%s\n", formatFinancialTable(financials))
+		}
+	}
+	// 合并不同来源转载的相同新闻，避免重复计算
+	if deduped := dedupNewsItems(input.NewsItems); len(deduped) < len(input.NewsItems) {
+		log.Printf("新闻去重: %d 条合并为 %d 条", len(input.NewsItems), len(deduped))
+		input.NewsItems = deduped
+	}
+	if text := formatSourceWarnings(warnings); text != "" {
+		fmt.Fprintf(&newsContent, "%s\n", text)
+	}
 	fmt.Fprintf(&newsContent, "共收集到 %d 条相关新闻：\n\n", len(input.NewsItems))
 
 	// 限制每条新闻的内容长度，避免超出token限制
@@ -128,15 +247,36 @@ func AnalyzeStockNews(ctx *ai.ToolContext, input AnalyzeNewsInput) (string, erro
 
 	for i, item := range input.NewsItems {
 		fmt.Fprintf(&newsContent, "新闻 %d:\n", i+1)
+		if item.SourceType == sourceTypeForum {
+			fmt.Fprintf(&newsContent, "类型: 股吧帖子（散户观点，阅读 %d，评论 %d）\n", item.ReadCount, item.CommentCount)
+		}
 		fmt.Fprintf(&newsContent, "标题: %s\n", item.Title)
+		if label := item.sourceLabel(); label != "" {
+			fmt.Fprintf(&newsContent, "来源: %s\n", label)
+		}
+		if item.Author != "" {
+			fmt.Fprintf(&newsContent, "作者: %s\n", item.Author)
+		}
+		if len(item.Tags) > 0 {
+			fmt.Fprintf(&newsContent, "标签: %s\n", strings.Join(item.Tags, "、"))
+		}
 		fmt.Fprintf(&newsContent, "URL: %s\n", item.URL)
+		if item.ClusterSize > 1 {
+			names := make([]string, 0, len(item.Sources))
+			for _, source := range item.Sources {
+				names = append(names, sourceName(source))
+			}
+			fmt.Fprintf(&newsContent, "传播: 共 %d 条相似报道，来源: %s\n", item.ClusterSize, strings.Join(names, "、"))
+			if len(item.RelatedURLs) > 0 {
+				fmt.Fprintf(&newsContent, "相似报道URL: %s\n", strings.Join(item.RelatedURLs, " "))
+			}
+		}
+		if item.Time != "" {
+			fmt.Fprintf(&newsContent, "发布时间: %s\n", item.Time)
+		}
 		if item.Content != "" {
 			// 限制每条新闻内容长度（保留前1000字符，减少token使用）
-			content := item.Content
-			if len(content) > 1000 {
-				content = content[:1000] + "..."
-			}
-			fmt.Fprintf(&newsContent, "内容摘要: %s\n", content)
+			fmt.Fprintf(&newsContent, "内容摘要: %s\n", truncateRunes(item.Content, 1000))
 		}
 		fmt.Fprintf(&newsContent, "\n")
 	}
@@ -145,11 +285,15 @@ func AnalyzeStockNews(ctx *ai.ToolContext, input AnalyzeNewsInput) (string, erro
 	prompt := fmt.Sprintf(`你是一位专业的股票分析师。请基于以下新闻内容，输出一份详细的股票分析报告，采用markdown格式。
 
 要求：
-1. 分析市场情绪（正面、负面、中性）
-2. 总结关键信息点，并列出相关新闻的URL和段落摘要
-3. 评估潜在风险和机会
-4. 给出投资建议（仅供参考）
-5. 使用中文回答，格式清晰易读
+1. 分析市场情绪（正面、负面、中性），如提供了行情快照、近期走势或技术指标，请结合价格、涨跌幅、成交量和估值等数据，引用指标时使用给出的读数
+2. 如提供了财务指标，请评述营收和利润增速、盈利能力、负债和现金流情况
+3. 总结关键信息点，并列出相关新闻的URL和段落摘要
+4. 标注为股吧帖子的内容代表散户观点，仅用于衡量散户情绪和关注度（可参考阅读数、评论数），不作为事实依据，应与专业新闻分开评述
+5. 标注了传播信息的新闻已合并多个来源的相似报道，不要重复计算；相似报道数和来源越多说明传播越广，可作为市场关注度的参考
+6. 评估潜在风险和机会
+7. 给出投资建议（仅供参考）
+8. 如提供了数据源告警，说明哪些来源的数据可能缺失，并相应降低相关结论的确定性
+9. 使用中文回答，格式清晰易读
 
 新闻内容：
 %s
@@ -170,7 +314,7 @@ func AnalyzeStockNews(ctx *ai.ToolContext, input AnalyzeNewsInput) (string, erro
 		return "", fmt.Errorf("AI分析失败: %v", err)
 	}
 
-	analysis := resp.Text()
+	analysis := withSourceWarnings(resp.Text(), warnings)
 	log.Printf("AI分析结果: %s\n", analysis)
 	return analysis, nil
 }
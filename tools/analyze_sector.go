@@ -0,0 +1,187 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// AnalyzeSectorInput 板块分析的输入参数
+type AnalyzeSectorInput struct {
+	Keyword   string `json:"keyword" jsonschema_description:"板块或主题关键词，例如：光伏、银行板块"`
+	TopN      int    `json:"topN,omitempty" jsonschema_description:"分析板块内排名靠前的成分股数量，默认5，最多10"`
+	NewsCount int    `json:"newsCount,omitempty" jsonschema_description:"每只成分股获取的电报条数，默认5，最多20"`
+}
+
+const (
+	defaultSectorTopN      = 5
+	maxSectorTopN          = 10
+	defaultSectorNewsCount = 5
+	maxSectorNewsCount     = 20
+)
+
+// sectorStockData 单只成分股收集到的行情和新闻
+type sectorStockData struct {
+	Stock SectorStock
+	Quote *QuoteSnapshot
+	News  []NewsItem
+}
+
+// AnalyzeSector 分析板块内排名靠前的成分股，生成板块整体分析报告（Genkit Tool）
+func AnalyzeSector(ctx *ai.ToolContext, input AnalyzeSectorInput) (string, error) {
+	if input.Keyword == "" {
+		return "", fmt.Errorf("keyword 不能为空")
+	}
+	topN := input.TopN
+	if topN <= 0 {
+		topN = defaultSectorTopN
+	}
+	if topN > maxSectorTopN {
+		topN = maxSectorTopN
+	}
+	newsCount := input.NewsCount
+	if newsCount <= 0 {
+		newsCount = defaultSectorNewsCount
+	}
+	if newsCount > maxSectorNewsCount {
+		newsCount = maxSectorNewsCount
+	}
+
+	g := getGenkitInstance()
+	if g == nil {
+		return "", fmt.Errorf("genkit实例未初始化")
+	}
+
+	// 创建带超时的context（20分钟超时，逐只成分股爬取需要较长时间）
+	analyzeCtx, cancel := context.WithTimeout(ctx.Context, 20*time.Minute)
+	defer cancel()
+
+	sectors, err := findSectors(analyzeCtx, input.Keyword, topN)
+	if err != nil {
+		return "", err
+	}
+	sector := sectors[0]
+	if len(sector.Stocks) == 0 {
+		return "", fmt.Errorf("板块 %s 没有成分股", sector.Name)
+	}
+	log.Printf("开始分析板块: %s，成分股 %d 只", sector.Name, len(sector.Stocks))
+
+	data := collectSectorData(&ai.ToolContext{Context: analyzeCtx}, sector.Stocks, newsCount)
+	if err := analyzeCtx.Err(); err != nil {
+		return "", fmt.Errorf("板块数据收集中断: %v", err)
+	}
+
+	// 成分股的电报来自财联社电报搜索，行情来自接口，不经过提取规则
+	warnings := recentSourceWarnings("cls_telegram")
+	prompt := fmt.Sprintf(`你是一位专业的行业研究员。请基于以下 %s 板块内主要成分股的行情和新闻，输出一份板块分析报告，采用markdown格式。
+
+要求：
+1. 概述板块整体表现和市场情绪（正面、负面、中性）
+2. 用表格对比各成分股的价格、涨跌幅、估值和近期要点
+3. 提炼板块共同的催化因素和风险因素，区分个股特有事件
+4. 指出板块内相对强势和弱势的个股，并说明依据
+5. 给出板块层面的投资建议（仅供参考）
+6. 如提供了数据源告警，说明哪些来源的数据可能缺失，并相应降低相关结论的确定性
+7. 使用中文回答，格式清晰易读，报告日期以新闻的最新发布时间为准
+
+%s
+%s
+请输出板块分析报告：`, sector.Name, formatSectorData(sector, data), formatSourceWarnings(warnings))
+
+	genkitCtx, cancel := context.WithTimeout(ctx.Context, 5*time.Minute)
+	defer cancel()
+	resp, err := genkit.Generate(genkitCtx, g,
+		ai.WithModelName("xiaomimimo/mimo-v2-flash"),
+		ai.WithMessages(ai.NewUserMessage(ai.NewTextPart(prompt))),
+		ai.WithMaxTurns(1),
+	)
+	if err != nil {
+		return "", fmt.Errorf("AI板块分析失败: %v", err)
+	}
+
+	analysis := withSourceWarnings(resp.Text(), warnings)
+	log.Printf("AI板块分析结果: %s\n", analysis)
+	return analysis, nil
+}
+
+// collectSectorData 并发获取各成分股的行情快照和电报，单只失败时跳过对应数据，结果与 stocks 顺序一致
+func collectSectorData(ctx *ai.ToolContext, stocks []SectorStock, newsCount int) []sectorStockData {
+	data := make([]sectorStockData, len(stocks))
+	sem := make(chan struct{}, max(crawlOptions.Workers, 1))
+	var wg sync.WaitGroup
+	for i, stock := range stocks {
+		data[i].Stock = stock
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+
+			quote, err := XqQuote(ctx, XqQuoteInput{Symbol: stock.Symbol})
+			if err != nil {
+				log.Printf("获取 %s 行情失败，已跳过: %v", stock.Symbol, err)
+			}
+			data[i].Quote = quote
+
+			news, err := SearchStockNews(ctx, SearchNewsInput{Keyword: stock.Name, Symbol: stock.Symbol, Count: newsCount})
+			if err != nil {
+				log.Printf("获取 %s 电报失败，已跳过: %v", stock.Symbol, err)
+			}
+			data[i].News = news
+		}()
+	}
+	wg.Wait()
+	return data
+}
+
+// formatSectorData 将成分股数据整理为提示词
+func formatSectorData(sector SectorResult, data []sectorStockData) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "板块: %s", sector.Name)
+	if sector.Type != "" {
+		fmt.Fprintf(&b, "（%s板块）", sector.Type)
+	}
+	fmt.Fprintf(&b, "，共分析 %d 只成分股\n\n", len(data))
+
+	for i, d := range data {
+		name := d.Stock.Name
+		if name == "" {
+			name = d.Stock.Symbol
+		}
+		fmt.Fprintf(&b, "成分股 %d: %s（%s）\n", i+1, name, d.Stock.Symbol)
+		if d.Quote != nil {
+			b.WriteString(formatQuoteSnapshot(d.Quote))
+		} else {
+			fmt.Fprintf(&b, "行情: 未获取到\n")
+		}
+		if len(d.News) == 0 {
+			fmt.Fprintf(&b, "近期电报: 未获取到\n\n")
+			continue
+		}
+		fmt.Fprintf(&b, "近期电报:\n")
+		for _, item := range d.News {
+			if item.Time != "" {
+				fmt.Fprintf(&b, "- [%s] %s", item.Time, item.Title)
+			} else {
+				fmt.Fprintf(&b, "- %s", item.Title)
+			}
+			// 板块报告涉及多只股票，每条只保留简短摘要
+			if content := truncateRunes(item.Content, 200); content != "" && content != item.Title {
+				fmt.Fprintf(&b, "：%s", content)
+			}
+			fmt.Fprintf(&b, "（%s）\n", item.URL)
+		}
+		fmt.Fprintf(&b, "\n")
+	}
+	return b.String()
+}
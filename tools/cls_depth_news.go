@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"stock_agent/newstime"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/firebase/genkit/go/ai"
+)
+
+// SearchDepthNewsInput 搜索财联社深度文章的输入参数
+type SearchDepthNewsInput struct {
+	Keyword string `json:"keyword" jsonschema_description:"要查询的股票关键词，例如：腾讯、阿里巴巴、AAPL等"`
+	Symbol  string `json:"symbol,omitempty" jsonschema_description:"可选，analyzeInput 解析出的股票代码，如 SH601288；未提供 keyword 时按代码对应的简称搜索"`
+	Count   int    `json:"count,omitempty" jsonschema_description:"需要获取的深度文章篇数，默认5，最多20"`
+	Refresh bool   `json:"refresh,omitempty" jsonschema_description:"可选，忽略页面缓存重新抓取，用户要求获取最新内容时设为true"`
+}
+
+const (
+	defaultClsDepthCount = 5
+	maxClsDepthCount     = 20
+)
+
+var (
+	clsAuthorPattern   = regexp.MustCompile(`(?:作者|记者|编辑)[：:\s]*([^\s丨|，,]{2,12})`)
+	clsDateTimePattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2} \d{2}:\d{2}(:\d{2})?`)
+)
+
+// SearchClsDepthNews 搜索财联社深度文章并抓取正文（Genkit Tool）
+func SearchClsDepthNews(ctx *ai.ToolContext, input SearchDepthNewsInput) ([]NewsItem, error) {
+	if input.Keyword == "" && input.Symbol != "" {
+		input.Keyword = keywordForSymbol(input.Symbol)
+	}
+	if input.Keyword == "" {
+		return nil, fmt.Errorf("keyword 和 symbol 不能同时为空")
+	}
+	log.Printf("搜索财联社深度文章: %s", input.Keyword)
+	searchCtx, cancel := context.WithTimeout(withCacheBypass(ctx.Context, input.Refresh), 20*time.Minute)
+	defer cancel()
+
+	count := input.Count
+	if count <= 0 {
+		count = defaultClsDepthCount
+	}
+	if count > maxClsDepthCount {
+		count = maxClsDepthCount
+	}
+
+	depthURL := getClsDepthChannel(input.Keyword)
+	page, err := getFetcher(sourceCLS).Fetch(searchCtx, depthURL, ruleFetchOptions(getRule("cls_depth")))
+	if err != nil {
+		return nil, fmt.Errorf("财联社深度文章搜索失败: %v", err)
+	}
+	doc, err := parseHTML(page)
+	if err != nil {
+		return nil, err
+	}
+
+	links := parseClsDepthLinks(page, doc)
+	if len(links) == 0 {
+		log.Printf("警告：未找到财联社深度文章链接，可能选择器需要调整")
+		return []NewsItem{}, nil
+	}
+	if len(links) > count {
+		links = links[:count]
+	}
+
+	newsItems := make([]NewsItem, 0, len(links))
+	for _, link := range links {
+		// 检查context是否已取消
+		select {
+		case <-searchCtx.Done():
+			log.Printf("财联社深度文章抓取超时，返回已获取的 %d 篇", len(newsItems))
+			return withSymbols(newsItems, relatedSymbols(input.Keyword, input.Symbol)), nil
+		default:
+		}
+
+		item, err := fetchClsArticle(searchCtx, link, time.Now())
+		if err != nil {
+			log.Printf("爬取财联社深度文章失败: %v", err)
+			continue
+		}
+		newsItems = append(newsItems, item)
+		log.Printf("爬取财联社深度文章成功: %s", link)
+	}
+	return withSymbols(newsItems, relatedSymbols(input.Keyword, input.Symbol)), nil
+}
+
+// parseClsDepthLinks 解析深度搜索结果中的文章链接（去重并保持页面顺序）
+func parseClsDepthLinks(page *FetchedPage, doc *goquery.Document) []string {
+	seen := make(map[string]bool)
+	var links []string
+	for _, rec := range extractRecords(getRule("cls_depth"), page, doc, time.Now()) {
+		link := resolveURL(clsBaseURL, rec.Fields["url"])
+		if seen[link] {
+			continue
+		}
+		seen[link] = true
+		links = append(links, link)
+	}
+	return links
+}
+
+// fetchClsArticle 抓取并解析单篇财联社文章
+func fetchClsArticle(ctx context.Context, link string, now time.Time) (NewsItem, error) {
+	page, err := getFetcher(sourceCLS).Fetch(ctx, link, ruleFetchOptions(getRule("cls_article")))
+	if err != nil {
+		return NewsItem{}, err
+	}
+	doc, err := parseHTML(page)
+	if err != nil {
+		return NewsItem{}, err
+	}
+	item := parseClsArticle(page, doc, now)
+	item.URL = link
+	if item.Content == "" {
+		// 正文选择器失效时按文本密度提取正文，仍失败时退回整页文本
+		fallback, err := newsItemFromPage(page, sourceCLS, item.Title)
+		if err != nil {
+			return NewsItem{}, err
+		}
+		item.Content = fallback.Content
+		item.Title = cmp.Or(item.Title, fallback.Title)
+		item.Author = cmp.Or(item.Author, fallback.Author)
+		if item.PublishedAt.IsZero() {
+			item.SetPublished(fallback.PublishedAt)
+		}
+	}
+	if item.Title == "" {
+		item.Title = page.Title
+	}
+	return item, nil
+}
+
+// parseClsArticle 按提取规则解析文章详情页的标题、作者、发布时间和正文
+func parseClsArticle(page *FetchedPage, doc *goquery.Document, now time.Time) NewsItem {
+	rule := getRule("cls_article")
+	checkRuleHealth(rule, page, rule.Validate(doc, now))
+	item := newNewsItem(sourceCLS, sourceTypeNews)
+	item.Title, _ = rule.Field(doc.Selection, "title")
+	item.Tags = []string{"深度"}
+
+	if content, _ := rule.Field(doc.Selection, "content"); content != "" {
+		item.Content = truncateRunes(content, maxPageContentRunes)
+	}
+
+	author, _ := rule.Field(doc.Selection, "author")
+	timeText, _ := rule.Field(doc.Selection, "time")
+	if m := clsAuthorPattern.FindStringSubmatch(author + " " + timeText); m != nil {
+		author = m[1]
+	}
+	item.Author = author
+
+	// 时间区域可能显示"3小时前"等相对时间；找不到时再从全文中找完整的日期时间
+	published, ok := rule.ParseTime(timeText, now)
+	if !ok {
+		published, ok = newstime.Find(clsDateTimePattern.FindString(htmlText(doc)), now)
+	}
+	if ok {
+		item.SetPublished(published)
+	}
+	return item
+}
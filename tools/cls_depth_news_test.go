@@ -0,0 +1,30 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestParseClsArticleTruncate(t *testing.T) {
+	// 正文全部为中文，按字节截断会切在汉字中间
+	content := strings.Repeat("农业银行", maxPageContentRunes)
+	html := `<html><body><h1 class="detail-title">农业银行深度</h1><div class="detail-content">` + content + `</div></body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := parseClsArticle(&FetchedPage{HTML: html}, doc, time.Now())
+	if !utf8.ValidString(item.Content) {
+		t.Fatalf("截断后正文不是有效的UTF-8")
+	}
+	if n := utf8.RuneCountInString(item.Content); n != maxPageContentRunes+3 {
+		t.Errorf("正文 %d 个字符，期望 %d", n, maxPageContentRunes+3)
+	}
+	if !strings.HasSuffix(item.Content, "...") {
+		t.Errorf("截断后正文应以 ... 结尾")
+	}
+}
@@ -1,41 +1,53 @@
 package tools
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"log"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"stock_agent/extractor"
+	"stock_agent/newstime"
+
+	"github.com/PuerkitoBio/goquery"
 	"github.com/firebase/genkit/go/ai"
 )
 
 // SearchNewsInput 搜索新闻的输入参数
 type SearchNewsInput struct {
 	Keyword string `json:"keyword" jsonschema_description:"要查询的股票关键词，例如：腾讯、阿里巴巴、AAPL等"`
+	Symbol  string `json:"symbol,omitempty" jsonschema_description:"可选，analyzeInput 解析出的股票代码，如 SH601288；未提供 keyword 时按代码对应的简称搜索"`
+	Count   int    `json:"count,omitempty" jsonschema_description:"需要获取的电报条数，默认20，最多100"`
+	Refresh bool   `json:"refresh,omitempty" jsonschema_description:"可选，忽略页面缓存重新抓取，用户要求获取最新内容时设为true"`
 }
 
-// NewsItem 新闻项结构
-type NewsItem struct {
-	Title   string `json:"title"`
-	Content string `json:"content"`
-	URL     string `json:"url"`
-	Time    string `json:"time"`
-}
+const (
+	defaultClsTelegramCount = 20
+	maxClsTelegramCount     = 100
+	// clsTelegramPageSize 搜索页每次“加载更多”追加的电报条数，规则未配置 page_size 时使用
+	clsTelegramPageSize = 20
+)
 
 // SearchStockNews 搜索股票相关新闻（Genkit Tool）
 func SearchStockNews(ctx *ai.ToolContext, input SearchNewsInput) ([]NewsItem, error) {
+	if input.Keyword == "" && input.Symbol != "" {
+		input.Keyword = keywordForSymbol(input.Symbol)
+	}
+	if input.Keyword == "" {
+		return nil, fmt.Errorf("keyword 和 symbol 不能同时为空")
+	}
+
 	// 创建带超时的context（20分钟超时，给爬取足够时间）
 	log.Printf("搜索财联社新闻: %s", input.Keyword)
-	searchCtx, cancel := context.WithTimeout(ctx.Context, 20*time.Minute)
+	searchCtx, cancel := context.WithTimeout(withCacheBypass(ctx.Context, input.Refresh), 20*time.Minute)
 	defer cancel()
 
-	// 使用公共方法获取浏览器
-	browser, err := getBrowser()
-	if err != nil {
-		return nil, fmt.Errorf("财联社电报频道新闻获取浏览器失败: %v", err)
-	}
-	defer browser.Close()
-
 	// 检查context是否已取消
 	select {
 	case <-searchCtx.Done():
@@ -43,28 +55,188 @@ func SearchStockNews(ctx *ai.ToolContext, input SearchNewsInput) ([]NewsItem, er
 	default:
 	}
 
-	// 使用公共方法安全创建页面
-	page, err := createPageSafely(browser, 60*time.Second)
-	if err != nil {
-		return nil, fmt.Errorf("财联社电报频道新闻创建页面失败: %v", err)
+	count := input.Count
+	if count <= 0 {
+		count = defaultClsTelegramCount
+	}
+	if count > maxClsTelegramCount {
+		count = maxClsTelegramCount
 	}
-	defer page.Close()
 
-	newsItems := make([]NewsItem, 0, 1)
+	rule := getRule("cls_telegram")
+	opts := ruleFetchOptions(rule)
+	opts.LoadMoreTimes = (count - 1) / cmp.Or(rule.Pagination.PageSize, clsTelegramPageSize)
 	channelURL := getClsChannel(input.Keyword)
-	channelNewsItem, err := fetchNewsContent(context.Background(), browser, channelURL, input.Keyword+"-电报频道", 60*time.Second)
+	page, err := getFetcher(sourceCLS).Fetch(searchCtx, channelURL, opts)
 	if err != nil {
-		log.Printf("财联社电报频道新闻爬取失败: %v", err)
+		return nil, fmt.Errorf("财联社电报频道新闻爬取失败: %v", err)
+	}
+	doc, err := parseHTML(page)
+	if err != nil {
+		return nil, err
+	}
+
+	newsItems := parseClsTelegrams(page, doc, time.Now())
+	if len(newsItems) == 0 {
+		// 页面结构可能已变化，退回整页文本，避免完全没有数据
+		log.Printf("警告：未解析到财联社电报，退回整页文本")
+		channelNewsItem, err := newsItemFromPage(page, sourceCLS, input.Keyword+"-电报频道")
+		if err != nil {
+			return nil, fmt.Errorf("财联社电报频道新闻爬取失败: %v", err)
+		}
+		return withSymbols([]NewsItem{channelNewsItem}, relatedSymbols(input.Keyword, input.Symbol)), nil
+	}
+	if len(newsItems) > count {
+		newsItems = newsItems[:count]
 	}
-	newsItems = append(newsItems, channelNewsItem)
 	log.Printf("财联社电报频道新闻爬取成功，共获取 %d 条新闻", len(newsItems))
-	return newsItems, nil
+	return withSymbols(newsItems, relatedSymbols(input.Keyword, input.Symbol)), nil
+}
+
+// parseClsTelegrams 将电报搜索结果页解析为按时间倒序的新闻列表
+func parseClsTelegrams(page *FetchedPage, doc *goquery.Document, now time.Time) []NewsItem {
+	rule := getRule("cls_telegram")
+	checkRuleHealth(rule, page, rule.Validate(doc, now))
+	var blocks []*goquery.Selection
+	if items, _ := rule.Items(doc); items.Length() > 0 {
+		for _, item := range items.EachIter() {
+			blocks = append(blocks, item)
+		}
+	} else {
+		// 选择器失效时，从电报详情链接向上查找带时间的容器
+		for _, link := range doc.Find("a[href*='/detail/']").EachIter() {
+			if block := closestTimedBlock(link); block != nil {
+				blocks = append(blocks, block)
+			}
+		}
+	}
+
+	type telegram struct {
+		item      NewsItem
+		published time.Time
+	}
+	seen := make(map[string]bool)
+	var telegrams []telegram
+	for _, block := range blocks {
+		item, published, ok := parseClsTelegram(rule, block, now)
+		if !ok || seen[item.URL+item.Content] {
+			continue
+		}
+		seen[item.URL+item.Content] = true
+		telegrams = append(telegrams, telegram{item: item, published: published})
+	}
+
+	// 按发布时间倒序
+	sort.SliceStable(telegrams, func(i, j int) bool {
+		return telegrams[i].published.After(telegrams[j].published)
+	})
+	items := make([]NewsItem, len(telegrams))
+	for i, t := range telegrams {
+		items[i] = t.item
+	}
+	return items
+}
+
+var (
+	clsHeadlinePattern = regexp.MustCompile(`^【([^】]+)】`)
+	clsTimePattern     = regexp.MustCompile(`(\d{4}-\d{2}-\d{2} \d{2}:\d{2}(:\d{2})?)|(\d{2}-\d{2} \d{2}:\d{2})|(\b\d{2}:\d{2}(:\d{2})?\b)`)
+)
+
+// parseClsTelegram 解析单条电报，返回新闻、发布时间以及是否解析成功
+func parseClsTelegram(rule *extractor.Rule, block *goquery.Selection, now time.Time) (NewsItem, time.Time, bool) {
+	text := selectionText(block)
+	timeText := clsTimePattern.FindString(text)
+	if timeText == "" {
+		return NewsItem{}, time.Time{}, false
+	}
+	published, err := newstime.Parse(timeText, now)
+	if err != nil {
+		return NewsItem{}, time.Time{}, false
+	}
+
+	// 正文：去掉时间、阅读数等短行，只保留电报内容
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if clsTimePattern.MatchString(line) && utf8.RuneCountInString(line) <= 20 {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	content := strings.Join(lines, "")
+	if utf8.RuneCountInString(content) < 10 {
+		return NewsItem{}, time.Time{}, false
+	}
+
+	// 标题：优先使用【】中的标题，其次是规则中的标题元素（加粗文本），最后截取正文开头
+	title, _ := rule.Field(block, "title")
+	if m := clsHeadlinePattern.FindStringSubmatch(content); m != nil {
+		title = m[1]
+	}
+	if title == "" {
+		title = truncateRunes(content, 30)
+	}
+
+	permalink := ""
+	if href, _ := rule.Field(block, "url"); href != "" {
+		permalink = resolveURL(clsBaseURL, href)
+	}
+
+	item := newNewsItem(sourceCLS, sourceTypeNews)
+	item.Title = title
+	item.Content = content
+	item.URL = permalink
+	item.Tags = []string{"电报"}
+	item.SetPublished(published)
+	return item, published, true
 }
 
+// closestTimedBlock 从链接向上查找包含发布时间的容器
+func closestTimedBlock(link *goquery.Selection) *goquery.Selection {
+	block := link
+	for i := 0; i < 5; i++ {
+		block = block.Parent()
+		if block.Length() == 0 {
+			return nil
+		}
+		if clsTimePattern.MatchString(block.Text()) {
+			return block
+		}
+	}
+	return nil
+}
+
+// shanghai 财联社、雪球页面时间均为北京时间
+var shanghai = newstime.Shanghai
+
+// resolveURL 将相对链接转为绝对链接
+func resolveURL(base, href string) string {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return href
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	return baseURL.ResolveReference(ref).String()
+}
+
+// truncateRunes 按字符截断字符串
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
+
+// clsBaseURL 财联社站点地址
+var clsBaseURL = "https://www.cls.cn"
+
 func getClsChannel(keyword string) string {
-	return fmt.Sprintf(`https://www.cls.cn/searchPage?keyword=%s&type=telegram`, keyword)
+	return getRule("cls_telegram").PageURL(map[string]string{"base": clsBaseURL, "keyword": url.QueryEscape(keyword)}, 1)
 }
 
-func getRealtimeInfo(keyword string) string {
-	return fmt.Sprintf(`https://www.cls.cn/searchPage?keyword=%s&type=depth`, keyword)
+func getClsDepthChannel(keyword string) string {
+	return getRule("cls_depth").PageURL(map[string]string{"base": clsBaseURL, "keyword": url.QueryEscape(keyword)}, 1)
 }
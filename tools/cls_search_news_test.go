@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// useHTTPFetcher 测试期间改用 net/http 抓取页面，不启动浏览器
+func useHTTPFetcher(t *testing.T) {
+	t.Helper()
+	oldDefault, oldSources := defaultFetcherMode, sourceFetcherModes
+	t.Cleanup(func() { defaultFetcherMode, sourceFetcherModes = oldDefault, oldSources })
+	if err := SetFetcherModes(FetcherHTTP, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// serveHTML 返回按路径提供固定页面的本地服务，未配置的路径返回404
+func serveHTML(t *testing.T, pages map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, page)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+const clsTelegramPage = `<html><head><title>财联社-搜索</title></head><body>
+<div class="search-telegram-list">
+  <div class="search-telegram-item">
+    <div>2025-10-15 09:30</div>
+    <div><strong>【农业银行：前三季度净利润同比增长】</strong>农业银行公告，前三季度实现净利润2200亿元，同比增长2.6%。</div>
+    <a href="/detail/2001">详情</a>
+  </div>
+  <div class="search-telegram-item">
+    <div>2025-10-15 14:05</div>
+    <div>农业银行宣布将于下月召开临时股东大会，审议董事选举议案。</div>
+    <a href="/detail/2002">详情</a>
+  </div>
+</div>
+</body></html>`
+
+func TestSearchStockNews(t *testing.T) {
+	useHTTPFetcher(t)
+	server := serveHTML(t, map[string]string{"/searchPage": clsTelegramPage})
+	oldBase := clsBaseURL
+	t.Cleanup(func() { clsBaseURL = oldBase })
+	clsBaseURL = server.URL
+
+	items, err := SearchStockNews(&ai.ToolContext{Context: context.Background()}, SearchNewsInput{Keyword: "农业银行"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("电报数 %d，期望 2: %+v", len(items), items)
+	}
+	// 按发布时间倒序，相对链接按站点地址补全
+	latest, earlier := items[0], items[1]
+	if latest.URL != server.URL+"/detail/2002" || !strings.HasPrefix(latest.Content, "农业银行宣布将于下月") {
+		t.Errorf("第一条 = %q %q", latest.URL, latest.Content)
+	}
+	if earlier.Title != "农业银行：前三季度净利润同比增长" || earlier.URL != server.URL+"/detail/2001" {
+		t.Errorf("第二条 = %q %q", earlier.Title, earlier.URL)
+	}
+	if want := time.Date(2025, 10, 15, 9, 30, 0, 0, shanghai); !earlier.PublishedAt.Equal(want) {
+		t.Errorf("发布时间 = %v，期望 %v", earlier.PublishedAt, want)
+	}
+}
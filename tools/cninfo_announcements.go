@@ -0,0 +1,285 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"stock_agent/kline"
+	"stock_agent/security"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// SearchAnnouncementsInput 搜索公司公告的输入参数
+type SearchAnnouncementsInput struct {
+	Symbol      string `json:"symbol" jsonschema_description:"A股代码，例如：SH601288、SZ000001、601288"`
+	Keyword     string `json:"keyword,omitempty" jsonschema_description:"可选，公告标题关键词，例如：回购、质押"`
+	Category    string `json:"category,omitempty" jsonschema_description:"可选，公告类别：定期报告、业绩预告、回购、增减持、质押、诉讼仲裁、分红、股东大会、董事会、监事会、担保、监管问询、风险提示、融资、股权激励、其他"`
+	Start       string `json:"start,omitempty" jsonschema_description:"开始日期，格式 2006-01-02，默认30天前"`
+	End         string `json:"end,omitempty" jsonschema_description:"结束日期，格式 2006-01-02，默认今天"`
+	Count       int    `json:"count,omitempty" jsonschema_description:"需要获取的公告条数，默认20，最多100"`
+	ExtractText bool   `json:"extractText,omitempty" jsonschema_description:"是否下载PDF提取正文，较慢，只对最新的5条公告提取"`
+	Refresh     bool   `json:"refresh,omitempty" jsonschema_description:"可选，忽略缓存重新下载公告PDF"`
+}
+
+const (
+	defaultAnnouncementCount = 20
+	maxAnnouncementCount     = 100
+	// defaultAnnouncementDays 未指定开始日期时查询的天数
+	defaultAnnouncementDays = 30
+	// maxAnnouncementTexts 提取正文的公告数量上限
+	maxAnnouncementTexts = 5
+	// cninfoPageSize 公告查询接口每页条数
+	cninfoPageSize = 30
+	// maxCninfoPages 单次查询最多翻页数，按少见的类别过滤时避免翻遍整个时间范围
+	maxCninfoPages = 10
+)
+
+var (
+	// cninfoBaseURL 巨潮资讯网地址
+	cninfoBaseURL = "http://www.cninfo.com.cn"
+	// cninfoStaticURL 公告PDF的下载地址
+	cninfoStaticURL = "http://static.cninfo.com.cn"
+)
+
+var cninfoClient = &http.Client{Timeout: 30 * time.Second}
+
+// announcementCategories 按标题关键词划分公告类别，按顺序匹配第一个
+var announcementCategories = []struct {
+	Name     string
+	Keywords []string
+}{
+	{"业绩预告", []string{"业绩预告", "业绩快报", "业绩预增", "业绩预减", "预亏", "预盈"}},
+	{"定期报告", []string{"年度报告", "半年度报告", "季度报告", "年报", "季报"}},
+	{"回购", []string{"回购"}},
+	{"质押", []string{"质押"}},
+	{"增减持", []string{"增持", "减持"}},
+	{"诉讼仲裁", []string{"诉讼", "仲裁", "起诉"}},
+	{"分红", []string{"权益分派", "利润分配", "分红", "派息"}},
+	{"监管问询", []string{"问询函", "关注函", "监管函", "警示函", "立案", "处罚"}},
+	{"风险提示", []string{"风险提示", "异常波动", "退市风险", "停牌", "复牌"}},
+	{"担保", []string{"担保"}},
+	{"股权激励", []string{"股权激励", "限制性股票", "股票期权", "员工持股"}},
+	{"融资", []string{"可转换公司债券", "可转债", "公司债券", "非公开发行", "向特定对象发行", "配股", "募集资金"}},
+	{"股东大会", []string{"股东大会", "股东会"}},
+	{"董事会", []string{"董事会"}},
+	{"监事会", []string{"监事会"}},
+}
+
+// announcementOther 未匹配任何类别的公告
+const announcementOther = "其他"
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]+>`)
+
+// SearchAnnouncements 搜索上市公司公告（Genkit Tool）
+func SearchAnnouncements(ctx *ai.ToolContext, input SearchAnnouncementsInput) ([]NewsItem, error) {
+	code, exchange := splitASymbol(input.Symbol)
+	if code == "" {
+		return nil, fmt.Errorf("公告查询仅支持A股代码: %s", input.Symbol)
+	}
+	count := input.Count
+	if count <= 0 {
+		count = defaultAnnouncementCount
+	}
+	if count > maxAnnouncementCount {
+		count = maxAnnouncementCount
+	}
+	end, err := parseDateInput(input.End)
+	if err != nil {
+		return nil, err
+	}
+	if end.IsZero() {
+		end = time.Now().In(shanghai)
+	}
+	start, err := parseDateInput(input.Start)
+	if err != nil {
+		return nil, err
+	}
+	if start.IsZero() {
+		start = end.AddDate(0, 0, -defaultAnnouncementDays)
+	}
+	if start.After(end) {
+		return nil, fmt.Errorf("开始日期 %s 晚于结束日期 %s", input.Start, input.End)
+	}
+
+	log.Printf("搜索公司公告: %s%s %s ~ %s", exchange, code, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	searchCtx, cancel := context.WithTimeout(withCacheBypass(ctx.Context, input.Refresh), 5*time.Minute)
+	defer cancel()
+
+	announcements, err := queryCninfoAnnouncements(searchCtx, code, exchange, input.Keyword, input.Category, start, end, count)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]NewsItem, len(announcements))
+	for i, a := range announcements {
+		items[i] = a.newsItem()
+	}
+	if input.ExtractText {
+		for i := 0; i < len(items) && i < maxAnnouncementTexts; i++ {
+			doc, err := fetchPDFDocument(searchCtx, items[i].URL)
+			if err != nil {
+				log.Printf("提取公告正文失败，已跳过: %s: %v", items[i].URL, err)
+				continue
+			}
+			items[i].Content += "\n" + formatPDFContent(doc, 5000)
+		}
+	}
+	log.Printf("公司公告搜索成功，共获取 %d 条公告", len(items))
+	return items, nil
+}
+
+// announcement 巨潮资讯公告
+type announcement struct {
+	Code      string
+	Exchange  string
+	Name      string
+	Title     string
+	Category  string
+	Published time.Time
+	PDFURL    string
+}
+
+// newsItem 将公告转为新闻项，标题带类别前缀，正文为公告摘要信息
+func (a announcement) newsItem() NewsItem {
+	item := newNewsItem(sourceCninfo, sourceTypeAnnouncement)
+	item.Title = fmt.Sprintf("[公告·%s] %s", a.Category, a.Title)
+	item.Content = fmt.Sprintf("%s（%s）发布公告《%s》，公告类别: %s，公告日期: %s，原文PDF: %s", a.Name, a.Code, a.Title, a.Category, a.Published.Format(kline.DateLayout), a.PDFURL)
+	item.URL = a.PDFURL
+	item.Symbols = []string{a.Exchange + a.Code}
+	item.Tags = []string{a.Category}
+	item.SetPublished(a.Published)
+	return item
+}
+
+// queryCninfoAnnouncements 分页查询巨潮资讯公告，按公告时间倒序，最多返回 count 条
+// 按类别过滤后凑满 count 条、没有更多公告或达到 maxCninfoPages 页时停止翻页
+func queryCninfoAnnouncements(ctx context.Context, code, exchange, keyword, category string, start, end time.Time, count int) ([]announcement, error) {
+	orgID, err := cninfoOrgID(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	column := "szse"
+	switch exchange {
+	case security.ExchangeSH:
+		column = "sse"
+	case security.ExchangeBJ:
+		column = "bj"
+	}
+
+	var announcements []announcement
+	for page := 1; ; page++ {
+		form := url.Values{
+			"stock":     {code + "," + orgID},
+			"tabName":   {"fulltext"},
+			"pageSize":  {strconv.Itoa(cninfoPageSize)},
+			"pageNum":   {strconv.Itoa(page)},
+			"column":    {column},
+			"searchkey": {keyword},
+			"seDate":    {start.Format("2006-01-02") + "~" + end.Format("2006-01-02")},
+			"isHLtitle": {"true"},
+		}
+		var result struct {
+			Announcements []struct {
+				SecCode           string `json:"secCode"`
+				SecName           string `json:"secName"`
+				AnnouncementTitle string `json:"announcementTitle"`
+				AnnouncementTime  int64  `json:"announcementTime"`
+				AdjunctURL        string `json:"adjunctUrl"`
+			} `json:"announcements"`
+			HasMore bool `json:"hasMore"`
+		}
+		if err := postCninfo(ctx, "/new/hisAnnouncement/query", form, &result); err != nil {
+			return nil, err
+		}
+		for _, a := range result.Announcements {
+			title := strings.TrimSpace(htmlTagPattern.ReplaceAllString(a.AnnouncementTitle, ""))
+			item := announcement{
+				Code:      a.SecCode,
+				Exchange:  exchange,
+				Name:      a.SecName,
+				Title:     title,
+				Category:  classifyAnnouncement(title),
+				Published: time.UnixMilli(a.AnnouncementTime).In(shanghai),
+				PDFURL:    cninfoStaticURL + "/" + strings.TrimPrefix(a.AdjunctURL, "/"),
+			}
+			if category != "" && item.Category != category {
+				continue
+			}
+			announcements = append(announcements, item)
+			if len(announcements) >= count {
+				break
+			}
+		}
+		if len(announcements) >= count || !result.HasMore || len(result.Announcements) == 0 {
+			break
+		}
+		if page >= maxCninfoPages {
+			log.Printf("公告查询已翻 %d 页，停止翻页，共找到 %d 条", page, len(announcements))
+			break
+		}
+	}
+	return announcements, nil
+}
+
+// cninfoOrgID 查询证券在巨潮资讯的机构代码，公告查询接口需要 代码,机构代码 的形式
+func cninfoOrgID(ctx context.Context, code string) (string, error) {
+	var result []struct {
+		Code     string `json:"code"`
+		OrgID    string `json:"orgId"`
+		Category string `json:"category"`
+	}
+	form := url.Values{"keyWord": {code}, "maxNum": {"10"}}
+	if err := postCninfo(ctx, "/new/information/topSearch/query", form, &result); err != nil {
+		return "", err
+	}
+	for _, r := range result {
+		if r.Code == code && r.OrgID != "" {
+			return r.OrgID, nil
+		}
+	}
+	return "", fmt.Errorf("巨潮资讯未找到证券: %s", code)
+}
+
+// postCninfo 以表单方式请求巨潮资讯接口并解析JSON
+func postCninfo(ctx context.Context, path string, form url.Values, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cninfoBaseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+	req.Header.Set("User-Agent", httpUserAgent)
+	req.Header.Set("Referer", cninfoBaseURL+"/new/disclosure")
+	resp, err := cninfoClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求巨潮资讯失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("请求巨潮资讯失败: HTTP %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("解析巨潮资讯返回失败: %v", err)
+	}
+	return nil
+}
+
+// classifyAnnouncement 按标题关键词判断公告类别
+func classifyAnnouncement(title string) string {
+	for _, c := range announcementCategories {
+		for _, keyword := range c.Keywords {
+			if strings.Contains(title, keyword) {
+				return c.Name
+			}
+		}
+	}
+	return announcementOther
+}
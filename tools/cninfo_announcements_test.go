@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"stock_agent/security"
+)
+
+func TestClassifyAnnouncement(t *testing.T) {
+	tests := []struct {
+		title, want string
+	}{
+		// 业绩预告先于定期报告匹配
+		{"关于2024年度业绩快报暨年报披露时间的提示性公告", "业绩预告"},
+		{"2024年年度报告", "定期报告"},
+		// 回购先于股东大会匹配
+		{"关于提请股东大会授权回购公司股份的公告", "回购"},
+		{"关于召开2024年年度股东大会的通知", "股东大会"},
+		{"关于控股股东部分股份质押的公告", "质押"},
+		{"关于公司网站改版的公告", announcementOther},
+	}
+	for _, tt := range tests {
+		if got := classifyAnnouncement(tt.title); got != tt.want {
+			t.Errorf("classifyAnnouncement(%q) = %q，期望 %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+// serveCninfo 启动模拟巨潮资讯接口，每页返回一条季报和一条回购公告，共 pages 页，返回请求过的页码
+func serveCninfo(t *testing.T, pages int) *[]int {
+	t.Helper()
+	var requested []int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/new/information/topSearch/query", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"code":%q,"orgId":"gssh0601288","category":"A股"}]`, r.FormValue("keyWord"))
+	})
+	mux.HandleFunc("/new/hisAnnouncement/query", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("stock") != "601288,gssh0601288" || r.FormValue("column") != "sse" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		page, _ := strconv.Atoi(r.FormValue("pageNum"))
+		requested = append(requested, page)
+		published := time.Date(2025, 4, 30, 0, 0, 0, 0, shanghai).AddDate(0, 0, -page)
+		type item struct {
+			SecCode           string `json:"secCode"`
+			SecName           string `json:"secName"`
+			AnnouncementTitle string `json:"announcementTitle"`
+			AnnouncementTime  int64  `json:"announcementTime"`
+			AdjunctURL        string `json:"adjunctUrl"`
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"announcements": []item{
+				{"601288", "农业银行", fmt.Sprintf("第%d期<em>季度报告</em>", page), published.UnixMilli(), fmt.Sprintf("finalpage/2025-04-%02d/%d.PDF", 30-page, 2*page)},
+				{"601288", "农业银行", fmt.Sprintf("关于回购股份的进展公告（第%d期）", page), published.UnixMilli(), fmt.Sprintf("/finalpage/2025-04-%02d/%d.PDF", 30-page, 2*page+1)},
+			},
+			"hasMore": page < pages,
+		})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	oldBase, oldStatic := cninfoBaseURL, cninfoStaticURL
+	cninfoBaseURL, cninfoStaticURL = server.URL, "http://static.example.com"
+	t.Cleanup(func() { cninfoBaseURL, cninfoStaticURL = oldBase, oldStatic })
+	return &requested
+}
+
+func TestQueryCninfoAnnouncements(t *testing.T) {
+	end := time.Date(2025, 4, 30, 0, 0, 0, 0, shanghai)
+	start := end.AddDate(-1, 0, 0)
+	query := func(category string, count int) []announcement {
+		t.Helper()
+		announcements, err := queryCninfoAnnouncements(context.Background(), "601288", security.ExchangeSH, "", category, start, end, count)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return announcements
+	}
+
+	// 凑满条数后不再翻页
+	requested := serveCninfo(t, 20)
+	got := query("", 3)
+	if len(got) != 3 || fmt.Sprint(*requested) != "[1 2]" {
+		t.Fatalf("返回 %d 条，请求页码 %v，期望 3 条、2 页", len(got), *requested)
+	}
+	first := got[0]
+	if first.Title != "第1期季度报告" || first.Category != "定期报告" || first.Exchange != security.ExchangeSH ||
+		first.PDFURL != "http://static.example.com/finalpage/2025-04-29/2.PDF" || !first.Published.Equal(end.AddDate(0, 0, -1)) {
+		t.Errorf("第一条公告 = %+v", first)
+	}
+	if got[1].PDFURL != "http://static.example.com/finalpage/2025-04-29/3.PDF" {
+		t.Errorf("PDF地址 = %q", got[1].PDFURL)
+	}
+
+	// 按类别过滤后的条数决定是否继续翻页
+	requested = serveCninfo(t, 20)
+	got = query("回购", 3)
+	if len(got) != 3 || fmt.Sprint(*requested) != "[1 2 3]" {
+		t.Fatalf("返回 %d 条，请求页码 %v，期望 3 条、3 页", len(got), *requested)
+	}
+	for _, a := range got {
+		if a.Category != "回购" {
+			t.Errorf("类别过滤后出现 %q: %s", a.Category, a.Title)
+		}
+	}
+
+	// 没有更多公告时停止
+	requested = serveCninfo(t, 2)
+	if got = query("", 10); len(got) != 4 || len(*requested) != 2 {
+		t.Errorf("返回 %d 条，请求 %d 页，期望 4 条、2 页", len(got), len(*requested))
+	}
+
+	// 少见的类别最多翻 maxCninfoPages 页
+	requested = serveCninfo(t, 100)
+	if got = query("诉讼仲裁", 5); len(got) != 0 || len(*requested) != maxCninfoPages {
+		t.Errorf("返回 %d 条，请求 %d 页，期望 0 条、%d 页", len(got), len(*requested), maxCninfoPages)
+	}
+}
@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// CrawlOptions 并发抓取参数
+type CrawlOptions struct {
+	Workers      int           // 并发抓取的页面数，默认4
+	HostInterval time.Duration // 同一站点两次请求的最小间隔，默认500毫秒
+	Deadline     time.Duration // 整体截止时间，到期后返回已抓取的部分结果，默认3分钟
+}
+
+var crawlOptions = CrawlOptions{
+	Workers:      4,
+	HostInterval: 500 * time.Millisecond,
+	Deadline:     3 * time.Minute,
+}
+
+// SetCrawlOptions 设置并发抓取参数，未设置的字段保持默认值
+func SetCrawlOptions(opts CrawlOptions) {
+	if opts.Workers > 0 {
+		crawlOptions.Workers = opts.Workers
+	}
+	if opts.HostInterval > 0 {
+		crawlOptions.HostInterval = opts.HostInterval
+	}
+	if opts.Deadline > 0 {
+		crawlOptions.Deadline = opts.Deadline
+	}
+}
+
+// crawlPages 并发抓取多个页面，返回结果与 urls 顺序一致，失败或超时未完成的页面会被跳过
+func crawlPages(ctx context.Context, urls []string, fetch func(ctx context.Context, url string) (NewsItem, error)) []NewsItem {
+	opts := crawlOptions
+	crawlCtx, cancel := context.WithTimeout(ctx, opts.Deadline)
+	defer cancel()
+
+	type result struct {
+		index int
+		item  NewsItem
+		err   error
+	}
+	jobs := make(chan int)
+	// 带缓冲，截止后仍在运行的worker写入结果时不会阻塞
+	results := make(chan result, len(urls))
+	limiter := newHostLimiter(opts.HostInterval)
+
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Workers && w < len(urls); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := limiter.Wait(crawlCtx, urls[i]); err != nil {
+					results <- result{index: i, err: err}
+					continue
+				}
+				item, err := fetch(crawlCtx, urls[i])
+				results <- result{index: i, item: item, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for i := range urls {
+			select {
+			case jobs <- i:
+			case <-crawlCtx.Done():
+				return
+			}
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	items := make([]*NewsItem, len(urls))
+	received := 0
+collect:
+	for received < len(urls) {
+		select {
+		case r := <-results:
+			received++
+			if r.err != nil {
+				log.Printf("抓取失败 %s: %v", urls[r.index], r.err)
+				continue
+			}
+			item := r.item
+			items[r.index] = &item
+		case <-done:
+			// 所有worker已退出（可能因截止而未派发剩余任务），取完缓冲中的结果
+			for {
+				select {
+				case r := <-results:
+					if r.err == nil {
+						item := r.item
+						items[r.index] = &item
+					}
+				default:
+					break collect
+				}
+			}
+		case <-crawlCtx.Done():
+			log.Printf("抓取达到截止时间，返回已完成的 %d/%d 个页面", received, len(urls))
+			break collect
+		}
+	}
+
+	ordered := make([]NewsItem, 0, len(urls))
+	for _, item := range items {
+		if item != nil {
+			ordered = append(ordered, *item)
+		}
+	}
+	return ordered
+}
+
+// hostLimiter 按站点限制请求频率
+type hostLimiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     map[string]time.Time
+}
+
+func newHostLimiter(interval time.Duration) *hostLimiter {
+	return &hostLimiter{interval: interval, next: make(map[string]time.Time)}
+}
+
+// Wait 等待直到可以向该URL所在站点发起请求
+func (l *hostLimiter) Wait(ctx context.Context, rawURL string) error {
+	host := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		host = u.Host
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[host] = at.Add(l.interval)
+	l.mu.Unlock()
+
+	wait := time.Until(at)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// crawlServer 模拟站点：/page/N?delay=毫秒 延迟后返回标题为 N 的页面，/hang 一直不返回，其他路径返回404
+type crawlServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	active    int
+	maxActive int
+	starts    []time.Time
+}
+
+func newCrawlServer(t *testing.T) *crawlServer {
+	t.Helper()
+	s := &crawlServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page/{n}", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.active++
+		s.maxActive = max(s.maxActive, s.active)
+		s.starts = append(s.starts, time.Now())
+		s.mu.Unlock()
+		defer func() {
+			s.mu.Lock()
+			s.active--
+			s.mu.Unlock()
+		}()
+		delay, _ := strconv.Atoi(r.URL.Query().Get("delay"))
+		time.Sleep(time.Duration(delay) * time.Millisecond)
+		fmt.Fprintf(w, "<html><head><title>%s</title></head><body>第 %s 页</body></html>", r.PathValue("n"), r.PathValue("n"))
+	})
+	mux.HandleFunc("/hang", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// useCrawlOptions 测试期间使用指定的并发抓取参数
+func useCrawlOptions(t *testing.T, opts CrawlOptions) {
+	t.Helper()
+	old := crawlOptions
+	t.Cleanup(func() { crawlOptions = old })
+	crawlOptions = opts
+}
+
+// fetchTitle 用 httpFetcher 抓取页面，标题作为新闻项标题
+func fetchTitle(ctx context.Context, pageURL string) (NewsItem, error) {
+	page, err := (&httpFetcher{}).Fetch(ctx, pageURL, FetchOptions{})
+	if err != nil {
+		return NewsItem{}, err
+	}
+	return NewsItem{Title: page.Title, URL: pageURL}, nil
+}
+
+func titles(items []NewsItem) string {
+	var s []string
+	for _, item := range items {
+		s = append(s, item.Title)
+	}
+	return fmt.Sprint(s)
+}
+
+func TestCrawlPages(t *testing.T) {
+	useCrawlOptions(t, CrawlOptions{Workers: 3, HostInterval: time.Millisecond, Deadline: 10 * time.Second})
+	server := newCrawlServer(t)
+	// 越靠前的页面越慢，结果仍按传入顺序；失败的页面跳过
+	var urls []string
+	for i := 1; i <= 8; i++ {
+		urls = append(urls, fmt.Sprintf("%s/page/%d?delay=%d", server.URL, i, 90-10*i))
+	}
+	urls = append(urls[:4], append([]string{server.URL + "/missing"}, urls[4:]...)...)
+
+	items := crawlPages(context.Background(), urls, fetchTitle)
+	if got := titles(items); got != "[1 2 3 4 5 6 7 8]" {
+		t.Errorf("结果顺序 %s，期望 [1 2 3 4 5 6 7 8]", got)
+	}
+	if server.maxActive > 3 {
+		t.Errorf("同时抓取 %d 个页面，超过 3 个worker", server.maxActive)
+	}
+	if server.maxActive < 2 {
+		t.Errorf("同时抓取 %d 个页面，期望并发抓取", server.maxActive)
+	}
+
+	if items := crawlPages(context.Background(), nil, fetchTitle); len(items) != 0 {
+		t.Errorf("没有URL时返回 %v", items)
+	}
+}
+
+func TestCrawlPagesHostInterval(t *testing.T) {
+	const interval = 80 * time.Millisecond
+	useCrawlOptions(t, CrawlOptions{Workers: 6, HostInterval: interval, Deadline: 10 * time.Second})
+	a, b := newCrawlServer(t), newCrawlServer(t)
+	var urls []string
+	for i := 1; i <= 3; i++ {
+		urls = append(urls, fmt.Sprintf("%s/page/a%d", a.URL, i), fmt.Sprintf("%s/page/b%d", b.URL, i))
+	}
+	if items := crawlPages(context.Background(), urls, fetchTitle); len(items) != 6 {
+		t.Fatalf("抓取 %d 个页面，期望 6 个", len(items))
+	}
+	// 同一站点的请求间隔不小于 HostInterval，不同站点互不等待
+	for _, s := range []*crawlServer{a, b} {
+		if len(s.starts) != 3 {
+			t.Fatalf("站点收到 %d 个请求，期望 3 个", len(s.starts))
+		}
+		for i := 1; i < len(s.starts); i++ {
+			if gap := s.starts[i].Sub(s.starts[i-1]); gap < interval-5*time.Millisecond {
+				t.Errorf("同一站点请求间隔 %v，期望不小于 %v", gap, interval)
+			}
+		}
+	}
+	if gap := a.starts[0].Sub(b.starts[0]).Abs(); gap >= interval/2 {
+		t.Errorf("不同站点的首个请求相差 %v，不应互相等待", gap)
+	}
+}
+
+func TestCrawlPagesDeadline(t *testing.T) {
+	const deadline = 300 * time.Millisecond
+	useCrawlOptions(t, CrawlOptions{Workers: 2, HostInterval: time.Millisecond, Deadline: deadline})
+	server := newCrawlServer(t)
+	// 一个worker卡在不返回的页面上，截止时返回其余已完成的页面
+	urls := []string{server.URL + "/page/1", server.URL + "/hang", server.URL + "/page/3", server.URL + "/page/4"}
+
+	start := time.Now()
+	items := crawlPages(context.Background(), urls, fetchTitle)
+	if elapsed := time.Since(start); elapsed > deadline+2*time.Second {
+		t.Errorf("耗时 %v，应在截止时间后尽快返回", elapsed)
+	}
+	if got := titles(items); got != "[1 3 4]" {
+		t.Errorf("部分结果 %s，期望 [1 3 4]", got)
+	}
+
+	// 调用方的 ctx 取消时同样提前返回
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if items := crawlPages(ctx, []string{server.URL + "/hang", server.URL + "/hang"}, fetchTitle); len(items) != 0 {
+		t.Errorf("全部超时时返回 %s", titles(items))
+	}
+	if elapsed := time.Since(start); elapsed > deadline {
+		t.Errorf("ctx 取消后耗时 %v", elapsed)
+	}
+}
@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"stock_agent/financial"
+	"stock_agent/security"
+)
+
+var (
+	// emFinanceAPIURL 东方财富F10主要财务指标接口
+	emFinanceAPIURL = "https://datacenter.eastmoney.com/securities/api/data/v1/get"
+	// emCashFlowAPIURL 东方财富数据中心现金流量表接口
+	emCashFlowAPIURL = "https://datacenter-web.eastmoney.com/api/data/v1/get"
+)
+
+// emFinancialProvider 东方财富财务数据源，仅支持A股
+type emFinancialProvider struct{}
+
+func (emFinancialProvider) Name() string {
+	return sourceEastmoney
+}
+
+func (emFinancialProvider) Reports(ctx context.Context, symbol string, n int) ([]financial.Report, error) {
+	code, exchange := splitASymbol(symbol)
+	if code == "" {
+		return nil, fmt.Errorf("东方财富财务数据仅支持A股: %s", symbol)
+	}
+
+	rows, err := fetchEmDatacenter(ctx, emFinanceAPIURL, url.Values{
+		"reportName":  {"RPT_F10_FINANCE_MAINFINADATA"},
+		"columns":     {"REPORT_DATE,TOTALOPERATEREVE,PARENTNETPROFIT,ROEJQ,XSMLL,ZCFZL"},
+		"filter":      {fmt.Sprintf(`(SECUCODE="%s.%s")`, code, exchange)},
+		"pageNumber":  {"1"},
+		"pageSize":    {strconv.Itoa(n)},
+		"sortColumns": {"REPORT_DATE"},
+		"sortTypes":   {"-1"},
+		"source":      {"HSF10"},
+		"client":      {"PC"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, financial.ErrNoData
+	}
+
+	reports := make([]financial.Report, 0, len(rows))
+	for _, row := range rows {
+		date := emString(row["REPORT_DATE"])
+		if len(date) < 10 {
+			continue
+		}
+		reports = append(reports, financial.Report{
+			Date:        date[:10],
+			Revenue:     emOptional(row["TOTALOPERATEREVE"]),
+			NetProfit:   emOptional(row["PARENTNETPROFIT"]),
+			ROE:         emOptional(row["ROEJQ"]),
+			GrossMargin: emOptional(row["XSMLL"]),
+			DebtRatio:   emOptional(row["ZCFZL"]),
+		})
+	}
+
+	// 经营现金流来自现金流量表，获取失败时仅缺少该项
+	cashFlows, err := fetchEmCashFlows(ctx, code, n)
+	if err != nil {
+		log.Printf("获取 %s 现金流量表失败，已跳过: %v", symbol, err)
+	}
+	for i := range reports {
+		reports[i].OperatingCashFlow = cashFlows[reports[i].Date]
+	}
+	return reports, nil
+}
+
+// fetchEmCashFlows 获取经营活动现金流量净额，按报告期索引
+func fetchEmCashFlows(ctx context.Context, code string, n int) (map[string]*float64, error) {
+	rows, err := fetchEmDatacenter(ctx, emCashFlowAPIURL, url.Values{
+		"reportName":  {"RPT_DMSK_FN_CASHFLOW"},
+		"columns":     {"REPORT_DATE,NETCASH_OPERATE"},
+		"filter":      {fmt.Sprintf(`(SECURITY_CODE="%s")`, code)},
+		"pageNumber":  {"1"},
+		"pageSize":    {strconv.Itoa(n)},
+		"sortColumns": {"REPORT_DATE"},
+		"sortTypes":   {"-1"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	cashFlows := make(map[string]*float64, len(rows))
+	for _, row := range rows {
+		if date := emString(row["REPORT_DATE"]); len(date) >= 10 {
+			cashFlows[date[:10]] = emOptional(row["NETCASH_OPERATE"])
+		}
+	}
+	return cashFlows, nil
+}
+
+// fetchEmDatacenter 请求东方财富数据中心接口，返回数据行
+func fetchEmDatacenter(ctx context.Context, apiURL string, query url.Values) ([]map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", httpUserAgent)
+	req.Header.Set("Referer", "https://emweb.securities.eastmoney.com/")
+	resp, err := emClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求东方财富数据中心失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("请求东方财富数据中心失败: HTTP %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+		Result  *struct {
+			Data []map[string]any `json:"data"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析东方财富数据中心返回失败: %v", err)
+	}
+	// 没有数据时 result 为 null
+	if result.Result == nil {
+		return nil, nil
+	}
+	return result.Result.Data, nil
+}
+
+// emOptional 将接口字段转为可选数值，null 或 "-" 时返回 nil
+func emOptional(v any) *float64 {
+	switch v := v.(type) {
+	case float64:
+		return financial.Float(v)
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return financial.Float(f)
+		}
+	}
+	return nil
+}
+
+// splitASymbol 将A股代码拆分为代码和交易所，非A股返回空
+func splitASymbol(symbol string) (code, exchange string) {
+	symbol = normalizeXqSymbol(symbol)
+	for _, ex := range []string{security.ExchangeSH, security.ExchangeSZ, security.ExchangeBJ} {
+		if code, ok := strings.CutPrefix(symbol, ex); ok && len(code) == 6 {
+			return code, ex
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,162 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"stock_agent/kline"
+	"stock_agent/security"
+)
+
+// emKlineAPIURL 东方财富K线接口
+var emKlineAPIURL = "https://push2his.eastmoney.com/api/qt/stock/kline/get"
+
+// emKlinePeriods K线周期对应的接口参数 klt
+var emKlinePeriods = map[kline.Period]string{
+	kline.Day: "101", kline.Week: "102", kline.Month: "103",
+	kline.Min1: "1", kline.Min5: "5", kline.Min15: "15", kline.Min30: "30", kline.Min60: "60",
+}
+
+// emKlineProvider 东方财富K线数据源，价格为前复权价
+type emKlineProvider struct{}
+
+func (emKlineProvider) Name() string {
+	return sourceEastmoney
+}
+
+func (emKlineProvider) Bars(ctx context.Context, q kline.Query) ([]kline.Bar, error) {
+	klt, ok := emKlinePeriods[q.Period]
+	if !ok {
+		return nil, fmt.Errorf("不支持的K线周期: %s", q.Period)
+	}
+	secids := emSecIDs(q.Symbol)
+	if len(secids) == 0 {
+		return nil, fmt.Errorf("无法识别代码: %s", q.Symbol)
+	}
+	// 美股无法从代码判断交易所，依次尝试纳斯达克、纽交所、美交所
+	for _, secid := range secids {
+		bars, err := fetchEmKline(ctx, secid, klt, q)
+		if err != nil {
+			return nil, err
+		}
+		if len(bars) > 0 {
+			return kline.Filter(bars, q), nil
+		}
+	}
+	return nil, kline.ErrNoData
+}
+
+// emSecIDs 将雪球格式代码转为东方财富的 市场.代码
+func emSecIDs(symbol string) []string {
+	symbol = normalizeXqSymbol(symbol)
+	switch {
+	case strings.HasPrefix(symbol, security.ExchangeSH):
+		return []string{"1." + strings.TrimPrefix(symbol, security.ExchangeSH)}
+	case strings.HasPrefix(symbol, security.ExchangeSZ):
+		return []string{"0." + strings.TrimPrefix(symbol, security.ExchangeSZ)}
+	case strings.HasPrefix(symbol, security.ExchangeBJ):
+		return []string{"0." + strings.TrimPrefix(symbol, security.ExchangeBJ)}
+	}
+	switch security.InferExchange(symbol) {
+	case security.ExchangeHK:
+		return []string{"116." + symbol}
+	case security.ExchangeUS:
+		return []string{"105." + symbol, "106." + symbol, "107." + symbol}
+	}
+	return nil
+}
+
+// fetchEmKline 请求东方财富K线接口
+func fetchEmKline(ctx context.Context, secid, klt string, q kline.Query) ([]kline.Bar, error) {
+	begin, end := "0", "20500101"
+	if !q.Start.IsZero() {
+		begin = q.Start.Format("20060102")
+	}
+	if !q.End.IsZero() {
+		end = q.End.Format("20060102")
+	}
+	limit := "1000000"
+	if q.Count > 0 {
+		limit = strconv.Itoa(q.Count)
+	}
+	query := url.Values{}
+	query.Set("secid", secid)
+	query.Set("klt", klt)
+	query.Set("fqt", "1")
+	query.Set("beg", begin)
+	query.Set("end", end)
+	query.Set("lmt", limit)
+	query.Set("fields1", "f1,f2,f3")
+	query.Set("fields2", "f51,f52,f53,f54,f55,f56,f57")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, emKlineAPIURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", httpUserAgent)
+	req.Header.Set("Referer", "https://quote.eastmoney.com/")
+	resp, err := emClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求东方财富K线接口失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("请求东方财富K线接口失败: HTTP %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data *struct {
+			Klines []string `json:"klines"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析东方财富K线接口返回失败: %v", err)
+	}
+	if result.Data == nil {
+		return nil, nil
+	}
+	return parseEmKlines(result.Data.Klines, q.Period, strings.HasPrefix(secid, "0.") || strings.HasPrefix(secid, "1."))
+}
+
+// parseEmKlines 解析“时间,开盘,收盘,最高,最低,成交量,成交额”格式的K线，A股成交量单位为手，统一换算为股
+func parseEmKlines(lines []string, period kline.Period, volumeInLots bool) ([]kline.Bar, error) {
+	bars := make([]kline.Bar, 0, len(lines))
+	for _, line := range lines {
+		fields := strings.Split(line, ",")
+		if len(fields) < 7 {
+			return nil, fmt.Errorf("东方财富K线格式错误: %s", line)
+		}
+		var values [6]float64
+		for i := range values {
+			v, err := strconv.ParseFloat(fields[i+1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("东方财富K线格式错误: %s", line)
+			}
+			values[i] = v
+		}
+		// 接口返回的时间格式与 TimeLayout 一致，日线为日期，分钟线精确到分钟
+		if _, err := time.Parse(period.TimeLayout(), fields[0]); err != nil {
+			return nil, fmt.Errorf("东方财富K线时间格式错误: %s", fields[0])
+		}
+		bar := kline.Bar{
+			Time:   fields[0],
+			Open:   values[0],
+			Close:  values[1],
+			High:   values[2],
+			Low:    values[3],
+			Volume: values[4],
+			Amount: values[5],
+		}
+		if volumeInLots {
+			bar.Volume *= 100
+		}
+		bars = append(bars, bar)
+	}
+	return bars, nil
+}
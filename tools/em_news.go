@@ -0,0 +1,359 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/firebase/genkit/go/ai"
+)
+
+// SearchEmNewsInput 搜索东方财富资讯的输入参数
+type SearchEmNewsInput struct {
+	Keyword string `json:"keyword,omitempty" jsonschema_description:"要查询的股票关键词，例如：农业银行、贵州茅台"`
+	Symbol  string `json:"symbol,omitempty" jsonschema_description:"可选，analyzeInput 解析出的股票代码，如 SH601288；未提供 keyword 时按代码对应的简称搜索"`
+	Count   int    `json:"count,omitempty" jsonschema_description:"需要获取的资讯条数，默认20，最多100"`
+}
+
+// SearchGubaInput 获取股吧帖子的输入参数
+type SearchGubaInput struct {
+	Symbol  string `json:"symbol,omitempty" jsonschema_description:"股票代码，如 SH601288、601288、00700"`
+	Keyword string `json:"keyword,omitempty" jsonschema_description:"可选，未提供 symbol 时按简称解析股票代码，例如：农业银行"`
+	Count   int    `json:"count,omitempty" jsonschema_description:"需要获取的帖子数，默认30，最多100"`
+	Refresh bool   `json:"refresh,omitempty" jsonschema_description:"可选，忽略页面缓存重新抓取，用户要求获取最新内容时设为true"`
+}
+
+const (
+	defaultEmNewsCount = 20
+	maxEmNewsCount     = 100
+	defaultGubaCount   = 30
+	maxGubaCount       = 100
+	// maxGubaPages 股吧列表最多翻页数，每页约80条
+	maxGubaPages = 3
+)
+
+var (
+	// emNewsSearchURL 东方财富资讯搜索接口
+	emNewsSearchURL = "https://search-api-web.eastmoney.com/search/jsonp"
+	// gubaBaseURL 东方财富股吧地址
+	gubaBaseURL = "https://guba.eastmoney.com"
+)
+
+var (
+	emHighlightPattern = regexp.MustCompile(`</?em>`)
+	// gubaArticleListPattern 股吧列表页内嵌帖子数据的起始位置，数据本身按JSON解析到对象结束
+	gubaArticleListPattern = regexp.MustCompile(`var\s+article_list\s*=\s*`)
+)
+
+// SearchEmNews 搜索东方财富个股资讯（Genkit Tool）
+func SearchEmNews(ctx *ai.ToolContext, input SearchEmNewsInput) ([]NewsItem, error) {
+	if input.Keyword == "" && input.Symbol != "" {
+		input.Keyword = keywordForSymbol(input.Symbol)
+	}
+	if input.Keyword == "" {
+		return nil, fmt.Errorf("keyword 和 symbol 不能同时为空")
+	}
+	count := input.Count
+	if count <= 0 {
+		count = defaultEmNewsCount
+	}
+	if count > maxEmNewsCount {
+		count = maxEmNewsCount
+	}
+
+	log.Printf("搜索东方财富资讯: %s", input.Keyword)
+	searchCtx, cancel := context.WithTimeout(ctx.Context, 2*time.Minute)
+	defer cancel()
+	items, err := fetchEmNews(searchCtx, input.Keyword, count)
+	if err != nil {
+		return nil, fmt.Errorf("东方财富资讯搜索失败: %v", err)
+	}
+	log.Printf("东方财富资讯搜索成功，共获取 %d 条资讯", len(items))
+	return withSymbols(items, relatedSymbols(input.Keyword, input.Symbol)), nil
+}
+
+// fetchEmNews 请求东方财富资讯搜索接口，按发布时间倒序返回
+func fetchEmNews(ctx context.Context, keyword string, count int) ([]NewsItem, error) {
+	param, err := json.Marshal(map[string]any{
+		"uid":           "",
+		"keyword":       keyword,
+		"type":          []string{"cmsArticleWebOld"},
+		"client":        "web",
+		"clientType":    "web",
+		"clientVersion": "curr",
+		"param": map[string]any{
+			"cmsArticleWebOld": map[string]any{
+				"searchScope": "default",
+				"sort":        "time",
+				"pageIndex":   1,
+				"pageSize":    count,
+				"preTag":      "<em>",
+				"postTag":     "</em>",
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	query.Set("cb", "jQuery")
+	query.Set("param", string(param))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, emNewsSearchURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", httpUserAgent)
+	req.Header.Set("Referer", "https://so.eastmoney.com/")
+	resp, err := emClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求东方财富接口失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("请求东方财富接口失败: HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("读取东方财富接口返回失败: %v", err)
+	}
+	return parseEmNews(string(body))
+}
+
+// parseEmNews 解析资讯搜索接口的JSONP返回
+func parseEmNews(text string) ([]NewsItem, error) {
+	// 去掉 jQuery( ... ) 包装
+	if start, end := strings.Index(text, "("), strings.LastIndex(text, ")"); start >= 0 && end > start {
+		text = text[start+1 : end]
+	}
+	var result struct {
+		Result struct {
+			Articles []struct {
+				Date      string `json:"date"`
+				Title     string `json:"title"`
+				Content   string `json:"content"`
+				MediaName string `json:"mediaName"`
+				URL       string `json:"url"`
+			} `json:"cmsArticleWebOld"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		return nil, fmt.Errorf("解析东方财富接口返回失败: %v", err)
+	}
+
+	items := make([]NewsItem, 0, len(result.Result.Articles))
+	for _, a := range result.Result.Articles {
+		title := strings.TrimSpace(emHighlightPattern.ReplaceAllString(a.Title, ""))
+		if title == "" {
+			continue
+		}
+		item := newNewsItem(sourceEastmoney, sourceTypeNews)
+		item.Title = title
+		item.Content = strings.TrimSpace(emHighlightPattern.ReplaceAllString(a.Content, ""))
+		item.URL = a.URL
+		if media := strings.TrimSpace(a.MediaName); media != "" {
+			item.Tags = []string{media}
+		}
+		if published, ok := parseNewsTime(a.Date); ok {
+			item.SetPublished(published)
+		}
+		items = append(items, item)
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].PublishedAt.After(items[j].PublishedAt) })
+	return items, nil
+}
+
+// SearchGuba 获取东方财富股吧的最新帖子，作为散户情绪参考（Genkit Tool）
+func SearchGuba(ctx *ai.ToolContext, input SearchGubaInput) ([]NewsItem, error) {
+	symbol := input.Symbol
+	if symbol == "" && input.Keyword != "" {
+		if m := getSecurityMaster(); m != nil {
+			if candidates := m.Resolve(input.Keyword, 1); len(candidates) > 0 {
+				symbol = candidates[0].Symbol
+			}
+		}
+	}
+	code := gubaCode(symbol)
+	if code == "" {
+		return nil, fmt.Errorf("无法确定股吧代码，请提供股票代码 symbol")
+	}
+	count := input.Count
+	if count <= 0 {
+		count = defaultGubaCount
+	}
+	if count > maxGubaCount {
+		count = maxGubaCount
+	}
+
+	log.Printf("获取股吧帖子: %s", code)
+	searchCtx, cancel := context.WithTimeout(withCacheBypass(ctx.Context, input.Refresh), 5*time.Minute)
+	defer cancel()
+
+	var items []NewsItem
+	seen := make(map[string]bool)
+	for page := 1; page <= maxGubaPages && len(items) < count; page++ {
+		posts, err := fetchGubaPage(searchCtx, code, page)
+		if err != nil {
+			if len(items) > 0 {
+				log.Printf("获取股吧第 %d 页失败，返回已获取的帖子: %v", page, err)
+				break
+			}
+			return nil, fmt.Errorf("获取股吧帖子失败: %v", err)
+		}
+		if len(posts) == 0 {
+			break
+		}
+		for _, post := range posts {
+			if seen[post.URL] {
+				continue
+			}
+			seen[post.URL] = true
+			items = append(items, post)
+		}
+	}
+	// 列表默认按最后回复排序，改为按发帖时间倒序
+	sort.SliceStable(items, func(i, j int) bool { return items[i].PublishedAt.After(items[j].PublishedAt) })
+	if len(items) > count {
+		items = items[:count]
+	}
+	log.Printf("股吧帖子获取成功，共获取 %d 条帖子", len(items))
+	return withSymbols(items, relatedSymbols("", symbol)), nil
+}
+
+// gubaCode 返回股吧使用的代码：A股为6位代码，港股为 hk+代码，美股为 us+代码
+func gubaCode(symbol string) string {
+	symbol = normalizeXqSymbol(symbol)
+	if code, _ := splitASymbol(symbol); code != "" {
+		return code
+	}
+	switch {
+	case symbol == "":
+		return ""
+	case len(symbol) == 5 && strings.Trim(symbol, "0123456789") == "":
+		return "hk" + symbol
+	case strings.Trim(strings.ToUpper(symbol), "ABCDEFGHIJKLMNOPQRSTUVWXYZ.") == "":
+		return "us" + strings.ToLower(symbol)
+	}
+	return ""
+}
+
+// gubaListURL 返回股吧帖子列表页地址，第一页之后按规则的翻页地址（list,代码_页码.html）
+func gubaListURL(code string, page int) string {
+	return getRule("guba_list").PageURL(map[string]string{"base": gubaBaseURL, "code": code}, page)
+}
+
+// fetchGubaPage 抓取一页股吧帖子列表
+func fetchGubaPage(ctx context.Context, code string, page int) ([]NewsItem, error) {
+	fetched, err := getFetcher(sourceGuba).Fetch(ctx, gubaListURL(code, page), ruleFetchOptions(getRule("guba_list")))
+	if err != nil {
+		return nil, err
+	}
+	posts, err := parseGubaArticleList(fetched.HTML, code)
+	if err == nil {
+		return posts, nil
+	}
+	log.Printf("未解析到股吧内嵌数据，改为解析列表: %v", err)
+	doc, err := parseHTML(fetched)
+	if err != nil {
+		return nil, err
+	}
+	return parseGubaListHTML(fetched, doc, code, time.Now().In(shanghai)), nil
+}
+
+// parseGubaArticleList 解析列表页内嵌的 article_list 数据
+func parseGubaArticleList(html, code string) ([]NewsItem, error) {
+	loc := gubaArticleListPattern.FindStringIndex(html)
+	if loc == nil {
+		return nil, fmt.Errorf("页面中没有 article_list")
+	}
+	var list struct {
+		Posts []struct {
+			PostID       json.Number `json:"post_id"`
+			Title        string      `json:"post_title"`
+			Content      string      `json:"post_content"`
+			ClickCount   json.Number `json:"post_click_count"`
+			CommentCount json.Number `json:"post_comment_count"`
+			PublishTime  string      `json:"post_publish_time"`
+			Nickname     string      `json:"user_nickname"`
+			StockbarCode string      `json:"stockbar_code"`
+		} `json:"re"`
+	}
+	// 只解码第一个JSON值，帖子内容中的括号和之后的脚本不影响解析
+	if err := json.NewDecoder(strings.NewReader(html[loc[1]:])).Decode(&list); err != nil {
+		return nil, fmt.Errorf("解析 article_list 失败: %v", err)
+	}
+	posts := make([]NewsItem, 0, len(list.Posts))
+	for _, p := range list.Posts {
+		title := strings.TrimSpace(p.Title)
+		if title == "" || p.PostID == "" {
+			continue
+		}
+		bar := p.StockbarCode
+		if bar == "" {
+			bar = code
+		}
+		reads, _ := p.ClickCount.Int64()
+		comments, _ := p.CommentCount.Int64()
+		published, _ := parseNewsTime(p.PublishTime)
+		posts = append(posts, gubaPost(title, strings.TrimSpace(p.Content), p.Nickname,
+			fmt.Sprintf("%s/news,%s,%s.html", gubaBaseURL, bar, p.PostID), published, int(reads), int(comments)))
+	}
+	return posts, nil
+}
+
+// parseGubaListHTML 按提取规则解析列表表格，列表中的时间不含年份，按抓取时间推断
+func parseGubaListHTML(page *FetchedPage, doc *goquery.Document, code string, now time.Time) []NewsItem {
+	rule := getRule("guba_list")
+	var posts []NewsItem
+	for _, rec := range extractRecords(rule, page, doc, now) {
+		href := rec.Fields["url"]
+		// 资讯、公告转载的链接不在股吧站内，不算散户帖子
+		if !strings.Contains(href, "/news,") {
+			continue
+		}
+		reads := parseGubaCount(rec.Fields["reads"])
+		comments := parseGubaCount(rec.Fields["comments"])
+		posts = append(posts, gubaPost(rec.Fields["title"], "", rec.Fields["author"], resolveURL(gubaListURL(code, 1), href), rec.Time, reads, comments))
+	}
+	return posts
+}
+
+// gubaPost 生成股吧帖子新闻项，标记为论坛内容（散户情绪）
+func gubaPost(title, content, author, link string, published time.Time, reads, comments int) NewsItem {
+	item := newNewsItem(sourceGuba, sourceTypeForum)
+	item.Title = title
+	if content != title {
+		item.Content = truncateRunes(content, 500)
+	}
+	item.URL = link
+	item.Author = author
+	item.Tags = []string{"散户情绪"}
+	item.ReadCount = reads
+	item.CommentCount = comments
+	item.SetPublished(published)
+	return item
+}
+
+// parseGubaCount 解析阅读数和评论数，兼容 1.2万 的写法
+func parseGubaCount(text string) int {
+	text = strings.TrimSpace(text)
+	scale := 1.0
+	if n, ok := strings.CutSuffix(text, "万"); ok {
+		text, scale = n, 1e4
+	}
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0
+	}
+	return int(v * scale)
+}
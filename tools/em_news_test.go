@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"stock_agent/extractor"
+)
+
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestParseEmNews(t *testing.T) {
+	items, err := parseEmNews(readFixture(t, "em_news.jsonp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 标题为空的资讯跳过，按发布时间倒序
+	if len(items) != 2 {
+		t.Fatalf("资讯 %d 条，期望 2 条: %+v", len(items), items)
+	}
+	latest, earlier := items[0], items[1]
+	if latest.Title != "农业银行发布2025年一季报 净利润同比增长2.2%" || latest.Content != "4月29日晚间，农业银行披露一季报。" || len(latest.Tags) != 0 {
+		t.Errorf("第一条 = %+v", latest)
+	}
+	if want := time.Date(2025, 4, 29, 18, 30, 0, 0, shanghai); !latest.PublishedAt.Equal(want) {
+		t.Errorf("发布时间 = %v，期望 %v", latest.PublishedAt, want)
+	}
+	if earlier.Title != "农业银行：一季度净息差1.47%" || earlier.URL != "http://finance.eastmoney.com/a/202504293391000001.html" ||
+		len(earlier.Tags) != 1 || earlier.Tags[0] != "证券时报网" {
+		t.Errorf("第二条 = %+v", earlier)
+	}
+	for _, item := range items {
+		if item.Source != sourceEastmoney || item.SourceType != sourceTypeNews {
+			t.Errorf("来源 = %s/%s", item.Source, item.SourceType)
+		}
+	}
+
+	if _, err := parseEmNews("jQuery(<html>系统繁忙</html>)"); err == nil {
+		t.Error("返回不是JSON时应报错")
+	}
+}
+
+func TestParseGubaArticleList(t *testing.T) {
+	posts, err := parseGubaArticleList(readFixture(t, "guba_list.html"), "601288")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 内嵌数据跨多行、帖子内容带括号时仍能完整解析，没有标题的帖子跳过
+	if len(posts) != 2 {
+		t.Fatalf("帖子 %d 条，期望 2 条: %+v", len(posts), posts)
+	}
+	first := posts[0]
+	if first.Title != "农行分红到账了" || first.Content != "今年分红{每股0.2418元} var 了一下，股息率还是可以的" ||
+		first.Author != "价值投资老股民" || first.URL != gubaBaseURL+"/news,601288,1523456789.html" {
+		t.Errorf("第一条 = %+v", first)
+	}
+	if first.ReadCount != 12345 || first.CommentCount != 36 {
+		t.Errorf("阅读 %d，评论 %d，期望 12345、36", first.ReadCount, first.CommentCount)
+	}
+	if want := time.Date(2025, 4, 29, 18, 45, 10, 0, shanghai); !first.PublishedAt.Equal(want) {
+		t.Errorf("发帖时间 = %v，期望 %v", first.PublishedAt, want)
+	}
+	// 字符串形式的数字同样解析；内容与标题相同时不重复；没有股吧代码时使用请求的代码
+	second := posts[1]
+	if second.ReadCount != 860 || second.CommentCount != 4 || second.Content != "" || second.URL != gubaBaseURL+"/news,601288,1523456790.html" {
+		t.Errorf("第二条 = %+v", second)
+	}
+	for _, post := range posts {
+		if post.Source != sourceGuba || post.SourceType != sourceTypeForum || len(post.Tags) != 1 || post.Tags[0] != "散户情绪" {
+			t.Errorf("来源 = %s/%s，标签 = %v", post.Source, post.SourceType, post.Tags)
+		}
+	}
+
+	if _, err := parseGubaArticleList("<html><body>暂无数据</body></html>", "601288"); err == nil {
+		t.Error("页面没有内嵌数据时应报错")
+	}
+	if _, err := parseGubaArticleList("<script>var article_list = {\"re\": [</script>", "601288"); err == nil {
+		t.Error("内嵌数据不完整时应报错")
+	}
+}
+
+func TestParseGubaListHTML(t *testing.T) {
+	oldMonitor := healthMonitor
+	t.Cleanup(func() {
+		healthMonitor = oldMonitor
+		sourceWarningsMu.Lock()
+		delete(sourceWarnings, "guba_list")
+		sourceWarningsMu.Unlock()
+	})
+	healthMonitor, _ = extractor.NewMonitor("")
+
+	page := &FetchedPage{HTML: readFixture(t, "guba_list.html")}
+	doc, err := parseHTML(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 4, 30, 10, 0, 0, 0, shanghai)
+	posts := parseGubaListHTML(page, doc, "601288", now)
+	// 站外的资讯转载不算帖子
+	if len(posts) != 2 {
+		t.Fatalf("帖子 %d 条，期望 2 条: %+v", len(posts), posts)
+	}
+	first := posts[0]
+	if first.Title != "农行这波行情能走多远" || first.Author != "趋势猎手" || first.URL != gubaBaseURL+"/news,601288,1523400001.html" {
+		t.Errorf("第一条 = %+v", first)
+	}
+	if first.ReadCount != 12000 || first.CommentCount != 158 {
+		t.Errorf("阅读 %d，评论 %d，期望 12000、158", first.ReadCount, first.CommentCount)
+	}
+	// 列表时间不含年份，按抓取时间补全
+	if want := time.Date(2025, 4, 29, 20, 15, 0, 0, shanghai); !first.PublishedAt.Equal(want) {
+		t.Errorf("发帖时间 = %v，期望 %v", first.PublishedAt, want)
+	}
+	if posts[1].ReadCount != 356 || posts[1].CommentCount != 2 {
+		t.Errorf("第二条阅读 %d，评论 %d", posts[1].ReadCount, posts[1].CommentCount)
+	}
+	for _, post := range posts {
+		if post.SourceType != sourceTypeForum || len(post.Tags) != 1 || post.Tags[0] != "散户情绪" {
+			t.Errorf("来源类型 = %s，标签 = %v", post.SourceType, post.Tags)
+		}
+	}
+}
+
+func TestParseGubaCount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"158", 158},
+		{" 1.2万 ", 12000},
+		{"3万", 30000},
+		{"", 0},
+		{"-", 0},
+	}
+	for _, tt := range tests {
+		if got := parseGubaCount(tt.in); got != tt.want {
+			t.Errorf("parseGubaCount(%q) = %d，期望 %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGubaCode(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"SH601288", "601288"},
+		{"601288", "601288"},
+		{"000001.SZ", "000001"},
+		{"00700", "hk00700"},
+		{"0700.HK", "hk00700"},
+		{"aapl", "usaapl"},
+		{"农业银行", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := gubaCode(tt.in); got != tt.want {
+			t.Errorf("gubaCode(%q) = %q，期望 %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"stock_agent/security"
+)
+
+const sourceEastmoney = "eastmoney"
+
+// emListAPIURL 东方财富行情列表接口
+var emListAPIURL = "https://push2.eastmoney.com/api/qt/clist/get"
+
+// emBoardFilters 行业板块和概念板块的列表过滤条件
+var emBoardFilters = map[string]string{
+	security.SectorIndustry: "m:90+t:2+f:!50",
+	security.SectorConcept:  "m:90+t:3+f:!50",
+}
+
+// emBoard 东方财富板块
+type emBoard struct {
+	Code string // 板块代码，如 BK0478
+	Name string
+	Type string
+}
+
+var (
+	emBoardsMu sync.Mutex
+	emBoards   []emBoard
+	// emBoardsAt 板块列表的获取时间，板块变动不频繁，缓存一天
+	emBoardsAt time.Time
+)
+
+var emClient = &http.Client{Timeout: 30 * time.Second}
+
+// searchEmSectors 在东方财富行业和概念板块中查找名称匹配的板块，并获取按总市值降序的成分股
+func searchEmSectors(ctx context.Context, keyword string, count, limit int) ([]SectorResult, error) {
+	boards, err := getEmBoards(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keyword = security.TrimSectorSuffix(strings.TrimSpace(keyword))
+
+	// 名称完全相同的板块排在前面
+	var exact, partial []emBoard
+	for _, b := range boards {
+		switch {
+		case b.Name == keyword:
+			exact = append(exact, b)
+		case strings.Contains(b.Name, keyword) || strings.Contains(keyword, b.Name):
+			partial = append(partial, b)
+		}
+	}
+	matched := append(exact, partial...)
+	if len(matched) > limit {
+		matched = matched[:limit]
+	}
+
+	var results []SectorResult
+	for _, b := range matched {
+		stocks, err := fetchEmConstituents(ctx, b.Code, count)
+		if err != nil {
+			return results, fmt.Errorf("获取板块 %s 成分股失败: %v", b.Name, err)
+		}
+		results = append(results, SectorResult{Name: b.Name, Type: b.Type, Source: sourceEastmoney, Stocks: stocks})
+	}
+	return results, nil
+}
+
+// getEmBoards 返回东方财富全部行业和概念板块
+func getEmBoards(ctx context.Context) ([]emBoard, error) {
+	emBoardsMu.Lock()
+	defer emBoardsMu.Unlock()
+	if len(emBoards) > 0 && time.Since(emBoardsAt) < 24*time.Hour {
+		return emBoards, nil
+	}
+
+	var boards []emBoard
+	for _, boardType := range []string{security.SectorIndustry, security.SectorConcept} {
+		rows, err := fetchEmList(ctx, emBoardFilters[boardType], "f3", 1000, "f12,f14")
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			code, name := emString(row["f12"]), emString(row["f14"])
+			if code != "" && name != "" {
+				boards = append(boards, emBoard{Code: code, Name: name, Type: boardType})
+			}
+		}
+	}
+	if len(boards) == 0 {
+		return nil, fmt.Errorf("东方财富板块列表为空")
+	}
+	emBoards, emBoardsAt = boards, time.Now()
+	return boards, nil
+}
+
+// fetchEmConstituents 获取板块成分股，按总市值降序
+func fetchEmConstituents(ctx context.Context, boardCode string, count int) ([]SectorStock, error) {
+	rows, err := fetchEmList(ctx, "b:"+boardCode+"+f:!50", "f20", count, "f3,f12,f13,f14,f20")
+	if err != nil {
+		return nil, err
+	}
+	stocks := make([]SectorStock, 0, len(rows))
+	for _, row := range rows {
+		code := emString(row["f12"])
+		if code == "" {
+			continue
+		}
+		// f13 为市场：1 上海，0 深圳和北京
+		exchange := security.InferExchange(code)
+		if emString(row["f13"]) == "1" {
+			exchange = security.ExchangeSH
+		}
+		s := security.Security{Code: code, Exchange: exchange, Name: emString(row["f14"])}
+		stock := SectorStock{Symbol: s.Symbol(), Name: s.Name, ChangePercent: emNumber(row["f3"]), MarketCap: emNumber(row["f20"])}
+		if m := getSecurityMaster(); m != nil {
+			if known, ok := m.BySymbol(stock.Symbol); ok {
+				stock.Industry = known.Industry
+			}
+		}
+		stocks = append(stocks, stock)
+	}
+	return stocks, nil
+}
+
+// fetchEmList 请求东方财富行情列表接口，按 sortField 降序返回前 size 行
+func fetchEmList(ctx context.Context, filter, sortField string, size int, fields string) ([]map[string]any, error) {
+	query := url.Values{}
+	query.Set("pn", "1")
+	query.Set("pz", strconv.Itoa(size))
+	query.Set("po", "1")
+	query.Set("np", "1")
+	query.Set("fltt", "2")
+	query.Set("invt", "2")
+	query.Set("fid", sortField)
+	query.Set("fields", fields)
+	// 过滤条件中的 + 和 ! 需原样传递
+	apiURL := emListAPIURL + "?" + query.Encode() + "&fs=" + filter
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", httpUserAgent)
+	req.Header.Set("Referer", "https://quote.eastmoney.com/")
+	resp, err := emClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求东方财富接口失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("请求东方财富接口失败: HTTP %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data *struct {
+			Diff []map[string]any `json:"diff"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析东方财富接口返回失败: %v", err)
+	}
+	if result.Data == nil {
+		return nil, nil
+	}
+	return result.Data.Diff, nil
+}
+
+// emString 将接口字段转为字符串，停牌等无数据时接口返回 "-"
+func emString(v any) string {
+	switch v := v.(type) {
+	case string:
+		if v == "-" {
+			return ""
+		}
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// emNumber 将接口字段转为数值，无数据时返回0
+func emNumber(v any) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case string:
+		n, _ := strconv.ParseFloat(v, 64)
+		return n
+	}
+	return 0
+}
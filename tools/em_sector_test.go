@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"stock_agent/security"
+)
+
+// serveEmList 模拟东方财富行情列表接口，按过滤条件 fs 返回板块列表或成分股，返回请求过的过滤条件
+func serveEmList(t *testing.T, lists map[string][]map[string]any) *[]string {
+	t.Helper()
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 过滤条件中的 + 原样传递，不能按查询参数解码
+		_, fs, _ := strings.Cut(r.URL.RawQuery, "fs=")
+		requested = append(requested, fs)
+		rows, ok := lists[fs]
+		if !ok {
+			w.Write([]byte(`{"rc":0,"data":null}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"rc": 0, "data": map[string]any{"total": len(rows), "diff": rows}})
+	}))
+	t.Cleanup(server.Close)
+
+	oldURL, oldBoards, oldAt := emListAPIURL, emBoards, emBoardsAt
+	t.Cleanup(func() { emListAPIURL, emBoards, emBoardsAt = oldURL, oldBoards, oldAt })
+	emListAPIURL, emBoards, emBoardsAt = server.URL, nil, time.Time{}
+	return &requested
+}
+
+func TestSearchEmSectors(t *testing.T) {
+	oldMaster := getSecurityMaster()
+	t.Cleanup(func() { SetSecurityMaster(oldMaster) })
+	m := security.NewMaster()
+	m.Add(security.Security{Code: "601012", Exchange: security.ExchangeSH, Name: "隆基绿能", Industry: "光伏设备"})
+	SetSecurityMaster(m)
+
+	requested := serveEmList(t, map[string][]map[string]any{
+		"m:90+t:2+f:!50": {
+			{"f12": "BK1031", "f14": "光伏设备"},
+			{"f12": "BK0475", "f14": "银行"},
+			{"f12": "-", "f14": "停用板块"},
+		},
+		"m:90+t:3+f:!50": {
+			{"f12": "BK0478", "f14": "有色金属概念"},
+			{"f12": "BK0493", "f14": "光伏"},
+			{"f12": "BK1173", "f14": "光伏建筑一体化"},
+		},
+		"b:BK0493+f:!50": {
+			{"f3": 2.15, "f12": "601012", "f13": 1, "f14": "隆基绿能", "f20": 1.32e11},
+			{"f3": -0.8, "f12": "300274", "f13": 0, "f14": "阳光电源", "f20": 1.1e11},
+			{"f3": "-", "f12": "920118", "f13": 0, "f14": "太湖远大", "f20": "-"},
+			// 代码无法推断交易所的基金按 f13 归入上海
+			{"f3": 0.3, "f12": "515790", "f13": 1, "f14": "光伏ETF", "f20": 1e10},
+			{"f3": 0, "f12": "-", "f13": 1, "f14": "退市股"},
+		},
+		"b:BK1031+f:!50": {{"f3": 1.2, "f12": "601012", "f13": 1, "f14": "隆基绿能", "f20": 1.32e11}},
+	})
+	ctx := context.Background()
+
+	// 名称完全相同的概念板块排在包含匹配的行业板块之前，板块后缀去掉后再匹配
+	results, err := searchEmSectors(ctx, "光伏板块", 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Name != "光伏" || results[0].Type != security.SectorConcept || results[1].Name != "光伏设备" {
+		t.Fatalf("板块 = %+v，期望 光伏、光伏设备", results)
+	}
+	stocks := results[0].Stocks
+	var symbols []string
+	for _, s := range stocks {
+		symbols = append(symbols, s.Symbol)
+	}
+	if strings.Join(symbols, ",") != "SH601012,SZ300274,BJ920118,SH515790" {
+		t.Errorf("成分股 = %v", symbols)
+	}
+	if s := stocks[0]; s.Name != "隆基绿能" || s.ChangePercent != 2.15 || s.MarketCap != 1.32e11 || s.Industry != "光伏设备" || results[0].Source != sourceEastmoney {
+		t.Errorf("第一只成分股 = %+v", s)
+	}
+	if s := stocks[2]; s.ChangePercent != 0 || s.MarketCap != 0 {
+		t.Errorf("无数据的字段应为0: %+v", s)
+	}
+
+	// 板块列表缓存一天，不再重复请求
+	before := len(*requested)
+	if results, err := searchEmSectors(ctx, "银行", 10, 3); err != nil || len(results) != 1 || results[0].Type != security.SectorIndustry {
+		t.Errorf("银行 = %+v %v", results, err)
+	}
+	if got := (*requested)[before:]; len(got) != 1 || got[0] != "b:BK0475+f:!50" {
+		t.Errorf("第二次查询请求 %v，期望只请求成分股", got)
+	}
+	if results, err := searchEmSectors(ctx, "白酒", 10, 3); err != nil || len(results) != 0 {
+		t.Errorf("没有匹配的板块 = %+v %v", results, err)
+	}
+}
+
+func TestSearchEmSectorsEmptyBoards(t *testing.T) {
+	serveEmList(t, nil)
+	if _, err := searchEmSectors(context.Background(), "光伏", 10, 3); err == nil || !strings.Contains(err.Error(), "板块列表为空") {
+		t.Errorf("板块列表为空时 = %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"stock_agent/extractor"
+)
+
+var (
+	extractorRegistry *extractor.Registry
+
+	builtinRegistryOnce sync.Once
+	builtinRegistry     *extractor.Registry
+)
+
+// SetExtractorRegistry 设置页面提取规则（启动时加载内置规则和规则目录）
+func SetExtractorRegistry(r *extractor.Registry) {
+	extractorRegistry = r
+}
+
+// getRule 返回页面提取规则，未设置规则时使用内置规则
+// 规则缺失时返回空规则，列表和字段都不会命中，调用方按未找到处理
+func getRule(name string) *extractor.Rule {
+	registry := extractorRegistry
+	if registry == nil {
+		builtinRegistryOnce.Do(func() {
+			r, err := extractor.DefaultRegistry()
+			if err != nil {
+				log.Printf("加载内置提取规则失败: %v", err)
+				r = extractor.NewRegistry()
+			}
+			builtinRegistry = r
+		})
+		registry = builtinRegistry
+	}
+	if rule, ok := registry.Get(name); ok {
+		return rule
+	}
+	log.Printf("警告：缺少页面提取规则 %s", name)
+	return &extractor.Rule{Name: name}
+}
+
+// ruleFetchOptions 按规则的等待条件和翻页方式生成抓取选项，默认单页超时60秒，页面按规则名缓存
+func ruleFetchOptions(rule *extractor.Rule) FetchOptions {
+	opts := FetchOptions{
+		Timeout:      rule.Wait.Timeout,
+		WaitStable:   rule.Wait.Stable,
+		WaitSelector: rule.Wait.Selector,
+		LoadMoreText: rule.Pagination.LoadMore,
+		Cache:        rule.Name,
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 60 * time.Second
+	}
+	return opts
+}
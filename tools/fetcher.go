@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// 抓取方式
+const (
+	FetcherRod  = "rod"  // 使用无头浏览器渲染页面
+	FetcherHTTP = "http" // 直接使用 net/http 读取服务端渲染的页面
+)
+
+// 数据源名称，用于按来源选择抓取方式
+const (
+	sourceCLS    = "cls"
+	sourceXueqiu = "xueqiu"
+	sourceGuba   = "guba"
+)
+
+// FetchOptions 单次抓取的参数
+type FetchOptions struct {
+	Timeout    time.Duration // 单页超时，默认60秒
+	WaitStable bool          // 等待页面DOM稳定，仅对浏览器抓取有效
+
+	// 等待该选择器对应的元素出现，超时后仍继续获取内容，仅对浏览器抓取有效
+	WaitSelector string
+
+	// 点击文本为 LoadMoreText 的按钮加载更多内容，最多点击 LoadMoreTimes 次
+	// 仅对浏览器抓取有效
+	LoadMoreText  string
+	LoadMoreTimes int
+
+	// 页面缓存的类别，决定缓存有效期，为空时使用数据源名
+	Cache string
+}
+
+// FetchedPage 抓取到的页面
+type FetchedPage struct {
+	URL      string `json:"url"`      // 请求的URL
+	FinalURL string `json:"finalUrl"` // 跳转后的URL
+	Status   int    `json:"status"`   // HTTP状态码，浏览器抓取时为0表示未知
+	Title    string `json:"title"`
+	HTML     string `json:"html"`
+	Text     string `json:"text"` // 页面纯文本（已移除脚本和样式）
+	Stored   bool   `json:"-"`    // 页面来自缓存或存档回放，不是本次抓取的
+}
+
+// Fetcher 页面抓取接口
+type Fetcher interface {
+	Fetch(ctx context.Context, url string, opts FetchOptions) (*FetchedPage, error)
+}
+
+var (
+	defaultFetcherMode = FetcherRod
+	sourceFetcherModes = map[string]string{}
+)
+
+// SetFetcherModes 设置默认抓取方式以及各数据源的抓取方式
+func SetFetcherModes(defaultMode string, sources map[string]string) error {
+	if defaultMode == "" {
+		defaultMode = FetcherRod
+	}
+	if err := checkFetcherMode(defaultMode); err != nil {
+		return err
+	}
+	modes := make(map[string]string, len(sources))
+	for source, mode := range sources {
+		if err := checkFetcherMode(mode); err != nil {
+			return fmt.Errorf("数据源 %s: %v", source, err)
+		}
+		modes[source] = mode
+	}
+	defaultFetcherMode = defaultMode
+	sourceFetcherModes = modes
+	return nil
+}
+
+func checkFetcherMode(mode string) error {
+	switch mode {
+	case FetcherRod, FetcherHTTP:
+		return nil
+	}
+	return fmt.Errorf("不支持的抓取方式: %s", mode)
+}
+
+// getFetcher 按数据源返回配置的抓取实现，启用缓存时先查页面缓存，启用存档时记录或回放抓取结果
+func getFetcher(source string) Fetcher {
+	mode, ok := sourceFetcherModes[source]
+	if !ok {
+		mode = defaultFetcherMode
+	}
+	if mode == FetcherHTTP {
+		return archiveFetcher(cachedFetcher(&httpFetcher{}, source))
+	}
+	return archiveFetcher(cachedFetcher(&rodFetcher{}, source))
+}
+
+// parseHTML 将页面HTML解析为goquery文档
+func parseHTML(page *FetchedPage) (*goquery.Document, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page.HTML))
+	if err != nil {
+		return nil, fmt.Errorf("解析页面HTML失败: %v", err)
+	}
+	return doc, nil
+}
+
+// htmlText 获取页面纯文本内容（移除脚本和样式，每个文本节点一行）
+func htmlText(doc *goquery.Document) string {
+	body := doc.Find("body")
+	if body.Length() == 0 {
+		return ""
+	}
+	return selectionText(body)
+}
+
+// selectionText 获取选区内的纯文本，每个文本节点一行
+func selectionText(sel *goquery.Selection) string {
+	var lines []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "script", "style", "noscript":
+				return
+			}
+		}
+		if n.Type == html.TextNode {
+			if text := strings.TrimSpace(n.Data); text != "" {
+				lines = append(lines, text)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for _, n := range sel.Nodes {
+		walk(n)
+	}
+	return strings.Join(lines, "\n")
+}
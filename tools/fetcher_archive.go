@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+
+	"stock_agent/archive"
+)
+
+var (
+	archiveMode = archive.ModeOff
+	pageArchive *archive.Archive
+)
+
+// volatileParams 按运行时间计算、每次都不同的接口参数：雪球K线的 begin 默认为当前时间，
+// 巨潮公告的 seDate 默认为截至今天的时间窗口，回放时忽略这些参数匹配存档
+var volatileParams = []string{"begin", "seDate"}
+
+// SetArchive 设置抓取存档：record 记录每个页面和接口响应，replay 只从存档读取，off 或空字符串不使用存档
+func SetArchive(mode, dir string) error {
+	switch mode {
+	case "", archive.ModeOff:
+		archiveMode, pageArchive = archive.ModeOff, nil
+		setAPITransport(nil)
+		return nil
+	case archive.ModeRecord, archive.ModeReplay:
+	default:
+		return fmt.Errorf("不支持的存档模式: %s", mode)
+	}
+	// 页面和接口响应分开保存，同一URL既作为页面抓取又作为接口请求时互不覆盖
+	pages, err := archive.Open(filepath.Join(dir, "pages"))
+	if err != nil {
+		return err
+	}
+	api, err := archive.Open(filepath.Join(dir, "api"))
+	if err != nil {
+		return err
+	}
+	api.Volatile = volatileParams
+	archiveMode, pageArchive = mode, pages
+	setAPITransport(&archive.Transport{
+		Archive: api,
+		Replay:  mode == archive.ModeReplay,
+		OnError: func(err error) { log.Printf("%v", err) },
+	})
+	return nil
+}
+
+// setAPITransport 为各数据接口的HTTP客户端设置存档
+func setAPITransport(t http.RoundTripper) {
+	for _, client := range []*http.Client{emClient, cninfoClient, pdfClient, feedClient, getXqClient()} {
+		client.Transport = t
+	}
+}
+
+// archiveFetcher 根据存档模式包装页面抓取：记录模式下保存抓取结果，回放模式下只读存档
+func archiveFetcher(f Fetcher) Fetcher {
+	switch archiveMode {
+	case archive.ModeRecord:
+		return &recordingFetcher{next: f, archive: pageArchive}
+	case archive.ModeReplay:
+		return &replayFetcher{archive: pageArchive}
+	}
+	return f
+}
+
+// recordingFetcher 正常抓取页面并保存到存档
+type recordingFetcher struct {
+	next    Fetcher
+	archive *archive.Archive
+}
+
+func (f *recordingFetcher) Fetch(ctx context.Context, url string, opts FetchOptions) (*FetchedPage, error) {
+	page, err := f.next.Fetch(ctx, url, opts)
+	if err != nil {
+		return nil, err
+	}
+	err = f.archive.Save(archive.Entry{
+		URL:      url,
+		FinalURL: page.FinalURL,
+		Status:   page.Status,
+		Title:    page.Title,
+		Text:     page.Text,
+	}, []byte(page.HTML))
+	if err != nil {
+		// 存档失败不影响本次抓取
+		log.Printf("%v", err)
+	}
+	return page, nil
+}
+
+// replayFetcher 从存档读取页面，不访问网络，也不启动浏览器
+type replayFetcher struct {
+	archive *archive.Archive
+}
+
+func (f *replayFetcher) Fetch(_ context.Context, url string, _ FetchOptions) (*FetchedPage, error) {
+	e, body, err := f.archive.Load("", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("回放存档页面: %s（抓取于 %s）", url, e.Time.In(shanghai).Format("2006-01-02 15:04:05"))
+	return &FetchedPage{
+		URL:      url,
+		FinalURL: e.FinalURL,
+		Status:   e.Status,
+		Title:    e.Title,
+		HTML:     string(body),
+		Text:     e.Text,
+		Stored:   true,
+	}, nil
+}
@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"stock_agent/archive"
+)
+
+// stubFetcher 返回固定页面并记录抓取次数
+type stubFetcher struct {
+	page  FetchedPage
+	calls int
+}
+
+func (f *stubFetcher) Fetch(_ context.Context, url string, _ FetchOptions) (*FetchedPage, error) {
+	f.calls++
+	page := f.page
+	page.URL = url
+	return &page, nil
+}
+
+func TestArchiveRecordReplayPage(t *testing.T) {
+	dir := t.TempDir()
+	t.Cleanup(func() { SetArchive(archive.ModeOff, "") })
+	const pageURL = "https://www.cls.cn/detail/1001"
+
+	if err := SetArchive(archive.ModeRecord, dir); err != nil {
+		t.Fatal(err)
+	}
+	stub := &stubFetcher{page: FetchedPage{FinalURL: pageURL, Status: 200, Title: "标题", HTML: "<html><p>正文</p></html>", Text: "正文"}}
+	recorded, err := archiveFetcher(stub).Fetch(context.Background(), pageURL, FetchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetArchive(archive.ModeReplay, dir); err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := archiveFetcher(stub).Fetch(context.Background(), pageURL, FetchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 回放的页面标记为非本次抓取，不计入提取规则的基线
+	if !replayed.Stored || recorded.Stored {
+		t.Errorf("回放页面 Stored = %v，抓取页面 Stored = %v", replayed.Stored, recorded.Stored)
+	}
+	replayed.Stored = false
+	if *replayed != *recorded {
+		t.Errorf("回放页面 %+v，期望 %+v", *replayed, *recorded)
+	}
+	if stub.calls != 1 {
+		t.Errorf("回放模式不应抓取页面，实际抓取 %d 次", stub.calls)
+	}
+	if _, err := archiveFetcher(stub).Fetch(context.Background(), pageURL+"?page=2", FetchOptions{}); !errors.Is(err, archive.ErrNotFound) {
+		t.Errorf("未存档的页面应返回 ErrNotFound，得到 %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"net/url"
+	"strconv"
+	"time"
+
+	"stock_agent/archive"
+	"stock_agent/pagecache"
+)
+
+// cachePDF PDF下载的缓存类别，公告等PDF发布后不再变化
+const cachePDF = "pdf"
+
+// defaultCacheTTLs 各类页面的默认有效期，类别为提取规则名或数据源名
+var defaultCacheTTLs = map[string]time.Duration{
+	"cls_telegram":  2 * time.Minute,
+	"cls_depth":     10 * time.Minute,
+	"cls_article":   24 * time.Hour,
+	"xueqiu_search": time.Hour,
+	sourceXueqiu:    5 * time.Minute,
+	"guba_list":     5 * time.Minute,
+	cachePDF:        pagecache.Forever,
+}
+
+var (
+	pageCache       *pagecache.Cache
+	defaultCacheTTL = 10 * time.Minute
+	cacheTTLs       = maps.Clone(defaultCacheTTLs)
+)
+
+// SetPageCache 设置页面缓存目录和有效期，dir 为空时不使用缓存
+// ttls 按类别（提取规则名如 cls_telegram，或数据源名如 xueqiu）覆盖默认有效期，值如 2m、24h、forever，0 表示不缓存；
+// maxBytes 为缓存目录的大小上限，超出时删除最早的缓存，不大于0时不限制
+func SetPageCache(dir, defaultTTL string, ttls map[string]string, maxBytes int64) error {
+	if dir == "" {
+		pageCache = nil
+		return nil
+	}
+	fallback := 10 * time.Minute
+	if defaultTTL != "" {
+		ttl, err := pagecache.ParseTTL(defaultTTL)
+		if err != nil {
+			return err
+		}
+		fallback = ttl
+	}
+	merged := maps.Clone(defaultCacheTTLs)
+	for class, value := range ttls {
+		ttl, err := pagecache.ParseTTL(value)
+		if err != nil {
+			return fmt.Errorf("%s: %v", class, err)
+		}
+		merged[class] = ttl
+	}
+	pageCache = &pagecache.Cache{Dir: dir, MaxBytes: maxBytes}
+	defaultCacheTTL, cacheTTLs = fallback, merged
+	return nil
+}
+
+// PageCacheStats 返回页面缓存的命中统计
+func PageCacheStats() string {
+	if pageCache == nil {
+		return "页面缓存未启用"
+	}
+	return pageCache.String()
+}
+
+// cacheTTL 返回类别的有效期，未配置时依次使用数据源的有效期和默认有效期
+func cacheTTL(class, source string) time.Duration {
+	if ttl, ok := cacheTTLs[class]; ok {
+		return ttl
+	}
+	if ttl, ok := cacheTTLs[source]; ok {
+		return ttl
+	}
+	return defaultCacheTTL
+}
+
+type cacheBypassKey struct{}
+
+// withCacheBypass 返回忽略页面缓存的context，本次调用内的抓取都会重新请求并刷新缓存
+func withCacheBypass(ctx context.Context, bypass bool) context.Context {
+	if !bypass {
+		return ctx
+	}
+	return context.WithValue(ctx, cacheBypassKey{}, true)
+}
+
+// cacheBypassed 判断本次调用是否要求忽略缓存
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(cacheBypassKey{}).(bool)
+	return bypass
+}
+
+// cachedFetcher 未启用缓存或类别不缓存时直接返回原抓取实现
+func cachedFetcher(f Fetcher, source string) Fetcher {
+	if pageCache == nil {
+		return f
+	}
+	return &cachingFetcher{next: f, source: source}
+}
+
+// cachingFetcher 先查页面缓存，未命中时抓取并写入缓存
+type cachingFetcher struct {
+	next   Fetcher
+	source string
+}
+
+func (f *cachingFetcher) Fetch(ctx context.Context, rawURL string, opts FetchOptions) (*FetchedPage, error) {
+	class := cmp.Or(opts.Cache, f.source)
+	ttl := cacheTTL(class, f.source)
+	if ttl == 0 {
+		return f.next.Fetch(ctx, rawURL, opts)
+	}
+	key := pageCacheKey(rawURL, opts)
+	if cacheBypassed(ctx) {
+		pageCache.Bypass(class)
+	} else if data, ok := pageCache.Get(class, key, ttl); ok {
+		var page FetchedPage
+		if err := json.Unmarshal(data, &page); err == nil {
+			log.Printf("使用缓存页面: %s", rawURL)
+			page.Stored = true
+			return &page, nil
+		}
+	}
+
+	page, err := f.next.Fetch(ctx, rawURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(page)
+	if err == nil {
+		err = pageCache.Put(class, key, data)
+	}
+	if err != nil {
+		log.Printf("%v", err)
+	}
+	return page, nil
+}
+
+// pageCacheKey 返回页面的缓存键：加载更多的次数不同时页面内容不同，分开缓存
+func pageCacheKey(rawURL string, opts FetchOptions) string {
+	if opts.LoadMoreTimes <= 0 {
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	query := u.Query()
+	query.Set("_loadmore", strconv.Itoa(opts.LoadMoreTimes))
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// cachedDownload 按类别缓存下载内容；存档模式下不使用缓存，保证每个请求都经过存档
+func cachedDownload(ctx context.Context, class, rawURL string, download func() ([]byte, error)) ([]byte, error) {
+	ttl := cacheTTL(class, "")
+	if pageCache == nil || ttl == 0 || archiveMode != archive.ModeOff {
+		return download()
+	}
+	if cacheBypassed(ctx) {
+		pageCache.Bypass(class)
+	} else if data, ok := pageCache.Get(class, rawURL, ttl); ok {
+		log.Printf("使用缓存文件: %s", rawURL)
+		return data, nil
+	}
+	data, err := download()
+	if err != nil {
+		return nil, err
+	}
+	if err := pageCache.Put(class, rawURL, data); err != nil {
+		log.Printf("%v", err)
+	}
+	return data, nil
+}
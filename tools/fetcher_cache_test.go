@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"stock_agent/pagecache"
+)
+
+func TestCachingFetcher(t *testing.T) {
+	if err := SetPageCache(t.TempDir(), "10m", map[string]string{"xueqiu_search": "0"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetPageCache("", "", nil, 0) })
+	const pageURL = "https://xueqiu.com/S/SH601288"
+	stub := &stubFetcher{page: FetchedPage{Title: "农业银行", Text: "第一次"}}
+	f := cachedFetcher(stub, sourceXueqiu)
+	fetch := func(ctx context.Context, opts FetchOptions) string {
+		t.Helper()
+		page, err := f.Fetch(ctx, pageURL, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return page.Text
+	}
+	ctx := context.Background()
+
+	if page, _ := f.Fetch(ctx, pageURL, FetchOptions{}); page.Stored {
+		t.Error("新抓取的页面不应标记为缓存")
+	}
+	if page, _ := f.Fetch(ctx, pageURL, FetchOptions{}); page.Text != "第一次" || !page.Stored || stub.calls != 1 {
+		t.Errorf("第二次抓取 = %q Stored=%v，抓取 %d 次，期望使用缓存", page.Text, page.Stored, stub.calls)
+	}
+
+	// 忽略缓存时重新抓取并覆盖缓存
+	stub.page.Text = "第二次"
+	if text := fetch(withCacheBypass(ctx, true), FetchOptions{}); text != "第二次" || stub.calls != 2 {
+		t.Errorf("忽略缓存 = %q，抓取 %d 次", text, stub.calls)
+	}
+	if text := fetch(ctx, FetchOptions{}); text != "第二次" || stub.calls != 2 {
+		t.Errorf("刷新后 = %q，抓取 %d 次，期望使用新缓存", text, stub.calls)
+	}
+
+	// 加载更多次数不同的页面分开缓存
+	fetch(ctx, FetchOptions{LoadMoreTimes: 3})
+	fetch(ctx, FetchOptions{LoadMoreTimes: 3})
+	if stub.calls != 3 {
+		t.Errorf("加载更多的页面抓取 %d 次，期望 3 次", stub.calls)
+	}
+
+	// 有效期为0的类别不缓存
+	fetch(ctx, FetchOptions{Cache: "xueqiu_search"})
+	fetch(ctx, FetchOptions{Cache: "xueqiu_search"})
+	if stub.calls != 5 {
+		t.Errorf("不缓存的类别抓取 %d 次，期望 5 次", stub.calls)
+	}
+
+	stats := pageCache.Stats()
+	if s := stats[sourceXueqiu]; s.Hits != 3 || s.Bypassed != 1 || s.Stores != 3 {
+		t.Errorf("缓存统计 = %+v", s)
+	}
+	if _, ok := stats["xueqiu_search"]; ok {
+		t.Error("不缓存的类别不应计入统计")
+	}
+}
+
+func TestPageCacheKey(t *testing.T) {
+	const pageURL = "https://guba.eastmoney.com/list,601288.html?sort=1"
+	if got := pageCacheKey(pageURL, FetchOptions{}); got != pageURL {
+		t.Errorf("不加载更多时缓存键 = %q，期望原URL", got)
+	}
+	keys := map[string]bool{}
+	for _, times := range []int{0, 2, 5} {
+		keys[pageCacheKey(pageURL, FetchOptions{LoadMoreTimes: times})] = true
+	}
+	if len(keys) != 3 {
+		t.Errorf("加载更多次数不同的缓存键应各不相同: %v", keys)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	if err := SetPageCache(t.TempDir(), "3m", map[string]string{"cls_telegram": "1h", sourceGuba: "forever"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetPageCache("", "", nil, 0) })
+	tests := []struct {
+		class, source string
+		want          time.Duration
+	}{
+		{"cls_telegram", sourceCLS, time.Hour},
+		{"cls_article", sourceCLS, 24 * time.Hour},
+		// 类别已有默认有效期时不使用数据源的配置
+		{"guba_list", sourceGuba, 5 * time.Minute},
+		{"", sourceGuba, pagecache.Forever},
+		{"unknown", "unknown", 3 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := cacheTTL(tt.class, tt.source); got != tt.want {
+			t.Errorf("cacheTTL(%q, %q) = %s，期望 %s", tt.class, tt.source, got, tt.want)
+		}
+	}
+	if err := SetPageCache(t.TempDir(), "", map[string]string{"pdf": "一天"}, 0); err == nil {
+		t.Error("无效的有效期应返回错误")
+	}
+}
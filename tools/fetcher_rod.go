@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// rodFetcher 使用共享浏览器页面池抓取页面，适用于需要执行JS渲染的站点
+type rodFetcher struct{}
+
+func (f *rodFetcher) Fetch(ctx context.Context, url string, opts FetchOptions) (*FetchedPage, error) {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+
+	// 从页面池借用页面，用完归还；页面已绑定ctx，取消时所有导航、等待和脚本执行立即中止
+	page, err := acquirePage(ctx, timeout*2) // 给页面操作更多时间
+	if err != nil {
+		return nil, err
+	}
+	defer releasePage(page)
+
+	// 注意：用户代理已在 launcher 中设置，无需在页面中再次设置
+
+	// 重试机制：最多重试2次
+	maxRetries := 2
+	var lastErr error
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		// 检查context是否已取消
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("操作已取消: %v", ctx.Err())
+		default:
+		}
+
+		if attempt > 1 {
+			log.Printf("  重试连接 %s (第 %d 次)...", url, attempt)
+			if err := sleepContext(ctx, 2*time.Second); err != nil {
+				return nil, fmt.Errorf("操作已取消: %v", err)
+			}
+		}
+
+		// 导航到页面
+		err := page.Navigate(url)
+		if err == nil {
+			// 导航成功，检查页面是否加载
+			page.WaitLoad()
+			if err := sleepContext(ctx, 1*time.Second); err != nil {
+				return nil, fmt.Errorf("操作已取消: %v", err)
+			}
+			// 使用非panic版本，避免超时panic
+			pageInfo, err := page.Info()
+			if err == nil && pageInfo != nil && pageInfo.Title != "" {
+				lastErr = nil
+				break
+			}
+			if err != nil {
+				lastErr = err
+			} else {
+				lastErr = fmt.Errorf("页面加载失败：标题为空")
+			}
+		} else {
+			lastErr = err
+		}
+	}
+
+	if lastErr != nil {
+		return nil, fmt.Errorf("导航失败（已重试%d次）: %v", maxRetries, lastErr)
+	}
+
+	// 等待页面稳定（可选），使用更宽松的超时
+	if opts.WaitStable {
+		page.WaitLoad()
+		// 等待使用单独的context，超时或取消时先中止等待并等协程退出，避免页面归还页面池后仍被使用
+		waitCtx, stopWait := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		go func() {
+			// 增加等待稳定时间
+			done <- page.Context(waitCtx).Timeout(30 * time.Second).WaitStable(3 * time.Second)
+		}()
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(timeout):
+			// 即使超时也继续，可能页面已经部分加载
+			log.Printf("  页面加载超时，但继续尝试获取内容")
+		case <-done:
+		}
+		stopWait()
+		<-done
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("操作已取消: %v", err)
+		}
+
+		// 等待时间
+		if err := sleepContext(ctx, 2*time.Second); err != nil {
+			return nil, fmt.Errorf("操作已取消: %v", err)
+		}
+	}
+
+	// 等待规则指定的元素出现，超时时页面可能已部分加载，继续获取内容
+	if opts.WaitSelector != "" {
+		if _, err := page.Timeout(timeout).Element(opts.WaitSelector); err != nil {
+			log.Printf("  等待元素 %s 失败，继续获取内容: %v", opts.WaitSelector, err)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("操作已取消: %v", err)
+		}
+	}
+
+	// 点击“加载更多”直到达到次数或按钮消失
+	if opts.LoadMoreText != "" {
+		pattern := `^\s*` + regexp.QuoteMeta(opts.LoadMoreText) + `\s*$`
+		for i := 0; i < opts.LoadMoreTimes; i++ {
+			has, btn, err := page.HasR("a, button, div, span", pattern)
+			if err != nil || !has {
+				break
+			}
+			if err := btn.Click(proto.InputMouseButtonLeft, 1); err != nil {
+				log.Printf("  点击加载更多失败: %v", err)
+				break
+			}
+			page.Timeout(10 * time.Second).WaitStable(time.Second)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("操作已取消: %v", err)
+		}
+	}
+
+	// 获取渲染后的HTML
+	content, err := page.HTML()
+	if err != nil {
+		return nil, fmt.Errorf("获取页面内容失败: %v", err)
+	}
+	fetched := &FetchedPage{
+		URL:      url,
+		FinalURL: url,
+		HTML:     content,
+	}
+	if info, err := page.Info(); err == nil && info != nil {
+		fetched.Title = info.Title
+		fetched.FinalURL = info.URL
+	}
+
+	doc, err := parseHTML(fetched)
+	if err != nil {
+		return nil, err
+	}
+	fetched.Text = htmlText(doc)
+	return fetched, nil
+}
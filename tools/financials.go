@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"stock_agent/financial"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// GetFinancialsInput 获取财务数据的输入参数
+type GetFinancialsInput struct {
+	Symbol   string `json:"symbol" jsonschema_description:"A股代码，例如：SH601288、SZ000001、601288"`
+	Quarters int    `json:"quarters,omitempty" jsonschema_description:"返回最近的报告期数量，默认8，最多20"`
+}
+
+// Financials 最近若干报告期的主要财务指标
+type Financials struct {
+	Symbol    string             `json:"symbol"`
+	Name      string             `json:"name,omitempty"`
+	Source    string             `json:"source"`    // 数据来源：csv 或 eastmoney
+	FetchedAt string             `json:"fetchedAt"` // 数据获取时间，来自缓存时为缓存时间
+	Reports   []financial.Report `json:"reports"`   // 按报告期降序
+}
+
+const (
+	defaultFinancialQuarters = 8
+	maxFinancialQuarters     = 20
+	// analyzeFinancialQuarters 新闻分析时附带的报告期数量
+	analyzeFinancialQuarters = 4
+	// financialGrowthQuarters 计算同比需要额外获取的报告期数量
+	financialGrowthQuarters = 5
+)
+
+var (
+	financialProviders = financial.Chain{emFinancialProvider{}}
+	financialCache     *financial.Cache
+)
+
+// SetFinancialProviders 按顺序设置财务数据源：csv、eastmoney，前一个没有数据时尝试下一个
+// cacheDir 为空时不缓存
+func SetFinancialProviders(names []string, csvDir, cacheDir string, cacheTTL time.Duration) error {
+	var chain financial.Chain
+	for _, name := range names {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case sourceCSV:
+			chain = append(chain, financial.CSVProvider{Dir: csvDir})
+		case sourceEastmoney:
+			chain = append(chain, emFinancialProvider{})
+		default:
+			return fmt.Errorf("未知的财务数据源: %s，可选 csv、eastmoney", name)
+		}
+	}
+	if len(chain) > 0 {
+		financialProviders = chain
+	}
+	financialCache = nil
+	if cacheDir != "" {
+		financialCache = &financial.Cache{Dir: cacheDir, TTL: cacheTTL}
+	}
+	return nil
+}
+
+// GetFinancials 获取个股最近若干报告期的主要财务指标（Genkit Tool）
+func GetFinancials(ctx *ai.ToolContext, input GetFinancialsInput) (*Financials, error) {
+	symbol := normalizeXqSymbol(input.Symbol)
+	if symbol == "" {
+		return nil, fmt.Errorf("股票代码不能为空")
+	}
+	quarters := input.Quarters
+	if quarters <= 0 {
+		quarters = defaultFinancialQuarters
+	}
+	if quarters > maxFinancialQuarters {
+		quarters = maxFinancialQuarters
+	}
+
+	log.Printf("获取财务数据: %s", symbol)
+	financialCtx, cancel := context.WithTimeout(ctx.Context, 2*time.Minute)
+	defer cancel()
+	result, err := loadFinancials(financialCtx, symbol, quarters)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("财务数据获取成功: %s，来源 %s，共 %d 个报告期", symbol, result.Source, len(result.Reports))
+	return result, nil
+}
+
+// loadFinancials 优先读取有效期内的缓存，否则请求数据源并写入缓存；数据源失败时退回过期缓存
+func loadFinancials(ctx context.Context, symbol string, quarters int) (*Financials, error) {
+	// 多取一年的报告期用于计算同比
+	need := quarters + financialGrowthQuarters
+
+	var cached *financial.CacheEntry
+	if financialCache != nil {
+		entry, err := financialCache.Load(symbol)
+		if err != nil {
+			log.Printf("读取财务缓存失败，已忽略: %v", err)
+		}
+		if entry != nil && entry.Fresh(financialCache.TTL) && entry.Quarters >= need {
+			return newFinancials(entry, quarters), nil
+		}
+		cached = entry
+	}
+
+	reports, source, err := financialProviders.Reports(ctx, symbol, need)
+	if err != nil {
+		if cached != nil {
+			log.Printf("获取 %s 财务数据失败，使用 %s 的缓存: %v", symbol, cached.FetchedAt.In(shanghai).Format("2006-01-02 15:04"), err)
+			return newFinancials(cached, quarters), nil
+		}
+		return nil, fmt.Errorf("获取 %s 的财务数据失败: %v", symbol, err)
+	}
+	entry := &financial.CacheEntry{Symbol: symbol, Source: source, FetchedAt: time.Now(), Quarters: need, Reports: reports}
+	if financialCache != nil {
+		if err := financialCache.Store(entry); err != nil {
+			log.Printf("写入财务缓存失败: %v", err)
+		}
+	}
+	return newFinancials(entry, quarters), nil
+}
+
+// newFinancials 计算增速后截取最近的报告期
+func newFinancials(entry *financial.CacheEntry, quarters int) *Financials {
+	reports := financial.Compute(append([]financial.Report(nil), entry.Reports...))
+	if len(reports) > quarters {
+		reports = reports[:quarters]
+	}
+	name := keywordForSymbol(entry.Symbol)
+	if name == entry.Symbol {
+		name = ""
+	}
+	return &Financials{
+		Symbol:    entry.Symbol,
+		Name:      name,
+		Source:    entry.Source,
+		FetchedAt: entry.FetchedAt.In(shanghai).Format("2006-01-02 15:04:05"),
+		Reports:   reports,
+	}
+}
+
+// formatFinancialTable 将财务指标整理为提示词中的markdown表格，金额以亿元为单位
+func formatFinancialTable(f *Financials) string {
+	if len(f.Reports) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "主要财务指标（%s，收入和利润为年初累计，单位亿元，增速单位%%）:\n", f.Symbol)
+	b.WriteString("| 报告期 | 营业总收入 | 同比 | 归母净利润 | 同比 | 单季净利润环比 | ROE | 毛利率 | 资产负债率 | 经营现金流 |\n")
+	b.WriteString("|---|---|---|---|---|---|---|---|---|---|\n")
+	for _, r := range f.Reports {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+			r.Date,
+			formatOptional(r.Revenue, 1e8), formatOptional(r.RevenueYoY, 1),
+			formatOptional(r.NetProfit, 1e8), formatOptional(r.NetProfitYoY, 1),
+			formatOptional(r.QuarterNetProfitQoQ, 1),
+			formatOptional(r.ROE, 1), formatOptional(r.GrossMargin, 1), formatOptional(r.DebtRatio, 1),
+			formatOptional(r.OperatingCashFlow, 1e8))
+	}
+	return b.String()
+}
+
+// formatOptional 按单位格式化可选数值，缺失时显示 -
+func formatOptional(v *float64, unit float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", *v/unit)
+}
@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stock_agent/financial"
+)
+
+// stubFinancialProvider 返回固定报告期或错误，并记录请求次数
+type stubFinancialProvider struct {
+	reports []financial.Report
+	err     error
+	calls   int
+}
+
+func (p *stubFinancialProvider) Name() string { return "stub" }
+
+func (p *stubFinancialProvider) Reports(_ context.Context, _ string, _ int) ([]financial.Report, error) {
+	p.calls++
+	return p.reports, p.err
+}
+
+func TestLoadFinancials(t *testing.T) {
+	oldProviders, oldCache := financialProviders, financialCache
+	t.Cleanup(func() { financialProviders, financialCache = oldProviders, oldCache })
+	cache := &financial.Cache{Dir: t.TempDir(), TTL: 24 * time.Hour}
+	financialCache = cache
+	stub := &stubFinancialProvider{}
+	financialProviders = financial.Chain{stub}
+
+	const symbol = "SH601288"
+	need := 4 + financialGrowthQuarters
+	store := func(age time.Duration, quarters int, date string) {
+		t.Helper()
+		entry := &financial.CacheEntry{Symbol: symbol, Source: "eastmoney", FetchedAt: time.Now().Add(-age), Quarters: quarters,
+			Reports: []financial.Report{{Date: date, Revenue: financial.Float(1)}}}
+		if err := cache.Store(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	load := func() (*Financials, error) {
+		t.Helper()
+		return loadFinancials(context.Background(), symbol, 4)
+	}
+
+	// 有效期内且报告期足够时直接使用缓存
+	store(time.Hour, need, "2024-12-31")
+	result, err := load()
+	if err != nil || stub.calls != 0 || result.Source != "eastmoney" {
+		t.Fatalf("有效缓存: %+v %v，请求 %d 次", result, err, stub.calls)
+	}
+
+	// 缓存过期且数据源失败时退回过期缓存
+	store(48*time.Hour, need, "2024-09-30")
+	stub.err = errors.New("接口超时")
+	result, err = load()
+	if err != nil || stub.calls != 1 || result.Reports[0].Date != "2024-09-30" {
+		t.Fatalf("过期缓存: %+v %v，请求 %d 次", result, err, stub.calls)
+	}
+
+	// 缓存的报告期不够时重新请求，成功后写入缓存
+	store(time.Hour, need-1, "2024-06-30")
+	stub.err = nil
+	stub.reports = []financial.Report{{Date: "2025-03-31", Revenue: financial.Float(2)}}
+	result, err = load()
+	if err != nil || stub.calls != 2 || result.Source != "stub" || result.Reports[0].Date != "2025-03-31" {
+		t.Fatalf("报告期不足: %+v %v，请求 %d 次", result, err, stub.calls)
+	}
+	entry, err := cache.Load(symbol)
+	if err != nil || entry.Source != "stub" || entry.Quarters != need || !entry.Fresh(cache.TTL) {
+		t.Errorf("写入的缓存 = %+v %v", entry, err)
+	}
+
+	// 没有缓存且数据源失败时返回错误
+	financialCache = &financial.Cache{Dir: t.TempDir(), TTL: 24 * time.Hour}
+	stub.err = errors.New("接口超时")
+	if _, err := load(); err == nil {
+		t.Error("没有缓存且数据源失败时应返回错误")
+	}
+}
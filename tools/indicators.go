@@ -0,0 +1,262 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"stock_agent/indicator"
+	"stock_agent/kline"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// GetIndicatorsInput 计算技术指标的输入参数
+type GetIndicatorsInput struct {
+	Symbol string `json:"symbol" jsonschema_description:"股票代码，例如：SH601288、SZ000001、601288、00700、AAPL"`
+	Period string `json:"period,omitempty" jsonschema_description:"K线周期：day（日线，默认）、week、month、1m、5m、15m、30m、60m"`
+	End    string `json:"end,omitempty" jsonschema_description:"计算截至的日期，格式 2006-01-02，可选，默认截至最新"`
+}
+
+// IndicatorReport 最新一根K线的技术指标读数，数据不足以计算的指标不返回
+type IndicatorReport struct {
+	Symbol      string             `json:"symbol"`
+	Name        string             `json:"name,omitempty"`
+	Period      string             `json:"period"`
+	Source      string             `json:"source"`
+	Time        string             `json:"time"` // 最新K线时间
+	Close       float64            `json:"close"`
+	MA          map[string]float64 `json:"ma,omitempty"`  // MA5、MA10、MA20、MA60
+	EMA         map[string]float64 `json:"ema,omitempty"` // EMA12、EMA26
+	MACD        *MACDValue         `json:"macd,omitempty"`
+	RSI         map[string]float64 `json:"rsi,omitempty"` // RSI6、RSI12、RSI24
+	KDJ         *KDJValue          `json:"kdj,omitempty"`
+	BOLL        *BOLLValue         `json:"boll,omitempty"`
+	ATR         float64            `json:"atr,omitempty"`         // ATR14
+	VolumeRatio float64            `json:"volumeRatio,omitempty"` // 量比（当根成交量/前5根平均）
+	Signals     []string           `json:"signals,omitempty"`     // 由指标读数得出的形态信号
+}
+
+// MACDValue MACD(12,26,9) 读数
+type MACDValue struct {
+	DIF  float64 `json:"dif"`
+	DEA  float64 `json:"dea"`
+	Hist float64 `json:"hist"`
+}
+
+// KDJValue KDJ(9,3,3) 读数
+type KDJValue struct {
+	K float64 `json:"k"`
+	D float64 `json:"d"`
+	J float64 `json:"j"`
+}
+
+// BOLLValue BOLL(20,2) 读数
+type BOLLValue struct {
+	Upper float64 `json:"upper"`
+	Mid   float64 `json:"mid"`
+	Lower float64 `json:"lower"`
+}
+
+// indicatorBarCount 计算指标使用的K线数量，EMA类指标需要足够的数据收敛
+const indicatorBarCount = 250
+
+// GetIndicators 计算个股最新的技术指标（Genkit Tool）
+func GetIndicators(ctx *ai.ToolContext, input GetIndicatorsInput) (*IndicatorReport, error) {
+	symbol := normalizeXqSymbol(input.Symbol)
+	if symbol == "" {
+		return nil, fmt.Errorf("股票代码不能为空")
+	}
+	period, err := kline.ParsePeriod(input.Period)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseDateInput(input.End)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("计算技术指标: %s %s", symbol, period)
+	indicatorCtx, cancel := context.WithTimeout(ctx.Context, 2*time.Minute)
+	defer cancel()
+	history, err := loadPriceHistory(indicatorCtx, kline.Query{Symbol: symbol, Period: period, End: end, Count: indicatorBarCount})
+	if err != nil {
+		return nil, err
+	}
+	report := computeIndicators(history)
+	if report == nil {
+		return nil, fmt.Errorf("%s 没有K线数据，无法计算指标", symbol)
+	}
+	return report, nil
+}
+
+// computeIndicators 基于K线计算最新一根K线的指标读数
+func computeIndicators(h *PriceHistory) *IndicatorReport {
+	bars := h.Bars
+	if len(bars) == 0 {
+		return nil
+	}
+	closes := indicator.Closes(bars)
+	last := len(bars) - 1
+	report := &IndicatorReport{
+		Symbol: h.Symbol,
+		Name:   h.Name,
+		Period: h.Period,
+		Source: h.Source,
+		Time:   bars[last].Time,
+		Close:  bars[last].Close,
+	}
+
+	// 均线只在数据足够时返回，EMA 至少需要参数长度的数据才有参考意义
+	for _, n := range []int{5, 10, 20, 60} {
+		if v := indicator.Last(indicator.MA(closes, n)); indicator.Valid(v) {
+			report.MA = setReading(report.MA, fmt.Sprintf("MA%d", n), v)
+		}
+	}
+	for _, n := range []int{12, 26} {
+		if len(closes) >= n {
+			report.EMA = setReading(report.EMA, fmt.Sprintf("EMA%d", n), indicator.Last(indicator.EMA(closes, n)))
+		}
+	}
+	macd := indicator.MACD(closes, 12, 26, 9)
+	if len(closes) >= 26+9 {
+		report.MACD = &MACDValue{DIF: round(macd.DIF[last]), DEA: round(macd.DEA[last]), Hist: round(macd.Hist[last])}
+	}
+	for _, n := range []int{6, 12, 24} {
+		if len(closes) > n {
+			report.RSI = setReading(report.RSI, fmt.Sprintf("RSI%d", n), indicator.Last(indicator.RSI(closes, n)))
+		}
+	}
+	kdj := indicator.KDJ(bars, 9, 3, 3)
+	if indicator.Valid(kdj.K[last]) {
+		report.KDJ = &KDJValue{K: round(kdj.K[last]), D: round(kdj.D[last]), J: round(kdj.J[last])}
+	}
+	boll := indicator.BOLL(closes, 20, 2)
+	if indicator.Valid(boll.Mid[last]) {
+		report.BOLL = &BOLLValue{Upper: round(boll.Upper[last]), Mid: round(boll.Mid[last]), Lower: round(boll.Lower[last])}
+	}
+	if v := indicator.Last(indicator.ATR(bars, 14)); indicator.Valid(v) {
+		report.ATR = round(v)
+	}
+	if v := indicator.Last(indicator.VolumeRatio(indicator.Volumes(bars), 5)); indicator.Valid(v) {
+		report.VolumeRatio = round(v)
+	}
+
+	report.Signals = indicatorSignals(report, macd, kdj)
+	return report
+}
+
+// indicatorSignals 根据指标读数给出确定性的形态描述，避免由大模型自行推断
+func indicatorSignals(r *IndicatorReport, macd indicator.MACDResult, kdj indicator.KDJResult) []string {
+	var signals []string
+	last := len(macd.DIF) - 1
+
+	if ma5, ok := r.MA["MA5"]; ok {
+		ma10, ma20, ma60 := r.MA["MA10"], r.MA["MA20"], r.MA["MA60"]
+		switch {
+		case ma60 > 0 && ma5 > ma10 && ma10 > ma20 && ma20 > ma60:
+			signals = append(signals, "均线多头排列（MA5>MA10>MA20>MA60）")
+		case ma60 > 0 && ma5 < ma10 && ma10 < ma20 && ma20 < ma60:
+			signals = append(signals, "均线空头排列（MA5<MA10<MA20<MA60）")
+		}
+	}
+	if r.MACD != nil && last > 0 {
+		switch {
+		case macd.DIF[last-1] <= macd.DEA[last-1] && macd.DIF[last] > macd.DEA[last]:
+			signals = append(signals, "MACD金叉（DIF上穿DEA）")
+		case macd.DIF[last-1] >= macd.DEA[last-1] && macd.DIF[last] < macd.DEA[last]:
+			signals = append(signals, "MACD死叉（DIF下穿DEA）")
+		}
+	}
+	if rsi, ok := r.RSI["RSI6"]; ok {
+		switch {
+		case rsi >= 80:
+			signals = append(signals, fmt.Sprintf("RSI6超买（%.2f≥80）", rsi))
+		case rsi <= 20:
+			signals = append(signals, fmt.Sprintf("RSI6超卖（%.2f≤20）", rsi))
+		}
+	}
+	if r.KDJ != nil && last > 0 && indicator.Valid(kdj.K[last-1]) {
+		switch {
+		case kdj.K[last-1] <= kdj.D[last-1] && kdj.K[last] > kdj.D[last]:
+			signals = append(signals, "KDJ金叉（K上穿D）")
+		case kdj.K[last-1] >= kdj.D[last-1] && kdj.K[last] < kdj.D[last]:
+			signals = append(signals, "KDJ死叉（K下穿D）")
+		}
+		switch {
+		case r.KDJ.J > 100:
+			signals = append(signals, "KDJ超买（J>100）")
+		case r.KDJ.J < 0:
+			signals = append(signals, "KDJ超卖（J<0）")
+		}
+	}
+	if r.BOLL != nil {
+		switch {
+		case r.Close > r.BOLL.Upper:
+			signals = append(signals, "收盘价突破布林上轨")
+		case r.Close < r.BOLL.Lower:
+			signals = append(signals, "收盘价跌破布林下轨")
+		}
+	}
+	switch {
+	case r.VolumeRatio >= 2:
+		signals = append(signals, fmt.Sprintf("明显放量（量比%.2f）", r.VolumeRatio))
+	case r.VolumeRatio > 0 && r.VolumeRatio <= 0.5:
+		signals = append(signals, fmt.Sprintf("明显缩量（量比%.2f）", r.VolumeRatio))
+	}
+	return signals
+}
+
+// formatIndicatorReport 将指标读数整理为提示词中的文本块
+func formatIndicatorReport(r *IndicatorReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "技术指标（%s，%s线，截至 %s）:\n", r.Symbol, periodName(r.Period), r.Time)
+	writeReadings := func(label string, readings map[string]float64, keys ...string) {
+		var parts []string
+		for _, key := range keys {
+			if v, ok := readings[key]; ok {
+				parts = append(parts, fmt.Sprintf("%s %.2f", key, v))
+			}
+		}
+		if len(parts) > 0 {
+			fmt.Fprintf(&b, "%s: %s\n", label, strings.Join(parts, "，"))
+		}
+	}
+	writeReadings("均线", r.MA, "MA5", "MA10", "MA20", "MA60")
+	if r.MACD != nil {
+		fmt.Fprintf(&b, "MACD(12,26,9): DIF %.4f，DEA %.4f，MACD柱 %.4f\n", r.MACD.DIF, r.MACD.DEA, r.MACD.Hist)
+	}
+	writeReadings("RSI", r.RSI, "RSI6", "RSI12", "RSI24")
+	if r.KDJ != nil {
+		fmt.Fprintf(&b, "KDJ(9,3,3): K %.2f，D %.2f，J %.2f\n", r.KDJ.K, r.KDJ.D, r.KDJ.J)
+	}
+	if r.BOLL != nil {
+		fmt.Fprintf(&b, "BOLL(20,2): 上轨 %.2f，中轨 %.2f，下轨 %.2f\n", r.BOLL.Upper, r.BOLL.Mid, r.BOLL.Lower)
+	}
+	if r.ATR > 0 {
+		fmt.Fprintf(&b, "ATR(14): %.4f\n", r.ATR)
+	}
+	if r.VolumeRatio > 0 {
+		fmt.Fprintf(&b, "量比: %.2f\n", r.VolumeRatio)
+	}
+	if len(r.Signals) > 0 {
+		fmt.Fprintf(&b, "信号: %s\n", strings.Join(r.Signals, "；"))
+	}
+	return b.String()
+}
+
+func setReading(readings map[string]float64, key string, v float64) map[string]float64 {
+	if readings == nil {
+		readings = make(map[string]float64)
+	}
+	readings[key] = round(v)
+	return readings
+}
+
+// round 保留4位小数，便于在报告中直接引用
+func round(v float64) float64 {
+	return math.Round(v*1e4) / 1e4
+}
@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"stock_agent/dedup"
+)
+
+// dedupTextRunes 参与查重的正文长度，整页抓取的内容后半部分多为页面杂项
+const dedupTextRunes = 500
+
+// dedupNewsItems 合并不同来源转载的近似重复新闻，顺序与每簇第一条新闻的位置一致
+// 每簇保留一条（优先专业新闻，其次正文最长），发布时间取簇内最早，
+// 其余链接和来源记录在 relatedUrls、sources 中，簇大小记为 clusterSize
+func dedupNewsItems(items []NewsItem) []NewsItem {
+	texts := make([]string, len(items))
+	for i, item := range items {
+		texts[i] = dedupText(item)
+	}
+	clusters := dedup.Cluster(texts, dedup.DefaultDistance)
+	merged := make([]NewsItem, 0, len(clusters))
+	for _, members := range clusters {
+		merged = append(merged, mergeNewsCluster(items, members))
+	}
+	return merged
+}
+
+// dedupText 返回用于查重的文本：标题加正文开头，正文已包含标题时不重复
+func dedupText(item NewsItem) string {
+	text := item.Content
+	if !strings.Contains(text, item.Title) {
+		text = item.Title + "\n" + text
+	}
+	if utf8.RuneCountInString(text) > dedupTextRunes {
+		text = string([]rune(text)[:dedupTextRunes])
+	}
+	return text
+}
+
+// mergeNewsCluster 将一簇相似新闻合并为一条
+func mergeNewsCluster(items []NewsItem, members []int) NewsItem {
+	best := members[0]
+	for _, i := range members[1:] {
+		if betterNewsItem(items[i], items[best]) {
+			best = i
+		}
+	}
+	merged := items[best]
+	merged.RelatedURLs, merged.Sources = nil, nil
+	merged.Symbols = slices.Clone(merged.Symbols)
+
+	size := 0
+	earliest := merged.PublishedAt
+	seenURL := make(map[string]bool)
+	addURL := func(u string) {
+		if u != "" && !seenURL[u] {
+			seenURL[u] = true
+			if u != merged.URL {
+				merged.RelatedURLs = append(merged.RelatedURLs, u)
+			}
+		}
+	}
+	addSource := func(source string) {
+		if source != "" && !slices.Contains(merged.Sources, source) {
+			merged.Sources = append(merged.Sources, source)
+		}
+	}
+	addSource(merged.Source)
+	// 代表新闻排在最前，其余成员中与已有链接相同的视为同一篇，不计入簇大小
+	order := append([]int{best}, slices.DeleteFunc(slices.Clone(members), func(i int) bool { return i == best })...)
+	for _, i := range order {
+		item := items[i]
+		if item.URL != "" && seenURL[item.URL] {
+			continue
+		}
+		// 输入可能已经去重过，按原有的簇大小累加
+		size += max(1, item.ClusterSize)
+		addURL(item.URL)
+		for _, u := range item.RelatedURLs {
+			addURL(u)
+		}
+		addSource(item.Source)
+		for _, source := range item.Sources {
+			addSource(source)
+		}
+		for _, symbol := range item.Symbols {
+			if !slices.Contains(merged.Symbols, symbol) {
+				merged.Symbols = append(merged.Symbols, symbol)
+			}
+		}
+		if !item.PublishedAt.IsZero() && (earliest.IsZero() || item.PublishedAt.Before(earliest)) {
+			earliest = item.PublishedAt
+		}
+	}
+	merged.ClusterSize = size
+	merged.SetPublished(earliest)
+	return merged
+}
+
+// betterNewsItem 判断 a 是否比 b 更适合作为簇的代表：专业新闻优先于论坛帖子，其次正文更长
+func betterNewsItem(a, b NewsItem) bool {
+	if (a.SourceType == sourceTypeForum) != (b.SourceType == sourceTypeForum) {
+		return b.SourceType == sourceTypeForum
+	}
+	return utf8.RuneCountInString(a.Content) > utf8.RuneCountInString(b.Content)
+}
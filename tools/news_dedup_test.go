@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const dedupReport = "中国农业银行今日发布公告称，一季度实现营业收入1860亿元，同比增长2.3%；归属于母公司股东的净利润718亿元，同比增长1.6%。" +
+	"净息差为1.53%，较上年末下降7个基点，不良贷款率1.33%，拨备覆盖率保持在300%以上。"
+
+func TestDedupNewsItems(t *testing.T) {
+	at := func(hour, minute int) time.Time { return time.Date(2025, 4, 29, hour, minute, 0, 0, shanghai) }
+	items := []NewsItem{
+		// 股吧转帖正文最长，但专业新闻优先作为代表
+		{Title: "农业银行一季度净利润增长1.6%", Content: "新华社北京4月29日电 " + dedupReport + "！！！", URL: "https://guba.eastmoney.com/news,601288,1.html",
+			Source: sourceGuba, SourceType: sourceTypeForum, PublishedAt: at(10, 5), Symbols: []string{"SH601288"}},
+		{Title: "农业银行一季度净利润增长1.6%", Content: "财联社4月29日电，" + dedupReport, URL: "https://www.cls.cn/detail/1001",
+			Source: sourceCLS, SourceType: sourceTypeNews, PublishedAt: at(10, 0)},
+		{Title: "宁德时代发布钠离子电池", Content: "量产时间提前至明年。", URL: "https://xueqiu.com/1/2",
+			Source: sourceXueqiu, SourceType: sourceTypeNews, PublishedAt: at(9, 0)},
+		// 已经合并过的新闻，簇大小按原值累加，链接与来源一并保留
+		{Title: "农业银行一季度净利润增长1.6%", Content: dedupReport + "（转自财联社）", URL: "https://finance.eastmoney.com/a/1.html",
+			Source: sourceEastmoney, SourceType: sourceTypeNews, PublishedAt: at(9, 58), ClusterSize: 2,
+			RelatedURLs: []string{"https://finance.eastmoney.com/a/2.html", "https://www.cls.cn/detail/1001"},
+			Sources:     []string{sourceEastmoney, sourceFeed}, Symbols: []string{"SH601288", "SH601988"}},
+		// 与代表新闻链接相同，视为同一篇，不计入簇大小
+		{Title: "农业银行一季度净利润增长1.6%", Content: "财联社4月29日电，" + dedupReport, URL: "https://www.cls.cn/detail/1001",
+			Source: sourceCLS, SourceType: sourceTypeNews, PublishedAt: at(9, 30)},
+	}
+	merged := dedupNewsItems(items)
+	if len(merged) != 2 {
+		t.Fatalf("合并后 %d 条，期望 2 条", len(merged))
+	}
+
+	got := merged[0]
+	if got.URL != "https://www.cls.cn/detail/1001" || got.SourceType != sourceTypeNews {
+		t.Errorf("代表新闻 = %s %s，期望财联社的专业新闻", got.URL, got.SourceType)
+	}
+	if !got.PublishedAt.Equal(at(9, 58)) || got.Time != "2025-04-29 09:58:00" {
+		t.Errorf("发布时间 = %v %q，期望簇内最早的 09:58", got.PublishedAt, got.Time)
+	}
+	if got.ClusterSize != 4 {
+		t.Errorf("clusterSize = %d，期望 4", got.ClusterSize)
+	}
+	wantURLs := []string{"https://guba.eastmoney.com/news,601288,1.html", "https://finance.eastmoney.com/a/1.html", "https://finance.eastmoney.com/a/2.html"}
+	if !reflect.DeepEqual(got.RelatedURLs, wantURLs) {
+		t.Errorf("relatedUrls = %q，期望 %q", got.RelatedURLs, wantURLs)
+	}
+	if want := []string{sourceCLS, sourceGuba, sourceEastmoney, sourceFeed}; !reflect.DeepEqual(got.Sources, want) {
+		t.Errorf("sources = %q，期望 %q", got.Sources, want)
+	}
+	if want := []string{"SH601288", "SH601988"}; !reflect.DeepEqual(got.Symbols, want) {
+		t.Errorf("symbols = %q，期望 %q", got.Symbols, want)
+	}
+	if items[1].ClusterSize != 0 || items[1].Symbols != nil {
+		t.Error("合并不应修改输入的新闻项")
+	}
+
+	single := merged[1]
+	if single.URL != "https://xueqiu.com/1/2" || single.ClusterSize != 1 || single.RelatedURLs != nil {
+		t.Errorf("单独一簇 = %s clusterSize=%d relatedUrls=%q", single.URL, single.ClusterSize, single.RelatedURLs)
+	}
+}
+
+func TestBetterNewsItem(t *testing.T) {
+	news := NewsItem{SourceType: sourceTypeNews, Content: "短"}
+	page := NewsItem{SourceType: sourceTypePage, Content: "较长的整页文本"}
+	forum := NewsItem{SourceType: sourceTypeForum, Content: "很长很长的论坛帖子正文"}
+	tests := []struct {
+		name string
+		a, b NewsItem
+		want bool
+	}{
+		{"新闻优先于论坛", news, forum, true},
+		{"论坛不优先于新闻", forum, news, false},
+		{"同为非论坛时正文更长优先", page, news, true},
+		{"正文相同长度不替换", news, news, false},
+	}
+	for _, tt := range tests {
+		if got := betterNewsItem(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: betterNewsItem = %v，期望 %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package tools
+
+import (
+	"fmt"
+	"time"
+
+	"stock_agent/newstime"
+)
+
+// NewsItem 新闻项结构
+// title、content、url、time 为最初的字段，其余字段可选，旧格式的JSON仍可直接解析
+type NewsItem struct {
+	Title        string    `json:"title"`
+	Content      string    `json:"content"`
+	URL          string    `json:"url"`
+	Time         string    `json:"time"`                   // 发布时间，格式 2006-01-02 15:04:05，未知时为空
+	Source       string    `json:"source,omitempty"`       // 来源：cls、xueqiu、eastmoney、guba、cninfo、feed
+	SourceType   string    `json:"sourceType,omitempty"`   // 内容类型：news（专业新闻，默认）、forum（散户讨论）、announcement（公司公告）、page（整页文本）
+	PublishedAt  time.Time `json:"publishedAt,omitzero"`   // 发布时间，与 time 一致
+	CrawledAt    time.Time `json:"crawledAt,omitzero"`     // 抓取时间
+	Author       string    `json:"author,omitempty"`       // 作者或发帖人
+	Symbols      []string  `json:"symbols,omitempty"`      // 相关股票代码，如 SH601288
+	Tags         []string  `json:"tags,omitempty"`         // 标签，如电报、公告类别、媒体名称
+	ReadCount    int       `json:"readCount,omitempty"`    // 阅读数，仅论坛帖子有
+	CommentCount int       `json:"commentCount,omitempty"` // 评论数，仅论坛帖子有
+	ClusterSize  int       `json:"clusterSize,omitempty"`  // 去重后合并的相似报道数（含自身），越大说明传播越广
+	RelatedURLs  []string  `json:"relatedUrls,omitempty"`  // 被合并的相似报道链接
+	Sources      []string  `json:"sources,omitempty"`      // 相似报道的全部来源
+}
+
+// 新闻项的内容类型
+const (
+	sourceTypeNews         = "news"
+	sourceTypeForum        = "forum"
+	sourceTypeAnnouncement = "announcement"
+	sourceTypePage         = "page"
+)
+
+// 只产出新闻、不需要配置抓取方式的数据源
+const (
+	sourceCninfo = "cninfo"
+	sourceFeed   = "feed"
+)
+
+// newsTimeLayout 新闻发布时间的文本格式
+const newsTimeLayout = "2006-01-02 15:04:05"
+
+// sourceNames 数据源的中文名称，用于提示词和报告
+var sourceNames = map[string]string{
+	sourceCLS:       "财联社",
+	sourceXueqiu:    "雪球",
+	sourceEastmoney: "东方财富",
+	sourceGuba:      "东方财富股吧",
+	sourceCninfo:    "巨潮资讯",
+	sourceFeed:      "订阅源",
+}
+
+// newNewsItem 创建新闻项并记录来源和抓取时间
+func newNewsItem(source, sourceType string) NewsItem {
+	return NewsItem{Source: source, SourceType: sourceType, CrawledAt: time.Now().In(shanghai)}
+}
+
+// SetPublished 设置发布时间，同时更新 time 字段
+func (n *NewsItem) SetPublished(t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	n.PublishedAt = t.In(shanghai)
+	n.Time = n.PublishedAt.Format(newsTimeLayout)
+}
+
+// normalize 使 time 与 publishedAt 保持一致：只有其中一个时由另一个补齐
+func (n *NewsItem) normalize() {
+	switch {
+	case !n.PublishedAt.IsZero():
+		n.SetPublished(n.PublishedAt)
+	case n.Time != "":
+		if t, ok := parseNewsTime(n.Time); ok {
+			n.PublishedAt = t
+		}
+	}
+}
+
+// parseNewsTime 解析发布时间文本，支持"5分钟前"等相对时间，没有时区时按北京时间处理
+func parseNewsTime(text string) (time.Time, bool) {
+	t, err := newstime.Parse(text, time.Now())
+	return t, err == nil
+}
+
+// withSymbols 为没有相关代码的新闻项补充代码
+func withSymbols(items []NewsItem, symbols []string) []NewsItem {
+	if len(symbols) == 0 {
+		return items
+	}
+	for i := range items {
+		if len(items[i].Symbols) == 0 {
+			items[i].Symbols = append([]string(nil), symbols...)
+		}
+	}
+	return items
+}
+
+// relatedSymbols 返回搜索对应的股票代码：优先使用传入的代码，其次是关键词能确定的证券
+func relatedSymbols(keyword, symbol string) []string {
+	if symbol = normalizeXqSymbol(symbol); symbol != "" {
+		return []string{symbol}
+	}
+	if m := getSecurityMaster(); m != nil && keyword != "" {
+		if candidates := m.Resolve(keyword, 1); len(candidates) > 0 && candidates[0].Confidence >= 0.9 {
+			return []string{candidates[0].Symbol}
+		}
+	}
+	return nil
+}
+
+// sourceLabel 返回用于提示词的来源描述，如 东方财富股吧（散户观点）
+func (n NewsItem) sourceLabel() string {
+	name := sourceName(n.Source)
+	var kind string
+	switch n.SourceType {
+	case sourceTypeForum:
+		kind = "散户观点"
+	case sourceTypeAnnouncement:
+		kind = "公司公告"
+	}
+	switch {
+	case name != "" && kind != "":
+		return fmt.Sprintf("%s（%s）", name, kind)
+	case kind != "":
+		return kind
+	}
+	return name
+}
+
+// sourceName 返回数据源的中文名称，未知来源原样返回
+func sourceName(source string) string {
+	if name := sourceNames[source]; name != "" {
+		return name
+	}
+	return source
+}
@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"stock_agent/document"
+)
+
+// maxPDFSize 下载PDF的大小上限
+const maxPDFSize = 30 << 20
+
+var pdfClient = &http.Client{}
+
+// downloadPDF 下载PDF文件，PDF发布后不再变化，默认永久缓存
+func downloadPDF(ctx context.Context, pdfURL string) ([]byte, error) {
+	return cachedDownload(ctx, cachePDF, pdfURL, func() ([]byte, error) {
+		return fetchPDF(ctx, pdfURL)
+	})
+}
+
+// fetchPDF 请求PDF文件
+func fetchPDF(ctx context.Context, pdfURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pdfURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", httpUserAgent)
+	resp, err := pdfClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("下载PDF失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("下载PDF失败: HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPDFSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("下载PDF失败: %v", err)
+	}
+	if len(data) > maxPDFSize {
+		return nil, fmt.Errorf("PDF超过 %dMB，已跳过", maxPDFSize>>20)
+	}
+	return data, nil
+}
+
+// fetchPDFNewsItem 下载PDF并将分块正文转换为新闻项
+func fetchPDFNewsItem(ctx context.Context, source, pdfURL, title string, timeout time.Duration) (NewsItem, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	doc, err := fetchPDFDocument(ctx, pdfURL)
+	if err != nil {
+		return NewsItem{}, err
+	}
+	item := newNewsItem(source, sourceTypePage)
+	item.Title = title
+	item.Content = formatPDFContent(doc, 5000)
+	item.URL = pdfURL
+	return item, nil
+}
+
+// pdfChunkRunes 每个PDF分块的字符数
+const pdfChunkRunes = 1500
+
+// fetchPDFDocument 下载并解析PDF
+func fetchPDFDocument(ctx context.Context, url string) (*document.Document, error) {
+	data, err := downloadPDF(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return document.ParsePDF(data)
+}
+
+// formatPDFContent 将PDF分块整理为提示词中的正文，每块标注页码，总长度不超过 maxRunes
+func formatPDFContent(doc *document.Document, maxRunes int) string {
+	var b strings.Builder
+	size := 0
+	for _, chunk := range doc.Chunks(pdfChunkRunes) {
+		pages := fmt.Sprintf("第%d页", chunk.StartPage)
+		if chunk.EndPage != chunk.StartPage {
+			pages = fmt.Sprintf("第%d-%d页", chunk.StartPage, chunk.EndPage)
+		}
+		text := fmt.Sprintf("[%s]\n%s\n", pages, chunk.Text)
+		n := utf8.RuneCountInString(text)
+		if size+n > maxRunes {
+			if size == 0 {
+				b.WriteString(truncateRunes(text, maxRunes))
+			} else {
+				b.WriteString("...")
+			}
+			break
+		}
+		b.WriteString(text)
+		size += n
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// isPDFURL 判断链接是否指向PDF文件
+func isPDFURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return strings.HasSuffix(strings.ToLower(u.Path), ".pdf")
+}
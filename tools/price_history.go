@@ -0,0 +1,186 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"stock_agent/kline"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// GetPriceHistoryInput 获取K线的输入参数
+type GetPriceHistoryInput struct {
+	Symbol string `json:"symbol" jsonschema_description:"股票代码，例如：SH601288、SZ000001、601288、00700、AAPL"`
+	Period string `json:"period,omitempty" jsonschema_description:"K线周期：day（日线，默认）、week、month、1m、5m、15m、30m、60m"`
+	Start  string `json:"start,omitempty" jsonschema_description:"开始日期，格式 2006-01-02，可选"`
+	End    string `json:"end,omitempty" jsonschema_description:"结束日期，格式 2006-01-02，可选，默认截至最新"`
+	Count  int    `json:"count,omitempty" jsonschema_description:"返回区间内最近的K线数量，默认60，最多500"`
+}
+
+// PriceHistory K线数据
+type PriceHistory struct {
+	Symbol string      `json:"symbol"`
+	Name   string      `json:"name,omitempty"`
+	Period string      `json:"period"`
+	Source string      `json:"source"` // 数据来源：csv、eastmoney 或 xueqiu
+	Bars   []kline.Bar `json:"bars"`   // 按时间升序，价格为前复权价
+}
+
+const (
+	defaultPriceBarCount = 60
+	maxPriceBarCount     = 500
+	// analyzePriceBarCount 新闻分析时附带的日K线数量
+	analyzePriceBarCount = 60
+
+	sourceCSV = "csv"
+)
+
+var klineProviders = kline.Chain{emKlineProvider{}, xqKlineProvider{}}
+
+// SetKlineProviders 按顺序设置K线数据源：csv、eastmoney、xueqiu，前一个没有数据时尝试下一个
+func SetKlineProviders(names []string, csvDir string) error {
+	var chain kline.Chain
+	for _, name := range names {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case sourceCSV:
+			chain = append(chain, kline.CSVProvider{Dir: csvDir})
+		case sourceEastmoney:
+			chain = append(chain, emKlineProvider{})
+		case sourceXueqiu:
+			chain = append(chain, xqKlineProvider{})
+		default:
+			return fmt.Errorf("未知的K线数据源: %s，可选 csv、eastmoney、xueqiu", name)
+		}
+	}
+	if len(chain) > 0 {
+		klineProviders = chain
+	}
+	return nil
+}
+
+// GetPriceHistory 获取个股K线（Genkit Tool）
+func GetPriceHistory(ctx *ai.ToolContext, input GetPriceHistoryInput) (*PriceHistory, error) {
+	symbol := normalizeXqSymbol(input.Symbol)
+	if symbol == "" {
+		return nil, fmt.Errorf("股票代码不能为空")
+	}
+	period, err := kline.ParsePeriod(input.Period)
+	if err != nil {
+		return nil, err
+	}
+	q := kline.Query{Symbol: symbol, Period: period, Count: input.Count}
+	if q.Count <= 0 {
+		q.Count = defaultPriceBarCount
+	}
+	if q.Count > maxPriceBarCount {
+		q.Count = maxPriceBarCount
+	}
+	if q.Start, err = parseDateInput(input.Start); err != nil {
+		return nil, err
+	}
+	if q.End, err = parseDateInput(input.End); err != nil {
+		return nil, err
+	}
+	if !q.Start.IsZero() && !q.End.IsZero() && q.Start.After(q.End) {
+		return nil, fmt.Errorf("开始日期 %s 晚于结束日期 %s", input.Start, input.End)
+	}
+
+	log.Printf("获取K线: %s %s", symbol, period)
+	historyCtx, cancel := context.WithTimeout(ctx.Context, 2*time.Minute)
+	defer cancel()
+	history, err := loadPriceHistory(historyCtx, q)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("K线获取成功: %s，来源 %s，共 %d 根", symbol, history.Source, len(history.Bars))
+	return history, nil
+}
+
+// loadPriceHistory 从已配置的数据源获取K线
+func loadPriceHistory(ctx context.Context, q kline.Query) (*PriceHistory, error) {
+	bars, source, err := klineProviders.Bars(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 的K线失败: %v", q.Symbol, err)
+	}
+	name := keywordForSymbol(q.Symbol)
+	if name == q.Symbol {
+		name = ""
+	}
+	return &PriceHistory{Symbol: q.Symbol, Name: name, Period: string(q.Period), Source: source, Bars: bars}, nil
+}
+
+// parseDateInput 解析 2006-01-02 格式的日期，为空时返回零值
+func parseDateInput(text string) (time.Time, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.ParseInLocation(kline.DateLayout, text, shanghai)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("日期格式错误: %s，应为 2006-01-02", text)
+	}
+	return t, nil
+}
+
+// formatPriceAction 将K线整理为提示词中的走势摘要：区间涨跌、高低点、均量和最近10根K线
+func formatPriceAction(h *PriceHistory) string {
+	bars := h.Bars
+	if len(bars) == 0 {
+		return ""
+	}
+	first, last := bars[0], bars[len(bars)-1]
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "近期走势（%s，%d根%s线，%s 至 %s，前复权）:\n", h.Symbol, len(bars), periodName(h.Period), first.Time, last.Time)
+	high, low := bars[0], bars[0]
+	volume := 0.0
+	for _, bar := range bars {
+		if bar.High > high.High {
+			high = bar
+		}
+		if bar.Low < low.Low {
+			low = bar
+		}
+		volume += bar.Volume
+	}
+	fmt.Fprintf(&b, "最新收盘: %.2f，区间涨跌幅: %s\n", last.Close, changeText(first.Open, last.Close))
+	for _, n := range []int{5, 20} {
+		if len(bars) > n {
+			fmt.Fprintf(&b, "近%d根涨跌幅: %s\n", n, changeText(bars[len(bars)-n-1].Close, last.Close))
+		}
+	}
+	fmt.Fprintf(&b, "区间最高: %.2f（%s），区间最低: %.2f（%s），平均成交量: %s股\n", high.High, high.Time, low.Low, low.Time, formatLargeNumber(volume/float64(len(bars))))
+
+	recent := bars
+	if len(recent) > 10 {
+		recent = recent[len(recent)-10:]
+	}
+	fmt.Fprintf(&b, "最近%d根K线（时间 开 高 低 收 成交量）:\n", len(recent))
+	for _, bar := range recent {
+		fmt.Fprintf(&b, "%s %.2f %.2f %.2f %.2f %s\n", bar.Time, bar.Open, bar.High, bar.Low, bar.Close, formatLargeNumber(bar.Volume))
+	}
+	return b.String()
+}
+
+func changeText(from, to float64) string {
+	if from == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%+.2f%%", (to-from)/from*100)
+}
+
+func periodName(period string) string {
+	switch kline.Period(period) {
+	case kline.Day:
+		return "日"
+	case kline.Week:
+		return "周"
+	case kline.Month:
+		return "月"
+	}
+	return strings.TrimSuffix(period, "m") + "分钟"
+}
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"stock_agent/kline"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestParseDateInput(t *testing.T) {
+	if got, err := parseDateInput(" 2025-04-30 "); err != nil || !got.Equal(time.Date(2025, 4, 30, 0, 0, 0, 0, shanghai)) {
+		t.Errorf("parseDateInput = %v %v", got, err)
+	}
+	if got, err := parseDateInput(""); err != nil || !got.IsZero() {
+		t.Errorf("空日期 = %v %v，期望零值", got, err)
+	}
+	for _, in := range []string{"2025/04/30", "20250430", "2025-4-30", "2025-02-30", "昨天"} {
+		if _, err := parseDateInput(in); err == nil {
+			t.Errorf("parseDateInput(%q) 应返回错误", in)
+		}
+	}
+}
+
+// recordingKlineProvider 记录收到的查询条件，返回固定K线
+type recordingKlineProvider struct {
+	query kline.Query
+}
+
+func (p *recordingKlineProvider) Name() string { return "stub" }
+
+func (p *recordingKlineProvider) Bars(_ context.Context, q kline.Query) ([]kline.Bar, error) {
+	p.query = q
+	return []kline.Bar{{Time: "2025-04-30", Close: 4.71}}, nil
+}
+
+func TestGetPriceHistory(t *testing.T) {
+	old := klineProviders
+	t.Cleanup(func() { klineProviders = old })
+	stub := &recordingKlineProvider{}
+	klineProviders = kline.Chain{stub}
+	ctx := &ai.ToolContext{Context: context.Background()}
+
+	history, err := GetPriceHistory(ctx, GetPriceHistoryInput{Symbol: "601288", Period: "1M", Start: "2024-01-01", End: "2025-04-30", Count: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := stub.query
+	if history.Symbol != "SH601288" || history.Period != "month" || history.Source != "stub" || len(history.Bars) != 1 {
+		t.Errorf("结果 = %+v", history)
+	}
+	if q.Period != kline.Month || q.Count != maxPriceBarCount || !q.Start.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, shanghai)) ||
+		!q.End.Equal(time.Date(2025, 4, 30, 0, 0, 0, 0, shanghai)) {
+		t.Errorf("查询条件 = %+v", q)
+	}
+	if _, err := GetPriceHistory(ctx, GetPriceHistoryInput{Symbol: "SH601288"}); err != nil || stub.query.Period != kline.Day || stub.query.Count != defaultPriceBarCount {
+		t.Errorf("默认查询条件 = %+v %v", stub.query, err)
+	}
+
+	errs := []struct {
+		input GetPriceHistoryInput
+		want  string
+	}{
+		{GetPriceHistoryInput{}, "股票代码不能为空"},
+		{GetPriceHistoryInput{Symbol: "SH601288", Period: "2h"}, "不支持的K线周期"},
+		{GetPriceHistoryInput{Symbol: "SH601288", Start: "2025/01/01"}, "日期格式错误"},
+		{GetPriceHistoryInput{Symbol: "SH601288", Start: "2025-05-01", End: "2025-04-30"}, "晚于结束日期"},
+	}
+	for _, tt := range errs {
+		if _, err := GetPriceHistory(ctx, tt.input); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("GetPriceHistory(%+v) = %v，期望包含 %q", tt.input, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,390 @@
+package tools
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"stock_agent/newstime"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/firebase/genkit/go/ai"
+	"golang.org/x/net/html/charset"
+)
+
+// SearchFeedNewsInput 搜索订阅源新闻的输入参数
+type SearchFeedNewsInput struct {
+	Keyword string `json:"keyword,omitempty" jsonschema_description:"要查询的股票或主题关键词，例如：农业银行、光伏；为空时返回全部条目"`
+	Symbol  string `json:"symbol,omitempty" jsonschema_description:"可选，analyzeInput 解析出的股票代码，如 SH601288；会同时匹配简称和别名"`
+	Count   int    `json:"count,omitempty" jsonschema_description:"需要获取的条目数，默认20，最多100"`
+}
+
+const (
+	defaultFeedItemCount = 20
+	maxFeedItemCount     = 100
+	// maxFeedSize 单个订阅源最大读取字节数
+	maxFeedSize = 10 << 20
+)
+
+var (
+	feedURLs   []string
+	feedClient = &http.Client{Timeout: 30 * time.Second}
+)
+
+// SetFeedURLs 设置RSS/Atom订阅源，支持 http(s) 地址、file:// 地址和本地文件路径
+func SetFeedURLs(urls []string) {
+	feedURLs = nil
+	for _, u := range urls {
+		if u = strings.TrimSpace(u); u != "" {
+			feedURLs = append(feedURLs, u)
+		}
+	}
+}
+
+// SearchFeedNews 从已配置的RSS/Atom订阅源中搜索新闻（Genkit Tool）
+func SearchFeedNews(ctx *ai.ToolContext, input SearchFeedNewsInput) ([]NewsItem, error) {
+	if len(feedURLs) == 0 {
+		return nil, fmt.Errorf("未配置订阅源，请在配置文件的 feed.urls 中添加RSS/Atom地址")
+	}
+	count := input.Count
+	if count <= 0 {
+		count = defaultFeedItemCount
+	}
+	if count > maxFeedItemCount {
+		count = maxFeedItemCount
+	}
+	symbols := relatedSymbols(input.Keyword, input.Symbol)
+	terms := feedTerms(input.Keyword, input.Symbol, symbols)
+
+	log.Printf("搜索订阅源新闻: [%s]，共 %d 个订阅源", strings.Join(terms, "、"), len(feedURLs))
+	searchCtx, cancel := context.WithTimeout(ctx.Context, 2*time.Minute)
+	defer cancel()
+
+	entries, err := fetchFeeds(searchCtx, feedURLs)
+	if err != nil {
+		return nil, err
+	}
+	var items []NewsItem
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		key := e.Link
+		if key == "" {
+			key = e.Title
+		}
+		if seen[key] || !matchesTerms(e.Title+"\n"+e.Content, terms) {
+			continue
+		}
+		seen[key] = true
+		items = append(items, e.newsItem())
+		if len(items) >= count {
+			break
+		}
+	}
+	log.Printf("订阅源新闻搜索成功，共获取 %d 条新闻", len(items))
+	return withSymbols(items, symbols), nil
+}
+
+// feedTerms 返回匹配用的关键词：输入的关键词，以及相关证券的简称、别名和代码
+func feedTerms(keyword, symbol string, symbols []string) []string {
+	var terms []string
+	add := func(term string) {
+		term = strings.TrimSpace(term)
+		for _, t := range terms {
+			if strings.EqualFold(t, term) {
+				return
+			}
+		}
+		if term != "" {
+			terms = append(terms, term)
+		}
+	}
+	add(keyword)
+	if m := getSecurityMaster(); m != nil {
+		// 关键词本身是证券简称或代码时同样按别名匹配
+		for _, related := range symbols {
+			if s, ok := m.BySymbol(related); ok {
+				add(s.Name)
+				for _, alias := range s.Aliases {
+					add(alias)
+				}
+				add(s.Code)
+			}
+		}
+	}
+	if len(terms) == 0 {
+		add(symbol)
+	}
+	return terms
+}
+
+// matchesTerms 判断文本是否包含任一关键词（不区分大小写），没有关键词时全部匹配
+func matchesTerms(text string, terms []string) bool {
+	if len(terms) == 0 {
+		return true
+	}
+	text = strings.ToLower(text)
+	for _, term := range terms {
+		if strings.Contains(text, strings.ToLower(term)) {
+			return true
+		}
+	}
+	return false
+}
+
+// feedEntry 订阅源中的单个条目
+type feedEntry struct {
+	Feed      string // 订阅源标题
+	Title     string
+	Link      string
+	Content   string
+	Author    string
+	Published time.Time // 没有发布时间时为零值
+}
+
+// newsItem 将条目转为新闻项，订阅源标题作为标签，时间使用条目的发布时间
+func (e feedEntry) newsItem() NewsItem {
+	item := newNewsItem(sourceFeed, sourceTypeNews)
+	item.Title = e.Title
+	item.Content = e.Content
+	item.URL = e.Link
+	item.Author = e.Author
+	if e.Feed != "" {
+		item.Tags = []string{e.Feed}
+	}
+	item.SetPublished(e.Published)
+	return item
+}
+
+// fetchFeeds 并发读取全部订阅源，返回按发布时间倒序的条目；单个订阅源失败时跳过
+func fetchFeeds(ctx context.Context, urls []string) ([]feedEntry, error) {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		entries []feedEntry
+		errs    []string
+	)
+	sem := make(chan struct{}, max(crawlOptions.Workers, 1))
+	for _, u := range urls {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			data, err := readFeed(ctx, u)
+			var parsed []feedEntry
+			if err == nil {
+				parsed, err = parseFeed(data)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				log.Printf("读取订阅源失败，已跳过: %s: %v", u, err)
+				errs = append(errs, fmt.Sprintf("%s: %v", u, err))
+				return
+			}
+			entries = append(entries, parsed...)
+		}(u)
+	}
+	wg.Wait()
+
+	if len(errs) == len(urls) {
+		return nil, fmt.Errorf("全部订阅源读取失败: %s", strings.Join(errs, "；"))
+	}
+	// 没有发布时间的条目排在最后
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Published.After(entries[j].Published)
+	})
+	return entries, nil
+}
+
+// readFeed 读取订阅源内容，http(s) 地址直接请求，其余按本地文件读取
+func readFeed(ctx context.Context, rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err == nil && u.Scheme == "file" {
+		return os.ReadFile(u.Path)
+	}
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return os.ReadFile(rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", httpUserAgent)
+	req.Header.Set("Accept", "application/rss+xml,application/atom+xml,application/xml;q=0.9,*/*;q=0.8")
+	resp, err := feedClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("请求失败: HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxFeedSize))
+}
+
+// rssDocument 同时兼容 RSS 2.0（rss/channel/item）、RSS 1.0（rdf:RDF/item）和 Atom（feed/entry）
+type rssDocument struct {
+	XMLName xml.Name
+	// RSS 2.0
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	// RSS 1.0 的条目与 channel 同级
+	Items []rssItem `xml:"item"`
+	// Atom
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Links       []string `xml:"link"`
+	GUID        string   `xml:"guid"`
+	Description string   `xml:"description"`
+	Encoded     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate     string   `xml:"pubDate"`
+	Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Author      string   `xml:"author"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+}
+
+type atomEntry struct {
+	Title string `xml:"title"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	ID        string `xml:"id"`
+	Summary   string `xml:"summary"`
+	Content   string `xml:"content"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Author    struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+}
+
+// parseFeed 解析RSS或Atom订阅源，支持XML声明中的GBK等编码
+func parseFeed(data []byte) ([]feedEntry, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	// 部分订阅源包含未声明的HTML实体
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+
+	var doc rssDocument
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("解析订阅源失败: %v", err)
+	}
+
+	var entries []feedEntry
+	switch doc.XMLName.Local {
+	case "rss", "RDF":
+		items := append(doc.Channel.Items, doc.Items...)
+		for _, item := range items {
+			link := item.link()
+			if link == "" && strings.HasPrefix(item.GUID, "http") {
+				link = strings.TrimSpace(item.GUID)
+			}
+			content := item.Encoded
+			if strings.TrimSpace(content) == "" {
+				content = item.Description
+			}
+			published := parseFeedTime(item.PubDate)
+			if published.IsZero() {
+				published = parseFeedTime(item.Date)
+			}
+			entries = append(entries, feedEntry{
+				Feed:      strings.TrimSpace(doc.Channel.Title),
+				Title:     htmlToText(item.Title),
+				Link:      link,
+				Content:   truncateRunes(htmlToText(content), 2000),
+				Author:    strings.TrimSpace(cmp.Or(item.Creator, item.Author)),
+				Published: published,
+			})
+		}
+	case "feed":
+		for _, entry := range doc.Entries {
+			content := entry.Content
+			if strings.TrimSpace(content) == "" {
+				content = entry.Summary
+			}
+			published := parseFeedTime(entry.Published)
+			if published.IsZero() {
+				published = parseFeedTime(entry.Updated)
+			}
+			entries = append(entries, feedEntry{
+				Feed:      strings.TrimSpace(doc.Title),
+				Title:     htmlToText(entry.Title),
+				Link:      atomLink(entry),
+				Content:   truncateRunes(htmlToText(content), 2000),
+				Author:    strings.TrimSpace(entry.Author.Name),
+				Published: published,
+			})
+		}
+	default:
+		return nil, fmt.Errorf("不是RSS或Atom订阅源: <%s>", doc.XMLName.Local)
+	}
+	return entries, nil
+}
+
+// atomLink 返回条目的原文链接，优先 rel="alternate"
+func atomLink(entry atomEntry) string {
+	for _, l := range entry.Links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return strings.TrimSpace(l.Href)
+		}
+	}
+	if len(entry.Links) > 0 {
+		return strings.TrimSpace(entry.Links[0].Href)
+	}
+	if strings.HasPrefix(entry.ID, "http") {
+		return strings.TrimSpace(entry.ID)
+	}
+	return ""
+}
+
+// link 返回条目的第一个非空链接
+// 不带命名空间的 link 标签会同时匹配 <atom:link rel="self"> 等同名元素，它们没有文本，需跳过
+func (item rssItem) link() string {
+	for _, l := range item.Links {
+		if l = strings.TrimSpace(l); l != "" {
+			return l
+		}
+	}
+	return ""
+}
+
+// parseFeedTime 解析发布时间，没有时区的时间按北京时间处理，无法解析时返回零值
+func parseFeedTime(text string) time.Time {
+	t, _ := newstime.Parse(text, time.Now())
+	return t
+}
+
+// htmlToText 将条目中的HTML片段转为纯文本
+func htmlToText(fragment string) string {
+	fragment = strings.TrimSpace(fragment)
+	if !strings.Contains(fragment, "<") && !strings.Contains(fragment, "&") {
+		return fragment
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(fragment))
+	if err != nil {
+		return fragment
+	}
+	return selectionText(doc.Selection)
+}
@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stock_agent/security"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func readFeedFixture(t *testing.T, name string) []feedEntry {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := parseFeed(data)
+	if err != nil {
+		t.Fatalf("parseFeed(%s): %v", name, err)
+	}
+	return entries
+}
+
+func TestParseFeed(t *testing.T) {
+	type want struct {
+		feed, title, link, author, content string
+		published                          time.Time
+	}
+	tests := []struct {
+		file    string
+		entries []want
+	}{
+		{"feed_rss2_gbk.xml", []want{
+			// item 中的 <atom:link> 没有文本，不能覆盖原文链接
+			{"财经要闻", "农行发布一季度业绩 净息差降幅收窄", "https://news.example.com/a/1001.html", "张明", "农业银行一季度实现净利润",
+				time.Date(2025, 4, 29, 18, 30, 0, 0, shanghai)},
+			{"财经要闻", "光伏组件价格继续下行", "https://news.example.com/a/1002.html", "", "多晶硅库存高企",
+				time.Date(2025, 4, 29, 10, 5, 0, 0, shanghai)},
+			// 没有 link 时使用链接形式的 guid
+			{"财经要闻", "两市成交额回升至万亿元", "https://news.example.com/a/1003.html", "", "沪深两市成交额较上一交易日放量。", time.Time{}},
+		}},
+		{"feed_rss1.rdf", []want{
+			{"行业研究", "贵州茅台：直销渠道占比提升", "https://research.example.com/r/88", "研究所", "一季度直销收入占比提升至45%。",
+				time.Date(2025, 4, 28, 8, 0, 0, 0, shanghai)},
+		}},
+		{"feed_atom.xml", []want{
+			{"公司快讯", "宁德时代发布钠离子电池", "https://flash.example.com/e/1", "李华", "量产时间提前至明年。",
+				time.Date(2025, 4, 30, 9, 30, 0, 0, shanghai)},
+			{"公司快讯", "中国农业银行召开股东大会", "https://flash.example.com/e/2", "", "审议通过年度利润分配方案。",
+				time.Date(2025, 4, 29, 12, 0, 0, 0, shanghai)},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			entries := readFeedFixture(t, tt.file)
+			if len(entries) != len(tt.entries) {
+				t.Fatalf("条目数 %d，期望 %d", len(entries), len(tt.entries))
+			}
+			for i, w := range tt.entries {
+				e := entries[i]
+				if e.Feed != w.feed || e.Title != w.title || e.Link != w.link || e.Author != w.author {
+					t.Errorf("条目 %d = {%q %q %q %q}，期望 {%q %q %q %q}", i, e.Feed, e.Title, e.Link, e.Author, w.feed, w.title, w.link, w.author)
+				}
+				if !strings.HasPrefix(e.Content, w.content) || strings.Contains(e.Content, "<") {
+					t.Errorf("条目 %d 内容 = %q，期望以 %q 开头的纯文本", i, e.Content, w.content)
+				}
+				if !e.Published.Equal(w.published) {
+					t.Errorf("条目 %d 发布时间 = %v，期望 %v", i, e.Published, w.published)
+				}
+			}
+		})
+	}
+}
+
+func TestParseFeedNotFeed(t *testing.T) {
+	if _, err := parseFeed([]byte(`<html><body>not a feed</body></html>`)); err == nil {
+		t.Error("非订阅源应返回错误")
+	}
+}
+
+func TestSearchFeedNews(t *testing.T) {
+	oldURLs, oldMaster := feedURLs, getSecurityMaster()
+	t.Cleanup(func() {
+		feedURLs = oldURLs
+		SetSecurityMaster(oldMaster)
+	})
+	SetFeedURLs([]string{
+		filepath.Join("testdata", "feed_rss2_gbk.xml"),
+		filepath.Join("testdata", "feed_rss1.rdf"),
+		filepath.Join("testdata", "feed_atom.xml"),
+		filepath.Join("testdata", "missing.xml"), // 读取失败的订阅源跳过
+	})
+	m := security.NewMaster()
+	m.Add(security.Security{Code: "601288", Exchange: security.ExchangeSH, Name: "农业银行", Aliases: []string{"农行", "中国农业银行"}})
+	SetSecurityMaster(m)
+
+	tests := []struct {
+		name  string
+		input SearchFeedNewsInput
+		want  []string
+	}{
+		// 简称匹配全称和别名，结果按发布时间倒序
+		{"按别名匹配", SearchFeedNewsInput{Keyword: "农业银行"}, []string{"农行发布一季度业绩 净息差降幅收窄", "中国农业银行召开股东大会"}},
+		{"按代码匹配", SearchFeedNewsInput{Symbol: "SH601288"}, []string{"农行发布一季度业绩 净息差降幅收窄", "中国农业银行召开股东大会"}},
+		{"普通关键词", SearchFeedNewsInput{Keyword: "茅台"}, []string{"贵州茅台：直销渠道占比提升"}},
+		{"条目数", SearchFeedNewsInput{Count: 2}, []string{"宁德时代发布钠离子电池", "农行发布一季度业绩 净息差降幅收窄"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := SearchFeedNews(&ai.ToolContext{Context: context.Background()}, tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var titles []string
+			for _, item := range items {
+				titles = append(titles, item.Title)
+			}
+			if strings.Join(titles, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("结果 = %q，期望 %q", titles, tt.want)
+			}
+		})
+	}
+}
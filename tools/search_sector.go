@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// SearchSectorInput 板块查询的输入参数
+type SearchSectorInput struct {
+	Keyword string `json:"keyword" jsonschema_description:"板块或主题关键词，例如：光伏、银行板块、新能源车、半导体"`
+	Count   int    `json:"count,omitempty" jsonschema_description:"每个板块返回的成分股数量，默认20，最多100"`
+}
+
+// SectorResult 板块查询结果
+type SectorResult struct {
+	Name   string        `json:"name"`
+	Type   string        `json:"type,omitempty"` // 行业 或 概念
+	Source string        `json:"source"`         // 数据来源：local 或 eastmoney
+	Stocks []SectorStock `json:"stocks"`         // 成分股，按重要性或总市值降序
+}
+
+// SectorStock 板块成分股
+type SectorStock struct {
+	Symbol        string  `json:"symbol"` // 雪球格式代码，可直接传给搜索和行情工具
+	Name          string  `json:"name"`
+	Industry      string  `json:"industry,omitempty"`
+	ChangePercent float64 `json:"changePercent,omitempty"` // 涨跌幅（%），仅在线数据提供
+	MarketCap     float64 `json:"marketCap,omitempty"`     // 总市值（元），仅在线数据提供
+}
+
+const (
+	sourceLocal = "local"
+
+	defaultSectorStockCount = 20
+	maxSectorStockCount     = 100
+	// maxSectorResults 最多返回的板块数量
+	maxSectorResults = 3
+)
+
+var sectorOnline bool
+
+// SetSectorOnline 设置是否同时从东方财富查询板块成分股
+func SetSectorOnline(online bool) {
+	sectorOnline = online
+}
+
+// SearchSector 查询板块及其成分股（Genkit Tool）
+func SearchSector(ctx *ai.ToolContext, input SearchSectorInput) ([]SectorResult, error) {
+	if input.Keyword == "" {
+		return nil, fmt.Errorf("keyword 不能为空")
+	}
+	count := input.Count
+	if count <= 0 {
+		count = defaultSectorStockCount
+	}
+	if count > maxSectorStockCount {
+		count = maxSectorStockCount
+	}
+
+	log.Printf("查询板块: %s", input.Keyword)
+	searchCtx, cancel := context.WithTimeout(ctx.Context, 2*time.Minute)
+	defer cancel()
+	sectors, err := findSectors(searchCtx, input.Keyword, count)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("板块查询成功，共 %d 个板块", len(sectors))
+	return sectors, nil
+}
+
+// findSectors 先查本地板块数据，开启在线查询时再补充东方财富的板块，最多返回 maxSectorResults 个
+func findSectors(ctx context.Context, keyword string, count int) ([]SectorResult, error) {
+	var results []SectorResult
+	if m := getSecurityMaster(); m != nil {
+		for _, sector := range m.Sectors(keyword) {
+			result := SectorResult{Name: sector.Name, Type: sector.Type, Source: sourceLocal}
+			for _, s := range m.Constituents(sector) {
+				result.Stocks = append(result.Stocks, SectorStock{Symbol: s.Symbol(), Name: s.Name, Industry: s.Industry})
+			}
+			if len(result.Stocks) > count {
+				result.Stocks = result.Stocks[:count]
+			}
+			results = append(results, result)
+		}
+	}
+
+	if sectorOnline && len(results) < maxSectorResults {
+		online, err := searchEmSectors(ctx, keyword, count, maxSectorResults-len(results))
+		if err != nil {
+			if len(results) == 0 {
+				return nil, fmt.Errorf("东方财富板块查询失败: %v", err)
+			}
+			log.Printf("东方财富板块查询失败，仅返回本地板块: %v", err)
+		}
+		for _, result := range online {
+			if !hasSector(results, result.Name) {
+				results = append(results, result)
+			}
+		}
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("未找到与 %s 相关的板块", keyword)
+	}
+	if len(results) > maxSectorResults {
+		results = results[:maxSectorResults]
+	}
+	return results, nil
+}
+
+func hasSector(results []SectorResult, name string) bool {
+	for _, r := range results {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
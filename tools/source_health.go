@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"stock_agent/extractor"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// sourceWarningTTL 数据源告警的有效期，超过后不再附加到分析报告中
+const sourceWarningTTL = 30 * time.Minute
+
+// SourceDegraded 数据源降级告警：提取规则的命中情况明显差于基线，可能是页面改版或选择器失效
+type SourceDegraded struct {
+	Source string            // 数据源，如 cls、xueqiu
+	Rule   string            // 提取规则名
+	Severe bool              // 规则没有提取到任何数据
+	Issues []extractor.Issue // 具体异常
+	Time   time.Time         // 发现时间
+}
+
+// Error 输出一行告警描述
+func (e *SourceDegraded) Error() string {
+	level := "数据缺失"
+	if !e.Severe {
+		level = "部分字段异常"
+	}
+	messages := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		messages[i] = issue.String()
+	}
+	return fmt.Sprintf("数据源 %s %s（%s）", sourceName(e.Source), level, strings.Join(messages, "；"))
+}
+
+var (
+	// healthMonitor 提取规则的命中监控，未设置时只在内存中保存基线
+	healthMonitor, _ = extractor.NewMonitor("")
+
+	sourceWarningsMu sync.Mutex
+	sourceWarnings   = make(map[string]*SourceDegraded) // 按规则名保存最近一次告警
+)
+
+// SetHealthMonitor 设置提取规则的命中监控（启动时加载已保存的基线）
+func SetHealthMonitor(m *extractor.Monitor) {
+	healthMonitor = m
+}
+
+// extractRecords 按规则提取 page 解析出的 doc 中的全部列表项，并检查命中情况
+func extractRecords(rule *extractor.Rule, page *FetchedPage, doc *goquery.Document, ref time.Time) []extractor.Record {
+	records, report := rule.ExtractReport(doc, ref)
+	checkRuleHealth(rule, page, report)
+	return records
+}
+
+// checkRuleHealth 将本次提取与基线比较，发现异常时记录告警，恢复正常时清除该规则的告警
+// 缓存或存档中的页面不是站点当前的样子，不计入基线，也不改变告警
+func checkRuleHealth(rule *extractor.Rule, page *FetchedPage, report extractor.Report) {
+	if page.Stored {
+		return
+	}
+	issues, err := healthMonitor.Observe(rule, report)
+	if err != nil {
+		log.Printf("%v", err)
+	}
+
+	sourceWarningsMu.Lock()
+	defer sourceWarningsMu.Unlock()
+	if len(issues) == 0 {
+		delete(sourceWarnings, rule.Name)
+		return
+	}
+	warning := &SourceDegraded{Source: rule.Source, Rule: rule.Name, Issues: issues, Time: time.Now()}
+	warning.Severe = slices.ContainsFunc(issues, func(i extractor.Issue) bool { return i.Severe })
+	sourceWarnings[rule.Name] = warning
+	log.Printf("警告：%v", warning)
+}
+
+// severeWarning 返回规则当前未提取到任何数据的告警，没有时返回 nil
+func severeWarning(rule string) *SourceDegraded {
+	sourceWarningsMu.Lock()
+	defer sourceWarningsMu.Unlock()
+	if w := sourceWarnings[rule]; w != nil && w.Severe {
+		return w
+	}
+	return nil
+}
+
+// recentSourceWarnings 返回有效期内、数据源或规则名在 names 中的告警，按发现时间排序
+// 只附加本次分析用到的数据源，其他分析留下的告警与本次报告无关
+func recentSourceWarnings(names ...string) []*SourceDegraded {
+	sourceWarningsMu.Lock()
+	defer sourceWarningsMu.Unlock()
+	var warnings []*SourceDegraded
+	for name, w := range sourceWarnings {
+		if time.Since(w.Time) > sourceWarningTTL {
+			delete(sourceWarnings, name)
+			continue
+		}
+		if slices.Contains(names, w.Source) || slices.Contains(names, w.Rule) {
+			warnings = append(warnings, w)
+		}
+	}
+	slices.SortFunc(warnings, func(a, b *SourceDegraded) int { return a.Time.Compare(b.Time) })
+	return warnings
+}
+
+// newsSources 返回新闻项的全部来源，含合并的相似报道的来源
+func newsSources(items []NewsItem) []string {
+	var sources []string
+	for _, item := range items {
+		for _, source := range append([]string{item.Source}, item.Sources...) {
+			if source != "" && !slices.Contains(sources, source) {
+				sources = append(sources, source)
+			}
+		}
+	}
+	return sources
+}
+
+// formatSourceWarnings 将数据源告警格式化为提示词中的段落，没有告警时返回空字符串
+func formatSourceWarnings(warnings []*SourceDegraded) string {
+	if len(warnings) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("数据源告警（以下数据源本次抓取异常，相关信息可能缺失或不完整）：\n")
+	for _, w := range warnings {
+		fmt.Fprintf(&b, "- %v\n", w)
+	}
+	return b.String()
+}
+
+// withSourceWarnings 在报告末尾附加数据源告警章节，确保读者知道哪些数据可能缺失
+func withSourceWarnings(report string, warnings []*SourceDegraded) string {
+	if len(warnings) == 0 {
+		return report
+	}
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(report, "\n"))
+	b.WriteString("\n\n## 数据源告警\n\n以下数据源本次抓取异常，报告中相关信息可能缺失或不完整：\n\n")
+	for _, w := range warnings {
+		fmt.Fprintf(&b, "- %v\n", w)
+	}
+	return b.String()
+}
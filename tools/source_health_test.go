@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"stock_agent/extractor"
+)
+
+// useSourceWarnings 测试期间使用独立的监控器和告警
+func useSourceWarnings(t *testing.T, warnings map[string]*SourceDegraded) {
+	t.Helper()
+	oldMonitor, oldWarnings := healthMonitor, sourceWarnings
+	t.Cleanup(func() {
+		sourceWarningsMu.Lock()
+		healthMonitor, sourceWarnings = oldMonitor, oldWarnings
+		sourceWarningsMu.Unlock()
+	})
+	healthMonitor, _ = extractor.NewMonitor("")
+	sourceWarningsMu.Lock()
+	sourceWarnings = warnings
+	sourceWarningsMu.Unlock()
+}
+
+func TestCheckRuleHealthStoredPage(t *testing.T) {
+	useSourceWarnings(t, make(map[string]*SourceDegraded))
+	rule := getRule("xueqiu_search")
+	miss := extractor.Report{Rule: rule.Name}
+
+	// 缓存或存档的页面不计入基线
+	for i := 0; i < 3; i++ {
+		checkRuleHealth(rule, &FetchedPage{Stored: true}, miss)
+	}
+	if n := len(healthMonitor.Baseline(rule.Name)); n != 0 {
+		t.Fatalf("缓存页面计入基线 %d 次", n)
+	}
+	if w := severeWarning(rule.Name); w != nil {
+		t.Fatalf("缓存页面不应产生告警: %v", w)
+	}
+
+	for i := 0; i < 3; i++ {
+		checkRuleHealth(rule, &FetchedPage{}, miss)
+	}
+	if w := severeWarning(rule.Name); w == nil {
+		t.Fatal("本次抓取的页面连续未命中时应告警")
+	}
+	// 命中的缓存页面不能清除本次抓取发现的告警
+	checkRuleHealth(rule, &FetchedPage{Stored: true}, extractor.Report{Rule: rule.Name, ListSelector: rule.List[0], Items: 5, Records: 5,
+		Fields: []extractor.FieldReport{{Name: "url", Required: true, Matched: 5}}})
+	if w := severeWarning(rule.Name); w == nil {
+		t.Error("缓存页面清除了告警")
+	}
+}
+
+func TestRecentSourceWarnings(t *testing.T) {
+	now := time.Now()
+	warning := func(source, rule string, age time.Duration) *SourceDegraded {
+		return &SourceDegraded{Source: source, Rule: rule, Time: now.Add(-age)}
+	}
+	useSourceWarnings(t, map[string]*SourceDegraded{
+		"cls_telegram":  warning(sourceCLS, "cls_telegram", 2*time.Minute),
+		"cls_depth":     warning(sourceCLS, "cls_depth", time.Minute),
+		"guba_list":     warning(sourceGuba, "guba_list", 3*time.Minute),
+		"xueqiu_search": warning(sourceXueqiu, "xueqiu_search", time.Hour),
+	})
+	rules := func(warnings []*SourceDegraded) []string {
+		var names []string
+		for _, w := range warnings {
+			names = append(names, w.Rule)
+		}
+		return names
+	}
+
+	tests := []struct {
+		names []string
+		want  string
+	}{
+		// 按数据源匹配，按发现时间排序
+		{[]string{sourceCLS, sourceGuba}, "[guba_list cls_telegram cls_depth]"},
+		// 按规则名匹配
+		{[]string{"cls_telegram"}, "[cls_telegram]"},
+		// 过期的告警不返回
+		{[]string{sourceXueqiu}, "[]"},
+		{nil, "[]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(rules(recentSourceWarnings(tt.names...))); got != tt.want {
+			t.Errorf("recentSourceWarnings(%v) = %s，期望 %s", tt.names, got, tt.want)
+		}
+	}
+	sourceWarningsMu.Lock()
+	_, ok := sourceWarnings["xueqiu_search"]
+	sourceWarningsMu.Unlock()
+	if ok {
+		t.Error("过期的告警应删除")
+	}
+}
+
+func TestNewsSources(t *testing.T) {
+	items := []NewsItem{
+		{Source: sourceCLS, Sources: []string{sourceCLS, sourceEastmoney}},
+		{Source: sourceGuba},
+		{Source: sourceEastmoney},
+		{},
+	}
+	if got := fmt.Sprint(newsSources(items)); got != "[cls eastmoney guba]" {
+		t.Errorf("newsSources = %s", got)
+	}
+}
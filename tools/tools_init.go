@@ -1,6 +1,8 @@
 package tools
 
 import (
+	"stock_agent/security"
+
 	"github.com/firebase/genkit/go/ai"
 	"github.com/firebase/genkit/go/genkit"
 )
@@ -9,10 +11,17 @@ func InitTools(g *genkit.Genkit) []ai.ToolRef {
 	searchNewsTool := genkit.DefineTool[SearchNewsInput, []NewsItem](
 		g,
 		"searchStockNews",
-		"财联社股票财经新闻爬取，主要用于爬取个股的实时相关新闻",
+		"财联社电报搜索，按发布时间倒序返回个股相关的逐条电报（含标题、正文、发布时间和链接），可通过 count 指定条数",
 		SearchStockNews,
 	)
 
+	searchDepthNewsTool := genkit.DefineTool[SearchDepthNewsInput, []NewsItem](
+		g,
+		"searchClsDepthNews",
+		"财联社深度文章搜索，抓取个股相关深度文章的正文、作者和发布时间，分析价值高于电报，适合生成详细报告",
+		SearchClsDepthNews,
+	)
+
 	xqSearchStockTool := genkit.DefineTool[XqSearchStockInput, []NewsItem](
 		g,
 		"xqSearchStock",
@@ -20,10 +29,17 @@ func InitTools(g *genkit.Genkit) []ai.ToolRef {
 		XqSearchStock,
 	)
 
+	xqQuoteTool := genkit.DefineTool[XqQuoteInput, *QuoteSnapshot](
+		g,
+		"xqQuote",
+		"雪球行情快照，返回个股的最新价、涨跌幅、成交量、成交额、市盈率、市净率、总市值和52周区间，结果可作为 analyzeStockNews 的 quote 参数",
+		XqQuote,
+	)
+
 	analyzeNewsTool := genkit.DefineTool[AnalyzeNewsInput, string](
 		g,
 		"analyzeStockNews",
-		"使用AI分析股票相关新闻，生成专业的分析报告，包括市场情绪分析、关键信息总结、风险评估和投资建议。注意：newsItems 参数必须是数组格式，每个元素是包含 title、content、url、time 字段的对象。通常应该先调用 searchStockNews 或 xqSearchStock 获取新闻列表，然后将结果传递给此工具。",
+		"使用AI分析股票相关新闻，生成专业的分析报告，包括市场情绪分析、关键信息总结、风险评估和投资建议。注意：newsItems 参数必须是数组格式，每个元素是包含 title、content、url、time 字段的对象，其余字段（如股吧帖子的 sourceType、readCount、commentCount）请原样保留。通常应该先调用 searchStockNews、searchClsDepthNews、xqSearchStock、searchEastmoneyNews、searchGuba、searchFeedNews 或 searchAnnouncements 获取新闻列表，然后将结果传递给此工具。",
 		AnalyzeStockNews,
 	)
 
@@ -34,13 +50,76 @@ func InitTools(g *genkit.Genkit) []ai.ToolRef {
 		MarkdownExport,
 	)
 
-	analyzeInputTool := genkit.DefineTool[AnalyzeInput, string](
+	analyzeInputTool := genkit.DefineTool[AnalyzeInput, []security.Candidate](
 		g,
 		"analyzeInput",
-		"在不确定用户提到哪个个股的情况下，分析用户输入，返回可能的个股列表，最多返回三个",
+		"在不确定用户提到哪个个股的情况下，分析用户输入，返回可能的个股列表（含代码 symbol、简称和置信度），最多返回三个。symbol 可直接传给 searchStockNews、xqSearchStock、xqQuote",
 		Analyze,
 	)
 
-	toolList := []ai.ToolRef{analyzeInputTool,searchNewsTool, xqSearchStockTool, analyzeNewsTool, markdownExportTool}
+	searchSectorTool := genkit.DefineTool[SearchSectorInput, []SectorResult](
+		g,
+		"searchSector",
+		"板块查询，将光伏、银行板块等主题映射为板块及其成分股列表（含代码 symbol 和简称），成分股按重要性或总市值降序。symbol 可直接传给 searchStockNews、xqSearchStock、xqQuote",
+		SearchSector,
+	)
+
+	analyzeSectorTool := genkit.DefineTool[AnalyzeSectorInput, string](
+		g,
+		"analyzeSector",
+		"板块分析，自动获取板块内前 topN 只成分股的行情快照和财联社电报，生成板块整体分析报告，结果可传给 markdownExport 导出",
+		AnalyzeSector,
+	)
+
+	priceHistoryTool := genkit.DefineTool[GetPriceHistoryInput, *PriceHistory](
+		g,
+		"getPriceHistory",
+		"获取个股K线（开高低收、成交量、成交额，前复权），支持日线、周线、月线和1/5/15/30/60分钟线，可指定日期区间和数量。分析新闻时也可直接给 analyzeStockNews 传 symbol，自动附带近期走势和技术指标",
+		GetPriceHistory,
+	)
+
+	indicatorsTool := genkit.DefineTool[GetIndicatorsInput, *IndicatorReport](
+		g,
+		"getIndicators",
+		"计算个股最新的技术指标读数：MA、EMA、MACD、RSI、KDJ、BOLL、ATR、量比，并给出金叉死叉、超买超卖等信号。报告中引用技术指标时应以此工具的结果为准",
+		GetIndicators,
+	)
+
+	financialsTool := genkit.DefineTool[GetFinancialsInput, *Financials](
+		g,
+		"getFinancials",
+		"获取A股个股最近若干报告期的主要财务指标：营业总收入、归母净利润（年初累计）及同比、单季度环比，ROE、毛利率、资产负债率、经营现金流。分析基本面时使用",
+		GetFinancials,
+	)
+
+	announcementsTool := genkit.DefineTool[SearchAnnouncementsInput, []NewsItem](
+		g,
+		"searchAnnouncements",
+		"从巨潮资讯搜索A股上市公司公告（交易所信息披露），返回标题、类别（业绩预告、回购、质押、诉讼仲裁等）、日期和PDF原文链接，可选提取PDF正文。结果与新闻格式相同，可直接传给 analyzeStockNews",
+		SearchAnnouncements,
+	)
+
+	feedNewsTool := genkit.DefineTool[SearchFeedNewsInput, []NewsItem](
+		g,
+		"searchFeedNews",
+		"从配置的RSS/Atom订阅源搜索新闻，按关键词及股票简称、别名过滤，返回带真实发布时间的新闻列表。速度快、不依赖浏览器，可与 searchStockNews 搭配使用，结果可直接传给 analyzeStockNews",
+		SearchFeedNews,
+	)
+
+	emNewsTool := genkit.DefineTool[SearchEmNewsInput, []NewsItem](
+		g,
+		"searchEastmoneyNews",
+		"搜索东方财富个股资讯，返回按发布时间倒序的专业新闻列表（含来源媒体），结果可直接传给 analyzeStockNews",
+		SearchEmNews,
+	)
+
+	gubaTool := genkit.DefineTool[SearchGubaInput, []NewsItem](
+		g,
+		"searchGuba",
+		"获取东方财富股吧的最新帖子（含发帖时间、阅读数、评论数），标记为散户观点（sourceType=forum），用于衡量散户情绪和关注度。结果可与新闻一起传给 analyzeStockNews，分析时会与专业新闻区别对待",
+		SearchGuba,
+	)
+
+	toolList := []ai.ToolRef{analyzeInputTool,searchNewsTool, searchDepthNewsTool, xqSearchStockTool, xqQuoteTool, analyzeNewsTool, markdownExportTool, searchSectorTool, analyzeSectorTool, priceHistoryTool, indicatorsTool, financialsTool, announcementsTool, feedNewsTool, emNewsTool, gubaTool}
 	return toolList
 }
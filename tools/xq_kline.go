@@ -0,0 +1,130 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"stock_agent/kline"
+)
+
+// xqKlineAPIURL 雪球K线接口
+var xqKlineAPIURL = "https://stock.xueqiu.com/v5/stock/chart/kline.json"
+
+// xqKlineMaxCount 未指定数量时单次请求的最大K线数
+const xqKlineMaxCount = 1000
+
+// xqKlineProvider 雪球K线数据源，价格为前复权价
+type xqKlineProvider struct{}
+
+func (xqKlineProvider) Name() string {
+	return sourceXueqiu
+}
+
+func (xqKlineProvider) Bars(ctx context.Context, q kline.Query) ([]kline.Bar, error) {
+	symbol := normalizeXqSymbol(q.Symbol)
+	if symbol == "" {
+		return nil, fmt.Errorf("股票代码不能为空")
+	}
+	client := getXqClient()
+	if err := ensureXqCookies(ctx, client); err != nil {
+		return nil, err
+	}
+
+	// 接口从 begin 向前取 count 根K线，指定了开始日期时多取一些再按区间截取
+	end := time.Now()
+	if !q.End.IsZero() {
+		end = q.End.AddDate(0, 0, 1)
+	}
+	count := q.Count
+	if count <= 0 || !q.Start.IsZero() {
+		count = xqKlineMaxCount
+	}
+	query := url.Values{}
+	query.Set("symbol", symbol)
+	query.Set("begin", strconv.FormatInt(end.UnixMilli(), 10))
+	query.Set("period", string(q.Period))
+	query.Set("type", "before")
+	query.Set("count", strconv.Itoa(-count))
+	query.Set("indicator", "kline")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, xqKlineAPIURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", httpUserAgent)
+	req.Header.Set("Referer", xqBaseURL+"/")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求雪球K线接口失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("请求雪球K线接口失败: HTTP %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data struct {
+			Column []string     `json:"column"`
+			Item   [][]*float64 `json:"item"`
+		} `json:"data"`
+		ErrorCode        int    `json:"error_code"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析雪球K线接口失败: %v", err)
+	}
+	if result.ErrorCode != 0 {
+		return nil, fmt.Errorf("雪球K线接口返回错误: %d %s", result.ErrorCode, result.ErrorDescription)
+	}
+	bars, err := parseXqKlines(result.Data.Column, result.Data.Item, q.Period)
+	if err != nil {
+		return nil, err
+	}
+	if len(bars) == 0 {
+		return nil, kline.ErrNoData
+	}
+	return kline.Filter(bars, q), nil
+}
+
+// parseXqKlines 按列名解析雪球K线，时间戳为毫秒
+func parseXqKlines(columns []string, items [][]*float64, period kline.Period) ([]kline.Bar, error) {
+	index := make(map[string]int, len(columns))
+	for i, name := range columns {
+		index[name] = i
+	}
+	for _, required := range []string{"timestamp", "open", "high", "low", "close"} {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("雪球K线缺少列: %s", required)
+		}
+	}
+	value := func(item []*float64, name string) float64 {
+		i, ok := index[name]
+		if !ok || i >= len(item) || item[i] == nil {
+			return 0
+		}
+		return *item[i]
+	}
+
+	bars := make([]kline.Bar, 0, len(items))
+	for _, item := range items {
+		ts := int64(value(item, "timestamp"))
+		if ts == 0 {
+			continue
+		}
+		bars = append(bars, kline.Bar{
+			Time:   time.UnixMilli(ts).In(shanghai).Format(period.TimeLayout()),
+			Open:   value(item, "open"),
+			High:   value(item, "high"),
+			Low:    value(item, "low"),
+			Close:  value(item, "close"),
+			Volume: value(item, "volume"),
+			Amount: value(item, "amount"),
+		})
+	}
+	return bars, nil
+}
@@ -0,0 +1,330 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"stock_agent/security"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/firebase/genkit/go/ai"
+)
+
+// XqQuoteInput 雪球行情快照的输入参数
+type XqQuoteInput struct {
+	Symbol string `json:"symbol" jsonschema_description:"股票代码，例如：SH601288、SZ000001、601288、00700、AAPL"`
+}
+
+// QuoteSnapshot 行情快照，数值为0表示未获取到
+type QuoteSnapshot struct {
+	Symbol        string  `json:"symbol"`
+	Name          string  `json:"name"`
+	Currency      string  `json:"currency,omitempty"`
+	Current       float64 `json:"current"`       // 最新价
+	ChangePercent float64 `json:"changePercent"` // 涨跌幅（%）
+	Volume        float64 `json:"volume"`        // 成交量（股）
+	Amount        float64 `json:"amount"`        // 成交额（元）
+	PE            float64 `json:"pe"`            // 市盈率（TTM）
+	PB            float64 `json:"pb"`            // 市净率
+	MarketCap     float64 `json:"marketCap"`     // 总市值（元）
+	High52w       float64 `json:"high52w"`       // 52周最高
+	Low52w        float64 `json:"low52w"`        // 52周最低
+	Time          string  `json:"time"`          // 行情时间
+}
+
+// xqQuoteAPIURL 雪球行情接口地址
+var xqQuoteAPIURL = "https://stock.xueqiu.com/v5/stock/quote.json"
+
+var (
+	xqClientOnce sync.Once
+	xqClient     *http.Client
+)
+
+// getXqClient 返回带cookie的HTTP客户端，雪球接口需要先访问首页获取token
+func getXqClient() *http.Client {
+	xqClientOnce.Do(func() {
+		jar, _ := cookiejar.New(nil)
+		xqClient = &http.Client{Jar: jar, Timeout: 30 * time.Second}
+	})
+	return xqClient
+}
+
+// ensureXqCookies 先访问首页获取接口所需的cookie
+func ensureXqCookies(ctx context.Context, client *http.Client) error {
+	home, err := url.Parse(xqBaseURL)
+	if err != nil {
+		return err
+	}
+	if len(client.Jar.Cookies(home)) > 0 {
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, xqBaseURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", httpUserAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("访问雪球首页失败: %v", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// XqQuote 获取雪球行情快照（Genkit Tool）
+func XqQuote(ctx *ai.ToolContext, input XqQuoteInput) (*QuoteSnapshot, error) {
+	symbol := normalizeXqSymbol(input.Symbol)
+	if symbol == "" {
+		return nil, fmt.Errorf("股票代码不能为空")
+	}
+	log.Printf("获取雪球行情快照: %s", symbol)
+	quoteCtx, cancel := context.WithTimeout(ctx.Context, 2*time.Minute)
+	defer cancel()
+
+	quote, err := fetchXqQuoteJSON(quoteCtx, symbol)
+	if err == nil {
+		return quote, nil
+	}
+	log.Printf("雪球行情接口获取失败，改为解析个股页面: %v", err)
+
+	page, err := getFetcher(sourceXueqiu).Fetch(quoteCtx, getXqStock("/S/"+symbol), FetchOptions{Timeout: 60 * time.Second, WaitStable: true})
+	if err != nil {
+		return nil, fmt.Errorf("获取雪球个股页面失败: %v", err)
+	}
+	doc, err := parseHTML(page)
+	if err != nil {
+		return nil, err
+	}
+	quote = parseXqQuotePage(doc)
+	quote.Symbol = symbol
+	if quote.Current == 0 {
+		return nil, fmt.Errorf("未能从雪球个股页面解析出行情: %s", symbol)
+	}
+	return quote, nil
+}
+
+// fetchXqQuoteJSON 通过雪球行情接口获取快照
+func fetchXqQuoteJSON(ctx context.Context, symbol string) (*QuoteSnapshot, error) {
+	client := getXqClient()
+	if err := ensureXqCookies(ctx, client); err != nil {
+		return nil, err
+	}
+
+	apiURL := fmt.Sprintf("%s?symbol=%s&extend=detail", xqQuoteAPIURL, url.QueryEscape(symbol))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", httpUserAgent)
+	req.Header.Set("Referer", xqBaseURL+"/")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求雪球行情接口失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("请求雪球行情接口失败: HTTP %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data struct {
+			Quote *struct {
+				Symbol        string   `json:"symbol"`
+				Name          string   `json:"name"`
+				Currency      string   `json:"currency"`
+				Current       *float64 `json:"current"`
+				Percent       *float64 `json:"percent"`
+				Volume        *float64 `json:"volume"`
+				Amount        *float64 `json:"amount"`
+				PETTM         *float64 `json:"pe_ttm"`
+				PB            *float64 `json:"pb"`
+				MarketCapital *float64 `json:"market_capital"`
+				High52w       *float64 `json:"high52w"`
+				Low52w        *float64 `json:"low52w"`
+				Timestamp     int64    `json:"timestamp"`
+			} `json:"quote"`
+		} `json:"data"`
+		ErrorCode        int    `json:"error_code"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析雪球行情接口失败: %v", err)
+	}
+	if result.ErrorCode != 0 {
+		return nil, fmt.Errorf("雪球行情接口返回错误: %d %s", result.ErrorCode, result.ErrorDescription)
+	}
+	q := result.Data.Quote
+	if q == nil || q.Current == nil {
+		return nil, fmt.Errorf("雪球行情接口未返回 %s 的行情", symbol)
+	}
+
+	value := func(v *float64) float64 {
+		if v == nil {
+			return 0
+		}
+		return *v
+	}
+	quote := &QuoteSnapshot{
+		Symbol:        q.Symbol,
+		Name:          q.Name,
+		Currency:      q.Currency,
+		Current:       value(q.Current),
+		ChangePercent: value(q.Percent),
+		Volume:        value(q.Volume),
+		Amount:        value(q.Amount),
+		PE:            value(q.PETTM),
+		PB:            value(q.PB),
+		MarketCap:     value(q.MarketCapital),
+		High52w:       value(q.High52w),
+		Low52w:        value(q.Low52w),
+	}
+	if q.Timestamp > 0 {
+		quote.Time = time.UnixMilli(q.Timestamp).In(shanghai).Format("2006-01-02 15:04:05")
+	}
+	return quote, nil
+}
+
+var (
+	xqNamePattern    = regexp.MustCompile(`^(.+?)\s*\(`)
+	xqPercentPattern = regexp.MustCompile(`([+-]?\d+(\.\d+)?)%`)
+)
+
+// parseXqQuotePage 从雪球个股页面解析行情快照
+func parseXqQuotePage(doc *goquery.Document) *QuoteSnapshot {
+	quote := &QuoteSnapshot{}
+
+	name := strings.TrimSpace(doc.Find(".stock-name").First().Text())
+	if m := xqNamePattern.FindStringSubmatch(name); m != nil {
+		name = m[1]
+	}
+	quote.Name = name
+	quote.Current = parseChineseNumber(doc.Find(".stock-current strong, .stock-current").First().Text())
+	if m := xqPercentPattern.FindStringSubmatch(doc.Find(".stock-change").First().Text()); m != nil {
+		quote.ChangePercent, _ = strconv.ParseFloat(m[1], 64)
+	}
+	quote.Time = strings.TrimSpace(doc.Find(".quote-market-status .time, .stock-time").First().Text())
+
+	// 行情表格：每个单元格形如“成交量：<span>1.23亿股</span>”
+	for _, td := range doc.Find(".quote-info td").EachIter() {
+		label, value, ok := strings.Cut(td.Text(), "：")
+		if !ok {
+			continue
+		}
+		number := parseChineseNumber(value)
+		switch strings.TrimSpace(label) {
+		case "成交量":
+			quote.Volume = number
+		case "成交额":
+			quote.Amount = number
+		case "市盈率(TTM)", "市盈率TTM":
+			quote.PE = number
+		case "市净率":
+			quote.PB = number
+		case "总市值":
+			quote.MarketCap = number
+		case "52周最高":
+			quote.High52w = number
+		case "52周最低":
+			quote.Low52w = number
+		}
+	}
+	return quote
+}
+
+var chineseNumberPattern = regexp.MustCompile(`[-+]?\d+(\.\d+)?`)
+
+// parseChineseNumber 解析带中文单位的数字，例如“1.23亿股”、“¥4.50”、“3456.7万”
+func parseChineseNumber(text string) float64 {
+	text = strings.ReplaceAll(strings.TrimSpace(text), ",", "")
+	loc := chineseNumberPattern.FindStringIndex(text)
+	if loc == nil {
+		return 0
+	}
+	number, err := strconv.ParseFloat(text[loc[0]:loc[1]], 64)
+	if err != nil {
+		return 0
+	}
+	unit := text[loc[1]:]
+	switch {
+	case strings.HasPrefix(unit, "万亿"):
+		number *= 1e12
+	case strings.HasPrefix(unit, "亿"):
+		number *= 1e8
+	case strings.HasPrefix(unit, "万"):
+		number *= 1e4
+	}
+	return number
+}
+
+// normalizeXqSymbol 将股票代码转换为雪球格式：A股加交易所前缀，港股补足5位，美股转大写
+func normalizeXqSymbol(symbol string) string {
+	return security.NormalizeSymbol(symbol)
+}
+
+// formatQuoteSnapshot 将行情快照格式化为提示词中的文本块，未获取到的数值（为0）不输出
+func formatQuoteSnapshot(q *QuoteSnapshot) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "行情快照（%s %s", q.Name, q.Symbol)
+	if q.Time != "" {
+		fmt.Fprintf(&b, "，%s", q.Time)
+	}
+	fmt.Fprintf(&b, "）:\n")
+
+	written := false
+	writeLine := func(parts ...string) {
+		parts = slices.DeleteFunc(parts, func(p string) bool { return p == "" })
+		if len(parts) > 0 {
+			b.WriteString(strings.Join(parts, "，") + "\n")
+			written = true
+		}
+	}
+	price := func(label string, v float64) string {
+		if v == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%s: %.2f", label, v)
+	}
+	large := func(label string, v float64, unit string) string {
+		if v == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%s: %s%s", label, formatLargeNumber(v), unit)
+	}
+	// 没有最新价时涨跌幅同样不可信，平盘的0涨幅只在有最新价时输出
+	change := ""
+	if q.Current != 0 {
+		change = fmt.Sprintf("涨跌幅: %+.2f%%", q.ChangePercent)
+	}
+	writeLine(price("最新价", q.Current), change)
+	writeLine(large("成交量", q.Volume, "股"), large("成交额", q.Amount, "元"))
+	writeLine(price("市盈率(TTM)", q.PE), price("市净率", q.PB), large("总市值", q.MarketCap, "元"))
+	if q.Low52w != 0 && q.High52w != 0 {
+		writeLine(fmt.Sprintf("52周区间: %.2f - %.2f", q.Low52w, q.High52w))
+	}
+	if !written {
+		b.WriteString("暂无行情数据\n")
+	}
+	return b.String()
+}
+
+// formatLargeNumber 以万/亿为单位格式化大数
+func formatLargeNumber(n float64) string {
+	switch {
+	case n >= 1e8 || n <= -1e8:
+		return fmt.Sprintf("%.2f亿", n/1e8)
+	case n >= 1e4 || n <= -1e4:
+		return fmt.Sprintf("%.2f万", n/1e4)
+	}
+	return fmt.Sprintf("%.2f", n)
+}
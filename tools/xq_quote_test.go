@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestFormatQuoteSnapshot(t *testing.T) {
+	tests := []struct {
+		name  string
+		quote QuoteSnapshot
+		want  string
+	}{
+		{"完整行情", QuoteSnapshot{Symbol: "SH601288", Name: "农业银行", Current: 3.82, ChangePercent: 0.79, Volume: 3.2e8, Amount: 1.22e9,
+			PE: 6.1, PB: 0.68, MarketCap: 1.34e12, Low52w: 2.95, High52w: 3.9, Time: "2025-10-15 15:00:00"},
+			"行情快照（农业银行 SH601288，2025-10-15 15:00:00）:\n最新价: 3.82，涨跌幅: +0.79%\n成交量: 3.20亿股，成交额: 12.20亿元\n" +
+				"市盈率(TTM): 6.10，市净率: 0.68，总市值: 13400.00亿元\n52周区间: 2.95 - 3.90\n"},
+		// 页面解析只拿到部分数值，未获取到的不输出，平盘时涨跌幅为0照常输出
+		{"部分缺失", QuoteSnapshot{Symbol: "00700", Name: "腾讯控股", Current: 520, PB: 3.5, High52w: 560},
+			"行情快照（腾讯控股 00700）:\n最新价: 520.00，涨跌幅: +0.00%\n市净率: 3.50\n"},
+		{"没有数值", QuoteSnapshot{Symbol: "AAPL", Name: "苹果"}, "行情快照（苹果 AAPL）:\n暂无行情数据\n"},
+	}
+	for _, tt := range tests {
+		if got := formatQuoteSnapshot(&tt.quote); got != tt.want {
+			t.Errorf("%s:\n%s\n期望\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestXqQuote(t *testing.T) {
+	var gotSymbol string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			http.SetCookie(w, &http.Cookie{Name: "xq_a_token", Value: "test"})
+		case "/quote.json":
+			if _, err := r.Cookie("xq_a_token"); err != nil {
+				http.Error(w, `{"error_code":400016}`, http.StatusBadRequest)
+				return
+			}
+			gotSymbol = r.URL.Query().Get("symbol")
+			io.WriteString(w, `{"data":{"quote":{"symbol":"SH601288","name":"农业银行","currency":"CNY","current":3.82,"percent":-0.26,
+				"volume":320000000,"amount":null,"pe_ttm":6.1,"pb":0.68,"market_capital":null,"high52w":3.9,"low52w":2.95,"timestamp":1760511600000}},"error_code":0}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	oldBase, oldAPI := xqBaseURL, xqQuoteAPIURL
+	t.Cleanup(func() { xqBaseURL, xqQuoteAPIURL = oldBase, oldAPI })
+	xqBaseURL, xqQuoteAPIURL = server.URL, server.URL+"/quote.json"
+
+	quote, err := XqQuote(&ai.ToolContext{Context: context.Background()}, XqQuoteInput{Symbol: "601288.SH"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotSymbol != "SH601288" {
+		t.Errorf("请求代码 %q，期望 SH601288", gotSymbol)
+	}
+	if quote.Name != "农业银行" || quote.Current != 3.82 || quote.ChangePercent != -0.26 || quote.Amount != 0 || quote.Time != "2025-10-15 15:00:00" {
+		t.Errorf("行情 = %+v", *quote)
+	}
+	text := formatQuoteSnapshot(quote)
+	if strings.Contains(text, "成交额") || strings.Contains(text, "总市值") || !strings.Contains(text, "成交量: 3.20亿股") {
+		t.Errorf("接口返回 null 的数值不应输出:\n%s", text)
+	}
+}
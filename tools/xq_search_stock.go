@@ -4,29 +4,41 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/url"
 	"time"
 
 	"github.com/firebase/genkit/go/ai"
 )
 
-// https://xueqiu.com/k?q=
-// search__stock__bd search__stock__ai__table tr td
-
 type XqSearchStockInput struct {
 	Keyword string `json:"keyword" jsonschema_description:"要查询的股票关键词，例如：腾讯、阿里巴巴、AAPL等"`
+	Symbol  string `json:"symbol,omitempty" jsonschema_description:"可选，analyzeInput 解析出的股票代码，如 SH601288；提供时直接抓取该股票页面，跳过搜索"`
+	Refresh bool   `json:"refresh,omitempty" jsonschema_description:"可选，忽略页面缓存重新抓取，用户要求获取最新内容时设为true"`
 }
 
 func XqSearchStock(ctx *ai.ToolContext, input XqSearchStockInput) ([]NewsItem, error) {
-	log.Printf("雪球搜索股票: %s", input.Keyword)
-	searchCtx, cancel := context.WithTimeout(ctx.Context, 20*time.Minute)
+	log.Printf("雪球搜索股票: %s %s", input.Keyword, input.Symbol)
+	searchCtx, cancel := context.WithTimeout(withCacheBypass(ctx.Context, input.Refresh), 20*time.Minute)
 	defer cancel()
-	browser, err := getBrowser()
-	if err != nil {
-		return nil, fmt.Errorf("获取浏览器失败: %v", err)
-	}
-
-	defer browser.Close()
 	newsItems := make([]NewsItem, 0, 1)
+
+	// 已解析出代码时直接抓取个股页面
+	if symbol := normalizeXqSymbol(input.Symbol); symbol != "" {
+		keyword := input.Keyword
+		if keyword == "" {
+			keyword = keywordForSymbol(symbol)
+		}
+		item, err := fetchNewsContent(searchCtx, sourceXueqiu, getXqStock("/S/"+symbol), keyword+"-雪球股票", 60*time.Second)
+		if err != nil {
+			return nil, fmt.Errorf("爬取雪球股票失败: %v", err)
+		}
+		item.Symbols = []string{symbol}
+		return append(newsItems, item), nil
+	}
+	if input.Keyword == "" {
+		return nil, fmt.Errorf("keyword 和 symbol 不能同时为空")
+	}
+	rule := getRule("xueqiu_search")
 	xqURL := getXqChannel(input.Keyword)
 
 	// 检查context是否已取消
@@ -36,54 +48,63 @@ func XqSearchStock(ctx *ai.ToolContext, input XqSearchStockInput) ([]NewsItem, e
 	default:
 	}
 
-	// 使用公共方法安全创建页面
-	page, err := createPageSafely(browser, 60*time.Second)
+	// 抓取搜索结果页
+	page, err := getFetcher(sourceXueqiu).Fetch(searchCtx, xqURL, ruleFetchOptions(rule))
 	if err != nil {
-		return nil, fmt.Errorf("创建页面失败: %v", err)
-	}
-	defer page.Close()
-
-	// 使用公共方法导航并等待页面加载
-	if err := navigateAndWait(page, xqURL, true); err != nil {
 		return nil, fmt.Errorf("导航失败: %v", err)
 	}
-
-	// 使用公共方法查找元素
-	selectors := []string{
-		".search__stock__bd .search__stock__ai__table tr td a",
-		".search__stock__bd.search__stock__ai__table tr td a",
-		".search__stock__ai__table tr td a",
-		"table.search__stock__ai__table tr td a",
-		"tr td a[href*='/S/']",
-		"td a[href*='/S/']",
-	}
-	links, err := findElementsWithSelectors(page, selectors)
+	doc, err := parseHTML(page)
 	if err != nil {
-		log.Printf("警告：未找到任何链接，可能选择器需要调整: %v", err)
-		return newsItems, nil
+		return nil, err
 	}
-	for _, link := range links {
-		href, err := link.Attribute("href")
-		if err != nil {
-			continue
-		}
-		stockURL := getXqStock(*href)
 
-		item, err := fetchNewsContent(context.Background(), browser, stockURL, input.Keyword+"-雪球股票", 60*time.Second)
+	// 按提取规则查找个股链接
+	links := extractRecords(rule, page, doc, time.Now())
+	if len(links) == 0 {
+		w := severeWarning(rule.Name)
+		if w == nil {
+			log.Printf("雪球搜索没有找到相关股票: %s", input.Keyword)
+			return newsItems, nil
+		}
+		// 页面可能已改版：退回整页文本并附上告警，避免调用方误以为没有相关股票
+		item, err := newsItemFromPage(page, sourceXueqiu, input.Keyword+"-雪球搜索")
 		if err != nil {
-			log.Printf("爬取雪球股票失败: %v", err)
+			return nil, w
+		}
+		item.Content = fmt.Sprintf("【数据源告警】%v\n%s", w, item.Content)
+		item.Tags = append(item.Tags, "数据源告警")
+		return withSymbols(append(newsItems, item), relatedSymbols(input.Keyword, input.Symbol)), nil
+	}
+	var stockURLs []string
+	seen := make(map[string]bool)
+	for _, link := range links {
+		stockURL := getXqStock(link.Fields["url"])
+		if seen[stockURL] {
 			continue
 		}
-		newsItems = append(newsItems, item)
-		log.Printf("爬取雪球股票成功: %s", stockURL)
+		seen[stockURL] = true
+		stockURLs = append(stockURLs, stockURL)
 	}
+
+	// 并发抓取个股页面，结果顺序与搜索结果一致
+	newsItems = crawlPages(searchCtx, stockURLs, func(ctx context.Context, stockURL string) (NewsItem, error) {
+		item, err := fetchNewsContent(ctx, sourceXueqiu, stockURL, input.Keyword+"-雪球股票", 60*time.Second)
+		if err == nil {
+			log.Printf("爬取雪球股票成功: %s", stockURL)
+		}
+		return item, err
+	})
+	log.Printf("雪球股票爬取完成，成功 %d/%d 个", len(newsItems), len(stockURLs))
 	return newsItems, nil
 }
 
+// xqBaseURL 雪球站点地址
+var xqBaseURL = "https://xueqiu.com"
+
 func getXqChannel(keyword string) string {
-	return fmt.Sprintf("https://xueqiu.com/k?q=%s", keyword)
+	return getRule("xueqiu_search").PageURL(map[string]string{"base": xqBaseURL, "keyword": url.QueryEscape(keyword)}, 1)
 }
 
 func getXqStock(s string) string {
-	return fmt.Sprintf("https://xueqiu.com%s", s)
+	return fmt.Sprintf("%s%s", xqBaseURL, s)
 }
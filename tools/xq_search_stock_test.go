@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"stock_agent/extractor"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+const xqSearchPage = `<html><head><title>农业银行 - 雪球搜索</title></head><body>
+<div class="search__stock__bd">
+  <table class="search__stock__ai__table">
+    <tr><td><a href="/S/SH601288">农业银行</a></td><td>3.82</td></tr>
+    <tr><td><a href="/S/SH601288">农业银行</a></td><td>3.82</td></tr>
+    <tr><td><a href="/S/HK01288">农业银行(HK)</a></td><td>3.30</td></tr>
+  </table>
+</div>
+</body></html>`
+
+const xqStockPage = `<html><head><title>农业银行(SH601288)股票股价 - 雪球</title></head><body>
+<div class="stock-name">农业银行(SH:601288)</div>
+<div class="stock-current">3.82</div>
+<table class="quote-info"><tr><td>最高：3.85</td><td>最低：3.78</td><td>今开：3.80</td><td>昨收：3.79</td></tr>
+<tr><td>成交量：3.2亿股</td><td>成交额：12.2亿</td><td>市盈率(TTM)：6.1</td><td>市净率：0.68</td></tr></table>
+</body></html>`
+
+func TestXqSearchStock(t *testing.T) {
+	useHTTPFetcher(t)
+	server := serveHTML(t, map[string]string{
+		"/k":          xqSearchPage,
+		"/S/SH601288": xqStockPage,
+		"/S/HK01288":  strings.Replace(xqStockPage, "SH601288", "HK01288", 1),
+	})
+	oldBase := xqBaseURL
+	t.Cleanup(func() { xqBaseURL = oldBase })
+	xqBaseURL = server.URL
+
+	items, err := XqSearchStock(&ai.ToolContext{Context: context.Background()}, XqSearchStockInput{Keyword: "农业银行"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 重复的个股链接只抓取一次，顺序与搜索结果一致
+	var urls []string
+	for _, item := range items {
+		urls = append(urls, item.URL)
+		if item.Title != "农业银行-雪球股票" || !strings.Contains(item.Content, "3.82") {
+			t.Errorf("个股页面 %s = %q %q", item.URL, item.Title, item.Content)
+		}
+	}
+	want := []string{server.URL + "/S/SH601288", server.URL + "/S/HK01288"}
+	if strings.Join(urls, " ") != strings.Join(want, " ") {
+		t.Errorf("个股页面 = %q，期望 %q", urls, want)
+	}
+}
+
+func TestXqSearchStockNoResult(t *testing.T) {
+	useHTTPFetcher(t)
+	server := serveHTML(t, map[string]string{
+		"/k": `<html><head><title>雪球搜索</title></head><body><div class="search__empty">没有找到相关股票，请换个关键词试试，或者查看热门股票列表和今日热帖推荐内容。</div></body></html>`,
+	})
+	oldBase, oldMonitor := xqBaseURL, healthMonitor
+	t.Cleanup(func() {
+		xqBaseURL, healthMonitor = oldBase, oldMonitor
+		sourceWarningsMu.Lock()
+		delete(sourceWarnings, "xueqiu_search")
+		sourceWarningsMu.Unlock()
+	})
+	xqBaseURL = server.URL
+	healthMonitor, _ = extractor.NewMonitor("")
+
+	search := func() []NewsItem {
+		t.Helper()
+		items, err := XqSearchStock(&ai.ToolContext{Context: context.Background()}, XqSearchStockInput{Keyword: "不存在的股票"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return items
+	}
+	// 搜索确实没有结果时返回空列表
+	for i := 0; i < 2; i++ {
+		if items := search(); len(items) != 0 {
+			t.Fatalf("第 %d 次搜索返回 %d 项，期望空列表", i+1, len(items))
+		}
+	}
+	// 连续未命中时判定页面可能已改版，退回整页文本并附上告警
+	items := search()
+	if len(items) != 1 {
+		t.Fatalf("返回 %d 项，期望 1 项带告警的整页文本", len(items))
+	}
+	if !strings.HasPrefix(items[0].Content, "【数据源告警】数据源 雪球 数据缺失") || !slices.Contains(items[0].Tags, "数据源告警") {
+		t.Errorf("告警结果 = %q %q", items[0].Content, items[0].Tags)
+	}
+}